@@ -1,20 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
-	"github.com/alex-bezverkhniy/bp-engine/internal/api"
+	bpengine "github.com/alex-bezverkhniy/bp-engine"
 	"github.com/alex-bezverkhniy/bp-engine/internal/config"
-	"github.com/alex-bezverkhniy/bp-engine/internal/model"
-	"github.com/alex-bezverkhniy/bp-engine/internal/validators"
+	"github.com/alex-bezverkhniy/bp-engine/internal/dialects"
+	"github.com/alex-bezverkhniy/bp-engine/internal/migrations"
 
-	"github.com/gofiber/contrib/swagger"
-	fiber "github.com/gofiber/fiber/v2"
 	log "github.com/gofiber/fiber/v2/log"
-	fiberlogger "github.com/gofiber/fiber/v2/middleware/logger"
-	"gorm.io/driver/sqlite"
-	"gorm.io/gorm"
 )
 
 // @title Business Process Engine API
@@ -30,92 +32,57 @@ import (
 func main() {
 	environment := os.Getenv("ENV")
 	confFilePath := os.Getenv("CONFIG_FILE")
-	migrateDbFlag := flag.Bool("migrate", false, "run DB migration scripts")
+	migrateDbFlag := flag.Bool("migrate", false, "run DB migration scripts, same as migrate up")
 	serveFlag := flag.Bool("serve", true, "run http server")
 
+	flag.Usage = usage
 	flag.Parse()
 	migrateDB := migrateDbFlag != nil && *migrateDbFlag
 	serveHTTP := serveFlag != nil && *serveFlag
 
+	// bp-engine lint <config>
+	if flag.Arg(0) == "lint" {
+		lintConfigFilePath := confFilePath
+		if flag.NArg() > 1 {
+			lintConfigFilePath = flag.Arg(1)
+		}
+		os.Exit(lint(lintConfigFilePath))
+	}
+
 	// load config
 	conf, err := loadConfig(confFilePath, environment)
 	if err != nil {
 		log.Fatal("cannot load config file. ", err)
 	}
 
-	// connect to db
-	// github.com/mattn/go-sqlite3
-	db, err := gorm.Open(sqlite.Open(conf.DbUrl), &gorm.Config{})
-	if err != nil {
-		log.Fatal("cannot connect to db", err)
+	// bp-engine migrate up|down|status|to <version>
+	if flag.Arg(0) == "migrate" {
+		os.Exit(migrate(conf, flag.Args()[1:]))
 	}
-
 	if migrateDB {
-		log.Info("run DB migration")
-		if migrationErr := runDBMigration(db); len(migrationErr) > 0 {
-			for _, e := range migrationErr {
-				log.Error("DB migration error", e)
-			}
-			log.Fatal("cannot successfully complete DB migration")
-		}
-		log.Info("DB migration done!")
-		os.Exit(0)
+		os.Exit(migrate(conf, []string{"up"}))
 	}
 
 	if serveHTTP {
-		validator, err := setupValidator(conf)
+		engine, err := bpengine.New(*conf)
+		if err == nil {
+			err = engine.InitDefault()
+		}
 		if err != nil {
-			log.Fatal("cannot setup JSON Schema validator", err)
+			log.Fatal("cannot setup engine ", err)
 		}
 
-		app := setupApp(conf, validator, db)
-		log.Fatal(app.Listen(":3000"))
-	}
-
-}
+		// SIGINT or SIGTERM starts the graceful shutdown
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
 
-// @Summary Show the status of server.
-// @Description get the status of server.
-// @Tags root
-// @Accept */*
-// @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /v1/Health [get]
-func Health(c *fiber.Ctx) error {
-	return c.JSON(fiber.Map{
-		"status": "OK",
-	})
-}
-
-func setupApp(conf *config.Config, validator validators.Validator, db *gorm.DB) *fiber.App {
-	// Swagger config
-	pathToSwaggerFile := "./docs/swagger.json"
-	if len(conf.Env) == 0 || conf.Env == "dev" {
-		pathToSwaggerFile = "../docs/swagger.json"
-	}
-	cfg := swagger.Config{
-		BasePath: "/",
-		FilePath: pathToSwaggerFile,
-		Path:     "swagger",
-		Title:    "Swagger API Docs",
+		// returns once the shutdown is completed
+		if err := engine.Start(ctx, ":3000"); err != nil {
+			log.Fatal(err)
+		}
+		log.Info("shut down")
 	}
 
-	// Fiber App init
-	app := fiber.New()
-	app.Use(fiberlogger.New())
-	app.Use(swagger.New(cfg))
-
-	processRepository := api.NewProcessRepository(db)
-	processService := api.NewProcessService(processRepository, validator)
-	processController := api.NewProcessController(processService)
-
-	api := app.Group("/api")
-	v1 := api.Group("/v1")
-
-	v1.Get("/health", Health)
-	processController.SetupRouter(v1.Group("/process"))
-
-	return app
 }
 
 func loadConfig(filePath, environment string) (*config.Config, error) {
@@ -130,24 +97,89 @@ func loadConfig(filePath, environment string) (*config.Config, error) {
 	return cf, err
 }
 
-func runDBMigration(db *gorm.DB) []error {
-	var migrationErr []error
-	if dbErr := db.AutoMigrate(&model.Process{}); dbErr != nil {
-		migrationErr = append(migrationErr, dbErr)
+// migrate - runs the migrate subcommand, returns exit code
+func migrate(conf *config.Config, args []string) int {
+	if len(args) == 0 {
+		flag.Usage()
+		return 2
+	}
+
+	db, err := dialects.Open(conf.DbEngine, conf.DbUrl)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cannot connect to db:", err)
+		return 1
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cannot load DB migrations:", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "to":
+		if len(args) < 2 {
+			flag.Usage()
+			return 2
+		}
+		version, parseErr := strconv.ParseUint(args[1], 10, 32)
+		if parseErr != nil {
+			fmt.Fprintln(os.Stderr, "not supported migration version:", args[1])
+			return 2
+		}
+		err = migrator.To(ctx, uint(version))
+	case "status":
+	default:
+		flag.Usage()
+		return 2
 	}
-	if dbErr := db.AutoMigrate(&model.ProcessStatus{}); dbErr != nil {
-		migrationErr = append(migrationErr, dbErr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "DB migration error:", err)
+		return 1
 	}
 
-	return migrationErr
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cannot get DB migration status:", err)
+		return 1
+	}
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Printf("%04d %-40s %s\n", status.Version, status.Name, appliedAt)
+	}
+	return 0
 }
 
-func setupValidator(conf *config.Config) (validators.Validator, error) {
-	validator := validators.NewBasicValidator(conf.ProcessConfig)
-	err := validator.CompileJsonSchema()
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage:\n  %s [flags]\n  %s lint [config file]\n  %s migrate up|down|status|to <version>\n\nFlags:\n", os.Args[0], os.Args[0], os.Args[0])
+	flag.PrintDefaults()
+}
 
+// lint - checks process definitions of the config file, returns exit code
+func lint(filePath string) int {
+	conf, err := loadConfig(filePath, "")
 	if err != nil {
-		return nil, err
+		fmt.Fprintln(os.Stderr, "cannot load config file:", err)
+		return 2
 	}
-	return validator, nil
+
+	err = errors.Join(conf.ProcessConfig.Validate(), conf.Webhooks.Validate(conf.ProcessConfig))
+	if err == nil {
+		fmt.Println("config is valid")
+		return 0
+	}
+
+	// each problem is printed on its own line
+	fmt.Fprintln(os.Stderr, err)
+	return 1
 }
@@ -1,20 +1,28 @@
 package bpengine
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io/fs"
+	"net"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/alex-bezverkhniy/bp-engine/internal/api"
 	"github.com/alex-bezverkhniy/bp-engine/internal/config"
-	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+	"github.com/alex-bezverkhniy/bp-engine/internal/dialects"
+	"github.com/alex-bezverkhniy/bp-engine/internal/migrations"
 	"github.com/alex-bezverkhniy/bp-engine/internal/validators"
 
 	"github.com/gofiber/contrib/swagger"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
 	"github.com/gofiber/fiber/v2/middleware/logger"
-	"gorm.io/driver/sqlite"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"gorm.io/gorm"
 )
 
@@ -22,31 +30,151 @@ var (
 	ErrAppIsNotInitialized       = errors.New("fiber app is not initialized")
 	ErrDbIsNotInitialized        = errors.New("db is not initialized")
 	ErrValidatorIsNotInitialized = errors.New("validator is not initialized")
+	ErrEngineIsShutDown          = errors.New("engine is shut down")
+	ErrEngineIsRunning           = errors.New("engine is already running")
 )
 
+// DEFAULT_SHUTDOWN_TIMEOUT - deadline of the shutdown started by the done context of Start
+const DEFAULT_SHUTDOWN_TIMEOUT = 10 * time.Second
+
 type Engine struct {
-	config    config.Config
-	App       *fiber.App
-	db        *gorm.DB
-	validator validators.Validator
+	config        config.Config
+	App           *fiber.App
+	router        fiber.Router
+	db            *gorm.DB
+	validator     validators.Validator
+	repository    api.ProcessRepository
+	authenticator api.Authenticator
+	dispatcher    *api.WebhookDispatcher
+	scheduler     *api.TimerScheduler
+	worker        *api.ScheduleWorker
+	events        *api.EventBus
+	noSwagger     bool
+	migrate       bool
+	initialized   bool
+	// the DB is opened by SetupDB and is closed on shutdown
+	ownDB bool
+
+	mu           sync.Mutex
+	running      bool
+	listener     net.Listener
+	stopWorkers  context.CancelFunc
+	workers      sync.WaitGroup
+	shutdownOnce sync.Once
+	shutdownErr  error
+	// closed once the shutdown is started and completed
+	stopping chan struct{}
+	stopped  chan struct{}
 }
 
-func New(config config.Config) (*Engine, error) {
+// Option - configures the engine created by New
+type Option func(e *Engine)
+
+// New - creates the engine of the config. Without options the engine is set up by InitDefault or the Setup* methods,
+// with any of them it is set up by InitDefault right away. The engine is ready to be started once the DB schema
+// is migrated by WithMigrations or RunDBMigration, Start refuses to serve the schema behind
+func New(config Config, opts ...Option) (*Engine, error) {
 	engine := &Engine{
-		config: config,
+		config:   config,
+		events:   api.NewEventBus(),
+		stopping: make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(engine)
 	}
 
-	// Fiber App init
-	engine.App = fiber.New()
-	engine.App.Use(logger.New())
+	// Fiber App init, the engine mounted into the app of the embedding application does not serve its own one
+	if engine.App == nil && engine.router == nil {
+		engine.App = fiber.New()
+		engine.App.Use(requestid.New())
+		engine.App.Use(logger.New())
+	}
 
+	if len(opts) == 0 {
+		return engine, nil
+	}
+	if err := engine.InitDefault(); err != nil {
+		return nil, err
+	}
+	if engine.migrate {
+		if err := engine.RunDBMigration(); err != nil {
+			return nil, err
+		}
+	}
 	return engine, nil
 }
 
+// WithDB - uses the DB connection of the embedding application, same as SetDB
+func WithDB(db *gorm.DB) Option {
+	return func(e *Engine) {
+		e.SetDB(db)
+	}
+}
+
+// WithFiberApp - serves the API by the app of the embedding application, the request ID and logger middlewares
+// are not added to it
+func WithFiberApp(app *fiber.App) Option {
+	return func(e *Engine) {
+		e.App = app
+	}
+}
+
+// WithRouter - mounts the API and swagger under the router of the embedding application, e.g. app.Group("/bp").
+// The embedding application serves it, so the engine is run by Run instead of Start unless WithFiberApp is given
+func WithRouter(router fiber.Router) Option {
+	return func(e *Engine) {
+		e.router = router
+	}
+}
+
+// WithValidator - uses the custom validator of the process payloads, same as SetValidator
+func WithValidator(validator Validator) Option {
+	return func(e *Engine) {
+		e.SetValidator(validator)
+	}
+}
+
+// WithRepository - stores the processes by the custom repository, the DB is still used for the webhooks,
+// timers and scheduled assignments
+func WithRepository(repository ProcessRepository) Option {
+	return func(e *Engine) {
+		e.repository = repository
+	}
+}
+
+// WithLogger - writes the logs by the logger, the fiber log is global, so it is set for the whole application
+func WithLogger(logger log.AllLogger) Option {
+	return func(e *Engine) {
+		log.SetLogger(logger)
+	}
+}
+
+// WithMigrations - applies pending migrations of the DB schema on creation, same as RunDBMigration
+func WithMigrations() Option {
+	return func(e *Engine) {
+		e.migrate = true
+	}
+}
+
+// WithoutSwagger - does not serve the swagger UI
+func WithoutSwagger() Option {
+	return func(e *Engine) {
+		e.noSwagger = true
+	}
+}
+
+// InitDefault - sets up the engine by the config in the required order, the engine set up already is kept
 func (e *Engine) InitDefault() error {
+	if e.initialized {
+		return nil
+	}
+
 	// Setup Swagger with default config
-	if err := e.SetupSwagger(""); err != nil {
-		return err
+	if !e.noSwagger {
+		if err := e.SetupSwagger(""); err != nil {
+			return err
+		}
 	}
 
 	// Setup DB
@@ -59,29 +187,180 @@ func (e *Engine) InitDefault() error {
 		return err
 	}
 
+	// Setup Webhooks
+	if err := e.SetupWebhooks(e.config.Webhooks); err != nil {
+		return err
+	}
+
+	// Setup Authenticator
+	if err := e.SetupAuthenticator(e.config.Auth); err != nil {
+		return err
+	}
+
 	// Setup Router
 	if err := e.SetupApi(); err != nil {
 		return err
 	}
+	e.initialized = true
 	return nil
 }
 
+// Listen - serves the API until Shutdown is called, same as Start with the context never done
 func (e *Engine) Listen(addr string) error {
-	if err := e.checkEngineInitialized(); err != nil {
-		return err
-	}
-	return e.App.Listen(addr)
+	return e.Start(context.Background(), addr)
 }
 
+// ListenTLS - serves the API over TLS until Shutdown is called, same as StartTLS with the context never done
 func (e *Engine) ListenTLS(addr, certFile, keyFile string) error {
+	return e.StartTLS(context.Background(), addr, certFile, keyFile)
+}
+
+// Start - starts the background workers and serves the API until Shutdown is called or the context is done,
+// the engine is shut down with DEFAULT_SHUTDOWN_TIMEOUT deadline then. Returns once the shutdown is completed
+func (e *Engine) Start(ctx context.Context, addr string) error {
+	return e.start(ctx, func() (net.Listener, error) {
+		return net.Listen(e.App.Config().Network, addr)
+	})
+}
+
+// Run - starts the background workers without serving the API, for the engine mounted by WithRouter into the app
+// served by the embedding application. Returns once Shutdown is called or the context is done and the shutdown is completed
+func (e *Engine) Run(ctx context.Context) error {
+	return e.start(ctx, nil)
+}
+
+// StartTLS - same as Start, but serves the API over TLS
+func (e *Engine) StartTLS(ctx context.Context, addr, certFile, keyFile string) error {
+	return e.start(ctx, func() (net.Listener, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		return tls.Listen(e.App.Config().Network, addr, &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			Certificates: []tls.Certificate{cert},
+		})
+	})
+}
+
+// start - serves the API by the listener, the API is not served if listen is nil
+func (e *Engine) start(ctx context.Context, listen func() (net.Listener, error)) error {
 	if err := e.checkEngineInitialized(); err != nil {
 		return err
 	}
-	return e.App.ListenTLS(addr, certFile, keyFile)
+	if listen != nil && e.App == nil {
+		return ErrAppIsNotInitialized
+	}
+	if e.isStopping() {
+		return ErrEngineIsShutDown
+	}
+	// refuse to serve with the schema the code does not expect
+	if err := e.CheckDBSchema(); err != nil {
+		return err
+	}
+
+	var ln net.Listener
+	if listen != nil {
+		var err error
+		if ln, err = listen(); err != nil {
+			return err
+		}
+	}
+	// the listener is closed by Shutdown as well, so the server started after it does not serve forever
+	e.mu.Lock()
+	select {
+	case <-e.stopping:
+		e.mu.Unlock()
+		closeListener(ln)
+		return ErrEngineIsShutDown
+	default:
+	}
+	if e.running {
+		e.mu.Unlock()
+		closeListener(ln)
+		return ErrEngineIsRunning
+	}
+	// the workers are started before the engine is published as running, so Shutdown sees them
+	e.startWorkers()
+	e.running = true
+	e.listener = ln
+	e.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), DEFAULT_SHUTDOWN_TIMEOUT)
+			defer cancel()
+			e.Shutdown(shutdownCtx)
+		case <-e.stopping:
+		}
+	}()
+
+	// returns as soon as the listener is closed
+	if ln != nil {
+		if err := e.App.Listener(ln); err != nil && !e.isStopping() {
+			e.Shutdown(context.Background())
+			return err
+		}
+	}
+	<-e.stopped
+	return e.shutdownErr
+}
+
+// Shutdown - stops accepting new requests and waits for the in-flight ones, the background workers
+// and the asynchronous hooks until the context is done, then closes the DB opened by SetupDB.
+// The app is shut down only if it is served by the engine. The engine cannot be started again
+func (e *Engine) Shutdown(ctx context.Context) error {
+	e.shutdownOnce.Do(func() {
+		e.mu.Lock()
+		close(e.stopping)
+		ln := e.listener
+		stopWorkers := e.stopWorkers
+		e.mu.Unlock()
+
+		var errs []error
+		if ln != nil {
+			errs = append(errs, e.App.ShutdownWithContext(ctx))
+			// closed by the server already unless it is shut down before it started serving
+			ln.Close()
+		}
+		if stopWorkers != nil {
+			stopWorkers()
+			errs = append(errs, api.Wait(ctx, &e.workers))
+		}
+		errs = append(errs, e.events.Wait(ctx))
+		if e.db != nil && e.ownDB {
+			sqlDB, err := e.db.DB()
+			if err == nil {
+				err = sqlDB.Close()
+			}
+			errs = append(errs, err)
+		}
+		e.shutdownErr = errors.Join(errs...)
+		close(e.stopped)
+	})
+	<-e.stopped
+	return e.shutdownErr
+}
+
+func closeListener(ln net.Listener) {
+	if ln != nil {
+		ln.Close()
+	}
+}
+
+func (e *Engine) isStopping() bool {
+	select {
+	case <-e.stopping:
+		return true
+	default:
+		return false
+	}
 }
 
 func (e *Engine) SetupSwagger(pathToSwaggerFile string) error {
-	if e.App == nil {
+	router := e.mount()
+	if router == nil {
 		return ErrAppIsNotInitialized
 	}
 	// default swagger config
@@ -97,17 +376,25 @@ func (e *Engine) SetupSwagger(pathToSwaggerFile string) error {
 		e.config.SwaggerConfig.Path = "swagger"
 	}
 
+	// the UI is served under the prefix of the router of the embedding application
+	if group, ok := router.(*fiber.Group); ok && len(e.config.SwaggerConfig.BasePath) <= 0 {
+		e.config.SwaggerConfig.BasePath = group.Prefix
+	}
+
 	e.config.SwaggerConfig.FilePath = pathToSwaggerFile
-	e.App.Use(swagger.New(e.config.SwaggerConfig))
+	router.Use(swagger.New(e.config.SwaggerConfig))
 
 	return nil
 }
 
-func (e *Engine) SetupDB(cfg config.Config) error {
-	var err error
+// SetupDB - connects to the DB selected by db_engine of the config, the DB set before by SetDB is kept
+func (e *Engine) SetupDB(cfg Config) error {
+	if e.db != nil {
+		return nil
+	}
 
 	// github.com/mattn/go-sqlite3
-	if len(cfg.DbEngine) == 0 || cfg.DbEngine == "sqlite3" {
+	if len(cfg.DbEngine) == 0 || cfg.DbEngine == dialects.SQLITE {
 		// check file exist
 		if _, err := os.Stat(cfg.DbUrl); errors.Is(err, os.ErrNotExist) {
 			// set default
@@ -119,44 +406,66 @@ func (e *Engine) SetupDB(cfg config.Config) error {
 
 			cfg.DbUrl = fmt.Sprintf("%s%c%s", dir, os.PathSeparator, "github.com/alex-bezverkhniy/bp-engine.db")
 		}
-
-		e.db, err = gorm.Open(sqlite.Open(cfg.DbUrl), &gorm.Config{})
 	}
 
+	db, err := dialects.Open(cfg.DbEngine, cfg.DbUrl)
 	if err != nil {
 		return err
 	}
-
+	e.db = db
+	e.ownDB = true
 	return nil
 }
 
+// SetDB - sets the DB connection opened by the embedding application, it is not closed on shutdown.
+// Migrations of its dialect other than sqlite, postgres and mysql are added by RegisterMigrations
+func (e *Engine) SetDB(db *gorm.DB) {
+	e.db = db
+	e.ownDB = false
+}
+
+// RunDBMigration - applies pending migrations of the DB schema
 func (e *Engine) RunDBMigration() error {
 	if e.db == nil {
 		return ErrDbIsNotInitialized
 	}
 
-	var migrationErr []error
-	if dbErr := e.db.AutoMigrate(&model.Process{}); dbErr != nil {
-		migrationErr = append(migrationErr, dbErr)
-	}
-	if dbErr := e.db.AutoMigrate(&model.ProcessStatus{}); dbErr != nil {
-		migrationErr = append(migrationErr, dbErr)
+	migrator, err := migrations.NewMigrator(e.db)
+	if err != nil {
+		return err
 	}
+	return migrator.Up(context.Background())
+}
 
-	if len(migrationErr) > 0 {
-		return errors.Join(migrationErr...)
+// CheckDBSchema - returns migrations.ErrSchemaIsBehind if any of the migrations is not applied
+func (e *Engine) CheckDBSchema() error {
+	if e.db == nil {
+		return ErrDbIsNotInitialized
 	}
 
-	return nil
+	migrator, err := migrations.NewMigrator(e.db)
+	if err != nil {
+		return err
+	}
+	return migrator.Check(context.Background())
 }
 
-func (e *Engine) SetupValidator(cfg config.ProcessConfigList) error {
+// SetupValidator - creates built-in validator of the process definitions, custom one set before is kept
+func (e *Engine) SetupValidator(cfg ProcessConfigList) error {
 
 	// Override default config if needed
 	if len(cfg) != 0 {
 		e.config.ProcessConfig = cfg
 	}
 
+	// Lint process definitions
+	if err := e.config.ProcessConfig.Validate(); err != nil {
+		return err
+	}
+	if e.validator != nil {
+		return nil
+	}
+
 	e.validator = validators.NewBasicValidator(e.config.ProcessConfig)
 	err := e.validator.CompileJsonSchema()
 
@@ -166,30 +475,139 @@ func (e *Engine) SetupValidator(cfg config.ProcessConfigList) error {
 	return nil
 }
 
-func (e *Engine) SetValidator(customValidator validators.Validator) {
+func (e *Engine) SetValidator(customValidator Validator) {
 	e.validator = customValidator
 }
 
+// SetupWebhooks - checks and sets the webhook subscriptions, must be called after the validator is set up
+func (e *Engine) SetupWebhooks(cfg WebhookConfigList) error {
+	if err := cfg.Validate(e.config.ProcessConfig); err != nil {
+		return err
+	}
+	e.config.Webhooks = cfg
+	return nil
+}
+
+// SetupAuthenticator - creates built-in authenticator defined by the config, custom one set before is kept
+func (e *Engine) SetupAuthenticator(cfg AuthConfig) error {
+	if e.authenticator != nil {
+		return nil
+	}
+
+	authenticator, err := api.NewAuthenticator(cfg)
+	if err != nil {
+		return err
+	}
+	e.authenticator = authenticator
+	return nil
+}
+
+// SetAuthenticator - sets the authenticator resolving the actor of API requests, anonymous by default
+func (e *Engine) SetAuthenticator(authenticator Authenticator) {
+	e.authenticator = authenticator
+}
+
+// BeforeStatusChange - registers the hook called within the transaction before the status is changed,
+// error of the hook vetoes the change. The hook is called on dry run as well
+func (e *Engine) BeforeStatusChange(handler EventHandler) {
+	e.events.BeforeStatusChange(handler)
+}
+
+// OnSubmitted - registers the hook called within the transaction creating the process, error of the hook rolls it back
+func (e *Engine) OnSubmitted(handler EventHandler) {
+	e.events.OnSubmitted(handler)
+}
+
+// OnSubmittedAsync - registers the hook called in background after the process is created
+func (e *Engine) OnSubmittedAsync(handler EventHandler) {
+	e.events.OnSubmittedAsync(handler)
+}
+
+// OnStatusChanged - registers the hook called within the transaction changing the status, error of the hook rolls it back
+func (e *Engine) OnStatusChanged(handler EventHandler) {
+	e.events.OnStatusChanged(handler)
+}
+
+// OnStatusChangedAsync - registers the hook called in background after the status is changed
+func (e *Engine) OnStatusChangedAsync(handler EventHandler) {
+	e.events.OnStatusChangedAsync(handler)
+}
+
 func (e *Engine) SetupApi() error {
 	if err := e.checkEngineInitialized(); err != nil {
 		return err
 	}
 
-	processRepository := api.NewProcessRepository(e.db)
-	processService := api.NewProcessService(processRepository, e.validator)
+	processRepository := e.repository
+	if processRepository == nil {
+		processRepository = api.NewProcessRepository(e.db)
+	}
+	processService := api.NewProcessService(processRepository, e.validator, e.config.Webhooks, e.events)
 	processController := api.NewProcessController(processService)
-
-	api := e.App.Group("/api")
+	changeController := api.NewChangeController(processService)
+	scheduleRepository := api.NewScheduleRepository(e.db)
+	scheduleController := api.NewScheduleController(api.NewScheduleService(scheduleRepository, processRepository))
+	processDefinitionController := api.NewProcessDefinitionController(e.config.ProcessConfig)
+	webhookRepository := api.NewWebhookRepository(e.db)
+	webhookController := api.NewWebhookController(webhookRepository)
+	authMiddleware := api.NewAuthMiddleware(e.authenticator)
+	adminMiddleware := api.NewRoleMiddleware(e.config.Auth.AdminRoles)
+	e.dispatcher = api.NewWebhookDispatcher(webhookRepository, e.config.Webhooks)
+	e.scheduler = api.NewTimerScheduler(api.NewTimerRepository(e.db), processService)
+	e.worker = api.NewScheduleWorker(scheduleRepository, processService)
+
+	api := e.mount().Group("/api")
 	v1 := api.Group("/v1")
 
 	v1.Get("/health", Health)
-	processController.SetupRouter(v1.Group("/process"))
+	processRouter := v1.Group("/process", authMiddleware)
+	processController.SetupRouter(processRouter)
+	scheduleController.SetupRouter(processRouter)
+	changeController.SetupRouter(v1.Group("/changes", authMiddleware))
+	processDefinitionController.SetupRouter(v1.Group("/process-definitions", authMiddleware))
+	webhookController.SetupRouter(v1.Group("/admin/webhooks", authMiddleware, adminMiddleware))
+
+	return nil
+}
+
+// startWorkers - delivers the webhook events, fires the status timeouts and makes the scheduled assignments
+// in background until the engine is shut down, must be called with mu held
+func (e *Engine) startWorkers() {
+	ctx, cancel := context.WithCancel(context.Background())
+	e.stopWorkers = cancel
+
+	var workers []func(ctx context.Context)
+	if e.dispatcher != nil {
+		workers = append(workers, e.dispatcher.Run)
+	}
+	if e.scheduler != nil {
+		workers = append(workers, e.scheduler.Run)
+	}
+	if e.worker != nil {
+		workers = append(workers, e.worker.Run)
+	}
+	for _, run := range workers {
+		e.workers.Add(1)
+		go func(run func(ctx context.Context)) {
+			defer e.workers.Done()
+			run(ctx)
+		}(run)
+	}
+}
 
+// mount - router the API is mounted under, the one of the embedding application set by WithRouter or App
+func (e *Engine) mount() fiber.Router {
+	if e.router != nil {
+		return e.router
+	}
+	if e.App != nil {
+		return e.App
+	}
 	return nil
 }
 
 func (e *Engine) checkEngineInitialized() error {
-	if e.App == nil {
+	if e.mount() == nil {
 		return ErrAppIsNotInitialized
 	}
 	if e.db == nil {
@@ -215,7 +633,18 @@ func Health(c *fiber.Ctx) error {
 	})
 }
 
-func LoadConfig(filePath, environment string) (config.Config, error) {
+// RegisterDialect - adds the DB engine to be selected by db_engine of the config,
+// sqlite3 (default), postgres and mysql are built in
+func RegisterDialect(name string, factory func(dsn string) gorm.Dialector) {
+	dialects.Register(name, factory)
+}
+
+// RegisterMigrations - sets the migration scripts of the dialect, the name is gorm.Dialector.Name() of the DB
+func RegisterMigrations(dialect string, scripts fs.FS) {
+	migrations.Register(dialect, scripts)
+}
+
+func LoadConfig(filePath, environment string) (Config, error) {
 	if len(filePath) == 0 {
 		filePath = config.DEFAULT_CONFIG_FILEPATH
 	}
@@ -226,5 +655,5 @@ func LoadConfig(filePath, environment string) (config.Config, error) {
 		return *cf, err
 	}
 
-	return config.Config{}, err
+	return Config{}, err
 }
@@ -0,0 +1,220 @@
+package bpengine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/migrations"
+	"github.com/alex-bezverkhniy/bp-engine/internal/validators"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+var testConfig = config.Config{
+	Env: "test",
+	ProcessConfig: config.ProcessConfigList{{
+		Name:   "requests",
+		Schema: `{"type": "object"}`,
+		Statuses: []config.StatusConfig{
+			{Name: "open", Initial: true, Next: config.NextList{{Name: "done"}}},
+			{Name: "done", Final: true},
+		},
+	}},
+}
+
+func testDB(t *testing.T) *gorm.DB {
+	db := emptyDB(t)
+	migrator, err := migrations.NewMigrator(db)
+	assert.NoError(t, err)
+	assert.NoError(t, migrator.Up(context.Background()))
+	return db
+}
+
+// emptyDB - DB without the schema of the engine
+func emptyDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	assert.NoError(t, err)
+	return db
+}
+
+func TestNew_Options(t *testing.T) {
+	tests := []struct {
+		name string
+		// options and the app serving the engine
+		setup    func(db *gorm.DB) (*fiber.App, []Option)
+		wantCode map[string]int
+	}{
+		{
+			name: "own app",
+			setup: func(db *gorm.DB) (*fiber.App, []Option) {
+				return nil, []Option{WithDB(db)}
+			},
+			wantCode: map[string]int{
+				"/api/v1/health":    http.StatusOK,
+				"/swagger":          http.StatusOK,
+				"/bp/api/v1/health": http.StatusNotFound,
+				"/bp/swagger":       http.StatusNotFound,
+			},
+		},
+		{
+			name: "fiber app",
+			setup: func(db *gorm.DB) (*fiber.App, []Option) {
+				app := fiber.New()
+				return app, []Option{WithDB(db), WithFiberApp(app), WithoutSwagger()}
+			},
+			wantCode: map[string]int{
+				"/api/v1/health": http.StatusOK,
+				"/swagger":       http.StatusNotFound,
+			},
+		},
+		{
+			name: "router",
+			setup: func(db *gorm.DB) (*fiber.App, []Option) {
+				app := fiber.New()
+				return app, []Option{WithDB(db), WithRouter(app.Group("/bp"))}
+			},
+			wantCode: map[string]int{
+				"/api/v1/health":    http.StatusNotFound,
+				"/swagger":          http.StatusNotFound,
+				"/bp/api/v1/health": http.StatusOK,
+				"/bp/swagger":       http.StatusOK,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, opts := tt.setup(testDB(t))
+			engine, err := New(testConfig, opts...)
+			assert.NoError(t, err)
+			if app == nil {
+				app = engine.App
+			}
+			// set up already
+			assert.NoError(t, engine.InitDefault())
+
+			for path, wantCode := range tt.wantCode {
+				resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+				assert.NoError(t, err)
+				assert.Equal(t, wantCode, resp.StatusCode, path)
+			}
+		})
+	}
+}
+
+func TestNew_WithValidator(t *testing.T) {
+	validator := &validators.ValidatorMocked{}
+	engine, err := New(testConfig, WithDB(testDB(t)), WithValidator(validator), WithoutSwagger())
+	assert.NoError(t, err)
+	assert.Same(t, validator, engine.validator)
+}
+
+func TestNew_WithMigrations(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name: "migrated",
+			opts: []Option{WithMigrations()},
+		},
+		{
+			name:    "schema is behind",
+			wantErr: migrations.ErrSchemaIsBehind,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := New(testConfig, append(tt.opts, WithDB(emptyDB(t)), WithoutSwagger())...)
+			assert.NoError(t, err)
+			assert.ErrorIs(t, engine.CheckDBSchema(), tt.wantErr)
+
+			// the engine is refused to start with the schema behind only
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			assert.ErrorIs(t, engine.Run(ctx), tt.wantErr)
+		})
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	cfg := testConfig
+	cfg.ProcessConfig = config.ProcessConfigList{{Name: "requests"}}
+	_, err := New(cfg, WithDB(testDB(t)), WithoutSwagger())
+	assert.Error(t, err)
+}
+
+func TestEngine_Run(t *testing.T) {
+	app := fiber.New()
+	engine, err := New(testConfig, WithDB(testDB(t)), WithRouter(app.Group("/bp")), WithoutSwagger())
+	assert.NoError(t, err)
+
+	// the engine mounted by the router does not serve the app
+	assert.ErrorIs(t, engine.Start(context.Background(), ":0"), ErrAppIsNotInitialized)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- engine.Run(ctx)
+	}()
+	cancel()
+	assert.NoError(t, <-done)
+	assert.ErrorIs(t, engine.Run(context.Background()), ErrEngineIsShutDown)
+
+	// the app of the embedding application is not shut down
+	resp, err := app.Test(httptest.NewRequest("GET", "/bp/api/v1/health", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestEngine_ShutdownWhileRunning(t *testing.T) {
+	app := fiber.New()
+	engine, err := New(testConfig, WithDB(testDB(t)), WithRouter(app.Group("/bp")), WithoutSwagger())
+	assert.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- engine.Run(context.Background())
+	}()
+	// the workers are stopped by Shutdown called as soon as the engine is running
+	assert.Eventually(t, func() bool {
+		engine.mu.Lock()
+		defer engine.mu.Unlock()
+		return engine.running
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, engine.Shutdown(context.Background()))
+	assert.NoError(t, <-done)
+}
+
+func TestEngine_SetupApi_Unauthenticated(t *testing.T) {
+	engine, err := New(testConfig)
+	assert.NoError(t, err)
+	engine.SetDB(testDB(t))
+	engine.SetAuthenticator(AuthenticatorFunc(func(c *fiber.Ctx) (Actor, error) {
+		return Actor{}, ErrUnauthenticated
+	}))
+	assert.NoError(t, engine.InitDefault())
+
+	// every API group but the health check is behind the authentication
+	wantCode := map[string]int{
+		"/api/v1/health":                       http.StatusOK,
+		"/api/v1/process-definitions":          http.StatusUnauthorized,
+		"/api/v1/process-definitions/requests": http.StatusUnauthorized,
+		"/api/v1/process/requests/list":        http.StatusUnauthorized,
+		"/api/v1/changes":                      http.StatusUnauthorized,
+		"/api/v1/admin/webhooks/events":        http.StatusUnauthorized,
+	}
+	for path, code := range wantCode {
+		resp, err := engine.App.Test(httptest.NewRequest("GET", path, nil))
+		assert.NoError(t, err)
+		assert.Equal(t, code, resp.StatusCode, path)
+	}
+}
@@ -0,0 +1,73 @@
+package bpengine
+
+import (
+	"github.com/alex-bezverkhniy/bp-engine/internal/api"
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/dialects"
+	"github.com/alex-bezverkhniy/bp-engine/internal/migrations"
+	"github.com/alex-bezverkhniy/bp-engine/internal/validators"
+)
+
+// Errors of the engine to be checked by errors.Is, they are the same values the internal packages return
+
+// Processes
+var (
+	ErrProcessNotFound     = api.ErrProcessNotFound
+	ErrCannotCreateProcess = api.ErrCannotCreateProcess
+	ErrStatusOnSubmit      = api.ErrStatusOnSubmit
+	ErrVersionConflict     = api.ErrVersionConflict
+	ErrStatusChangeVetoed  = api.ErrStatusChangeVetoed
+	ErrInvalidQueryParam   = api.ErrInvalidQueryParam
+	ErrInvalidCursor       = api.ErrInvalidCursor
+	ErrUnauthenticated     = api.ErrUnauthenticated
+)
+
+// Validator
+var (
+	ErrUnknownProcess          = validators.ErrUnknownProcess
+	ErrUnknownStatus           = validators.ErrUnknownStatus
+	ErrNotAllowedStatus        = validators.ErrNotAllowedStatus
+	ErrPayloadValidation       = validators.ErrPayloadValidation
+	ErrFinalStatus             = validators.ErrFinalStatus
+	ErrInitialStatusNotDefined = validators.ErrInitialStatusNotDefined
+	ErrGuardFailed             = validators.ErrGuardFailed
+	ErrForbiddenTransition     = validators.ErrForbiddenTransition
+	ErrReasonRequired          = validators.ErrReasonRequired
+)
+
+// Scheduled assignments and webhooks
+var (
+	ErrScheduledAssignmentNotFound   = api.ErrScheduledAssignmentNotFound
+	ErrScheduledAssignmentNotPending = api.ErrScheduledAssignmentNotPending
+	ErrScheduleStatusRequired        = api.ErrScheduleStatusRequired
+	ErrRunAtNotInFuture              = api.ErrRunAtNotInFuture
+	ErrWebhookEventNotFound          = api.ErrWebhookEventNotFound
+	ErrWebhookEventNotDead           = api.ErrWebhookEventNotDead
+)
+
+// Configuration and DB, ConfigError of the process definitions wraps one of the config errors
+var (
+	ErrConfigFileIsEmpty   = config.ErrConfigFileIsEmpty
+	ErrEmptyName           = config.ErrEmptyName
+	ErrDuplicateProcess    = config.ErrDuplicateProcess
+	ErrDuplicateStatus     = config.ErrDuplicateStatus
+	ErrUnknownNextStatus   = config.ErrUnknownNextStatus
+	ErrUnreachableStatus   = config.ErrUnreachableStatus
+	ErrDeadEndStatus       = config.ErrDeadEndStatus
+	ErrFinalStatusWithNext = config.ErrFinalStatusWithNext
+	ErrInvalidJsonSchema   = config.ErrInvalidJsonSchema
+	ErrNoStatusesDeclared  = config.ErrNoStatusesDeclared
+	ErrInvalidGuard        = config.ErrInvalidGuard
+	ErrDuplicateWebhook    = config.ErrDuplicateWebhook
+	ErrInvalidWebhookUrl   = config.ErrInvalidWebhookUrl
+	ErrInvalidTimeout      = config.ErrInvalidTimeout
+	ErrTimeoutTransition   = config.ErrTimeoutTransition
+	// process or status of the webhook subscription is not defined
+	ErrWebhookUnknownProcess = config.ErrUnknownProcess
+	ErrWebhookUnknownStatus  = config.ErrUnknownStatus
+	ErrUnknownAuthType       = api.ErrUnknownAuthType
+
+	ErrUnknownDbEngine = dialects.ErrUnknownDbEngine
+	ErrUnknownDialect  = migrations.ErrUnknownDialect
+	ErrSchemaIsBehind  = migrations.ErrSchemaIsBehind
+)
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	bpengine "github.com/alex-bezverkhniy/bp-engine"
 	"github.com/gofiber/fiber/v2"
@@ -66,7 +69,12 @@ func main() {
 			log.Fatal("cannot init default engine: ", err)
 		}
 
-		log.Fatal(engine.Listen(":3000"))
+		// SIGINT or SIGTERM shuts the engine down gracefully
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := engine.Start(ctx, ":3000"); err != nil {
+			log.Fatal(err)
+		}
 	}
 
 }
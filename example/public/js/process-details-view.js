@@ -4,7 +4,6 @@ export const ProcessDetails = {
     view: function (vnode) {
         var p = vnode.attrs.data
         var id = `${p.uuid}-dlg`
-        var currentStatusIndex = p.statuses.length - 1
         var created_at = new Date(p.created_at)
         created_at = `${created_at.toLocaleDateString()} ${created_at.toLocaleTimeString()}`
         var changed_at = new Date(p.changed_at)
@@ -41,7 +40,7 @@ export const ProcessDetails = {
                                 m("tr",
                                     [
                                         m("th[scope='col']", m("kbd", "Current Status")),
-                                        m("td", p.statuses[currentStatusIndex].name)
+                                        m("td", p.current_status.name)
                                     ]
                                 ),
                                 m("tr",
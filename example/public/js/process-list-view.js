@@ -25,7 +25,6 @@ export const ProcessList = {
                 
                 m("tbody", Process.list.map(function (p) {
                     console.log(p)
-                    var currentStatusIndex = p.statuses.length - 1
                     var created_at = new Date(p.created_at)
                     return m("tr", [
                         m(ProcessDetails, {data: p}),
@@ -43,7 +42,7 @@ export const ProcessList = {
                         },
                          p.uuid)),
                         m("td.datetime", `${created_at.toLocaleDateString()} ${created_at.toLocaleTimeString()}`),
-                        m("td", p.statuses[currentStatusIndex].name),
+                        m("td", p.current_status.name),
                     ])
                 }))
             ]),
@@ -32,7 +32,7 @@ export const Process = {
             if (res.length > 0) {
                 console.log(res);
                 Process.currentProcess = res[0]
-                Process.currentProcess.currentStatus = Process.currentProcess.statuses[Process.currentProcess.statuses.length - 1]
+                Process.currentProcess.currentStatus = Process.currentProcess.current_status
             }            
             
         })
@@ -85,9 +85,6 @@ export const Process = {
 
         var payload = {
             code: "requests",
-            current_status: {
-                name: "open"
-            },
             payload: {
                 firstName: Process.current.firstName,
                 lastName: Process.current.lastName,
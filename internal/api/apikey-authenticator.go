@@ -0,0 +1,64 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"os"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+const HEADERNAME_API_KEY = "X-API-Key"
+
+type (
+	// ApiKey - static key and the actor it belongs to
+	ApiKey struct {
+		Key   string   `json:"key"`
+		ID    string   `json:"id"`
+		Roles []string `json:"roles,omitempty"`
+	}
+
+	// ApiKeyAuthenticator - authenticates requests by the key passed in the header
+	ApiKeyAuthenticator struct {
+		header string
+		keys   []ApiKey
+	}
+)
+
+func NewApiKeyAuthenticator(header string, keys []ApiKey) *ApiKeyAuthenticator {
+	if len(header) == 0 {
+		header = HEADERNAME_API_KEY
+	}
+	return &ApiKeyAuthenticator{
+		header: header,
+		keys:   keys,
+	}
+}
+
+// LoadApiKeys - reads JSON list of API keys from the file
+func LoadApiKeys(filePath string) ([]ApiKey, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var keys []ApiKey
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (a *ApiKeyAuthenticator) Authenticate(c *fiber.Ctx) (model.Actor, error) {
+	key := c.Get(a.header)
+	if len(key) == 0 {
+		return model.Actor{}, ErrUnauthenticated
+	}
+	for _, k := range a.keys {
+		if len(k.Key) > 0 && subtle.ConstantTimeCompare([]byte(k.Key), []byte(key)) == 1 {
+			return model.Actor{ID: k.ID, Roles: k.Roles}, nil
+		}
+	}
+	return model.Actor{}, ErrUnauthenticated
+}
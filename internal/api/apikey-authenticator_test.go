@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiKeyAuthenticator(t *testing.T) {
+	keysFile := filepath.Join(t.TempDir(), "keys.json")
+	assert.Nil(t, os.WriteFile(keysFile, []byte(`[
+		{"key": "secret-1", "id": "alex", "roles": ["manager"]},
+		{"key": "secret-2", "id": "robot"}
+	]`), 0600))
+	keys, err := LoadApiKeys(keysFile)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name      string
+		header    string
+		headers   map[string]string
+		wantCode  int
+		wantActor model.Actor
+	}{
+		{
+			name:      "success",
+			headers:   map[string]string{HEADERNAME_API_KEY: "secret-1"},
+			wantCode:  http.StatusOK,
+			wantActor: model.Actor{ID: "alex", Roles: []string{"manager"}},
+		},
+		{
+			name:      "success - custom header",
+			header:    "X-Token",
+			headers:   map[string]string{"X-Token": "secret-2"},
+			wantCode:  http.StatusOK,
+			wantActor: model.Actor{ID: "robot"},
+		},
+		{
+			name:     "fail - unknown key",
+			headers:  map[string]string{HEADERNAME_API_KEY: "secret-3"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - key in another header",
+			header:   "X-Token",
+			headers:  map[string]string{HEADERNAME_API_KEY: "secret-1"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - no key",
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotActor, gotCode := authenticate(t, NewApiKeyAuthenticator(tt.header, keys), tt.headers)
+
+			assert.Equal(t, tt.wantCode, gotCode)
+			assert.Equal(t, tt.wantActor, gotActor)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	log "github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	LOCALS_ACTOR = "actor"
+
+	AUTH_TYPE_NONE    = "none"
+	AUTH_TYPE_API_KEY = "api_key"
+	AUTH_TYPE_JWT     = "jwt"
+)
+
+type (
+	// Authenticator - resolves the caller of the request
+	Authenticator interface {
+		Authenticate(c *fiber.Ctx) (model.Actor, error)
+	}
+
+	// AuthenticatorFunc - adapter to use ordinary function as Authenticator
+	AuthenticatorFunc func(c *fiber.Ctx) (model.Actor, error)
+
+	// AnonymousAuthenticator - treats every caller as anonymous actor without roles
+	AnonymousAuthenticator struct{}
+)
+
+var (
+	ErrUnauthenticated = errors.New("unauthenticated")
+	ErrUnknownAuthType = errors.New("unknown auth type")
+
+	UnauthenticatedErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "unauthenticated",
+	}
+	ForbiddenErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "forbidden",
+	}
+)
+
+// NewAuthenticator - creates one of the built-in authenticators defined by the config
+func NewAuthenticator(cfg config.AuthConfig) (Authenticator, error) {
+	switch cfg.Type {
+	case "", AUTH_TYPE_NONE:
+		return AnonymousAuthenticator{}, nil
+	case AUTH_TYPE_API_KEY:
+		keys, err := LoadApiKeys(cfg.ApiKey.KeysFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewApiKeyAuthenticator(cfg.ApiKey.Header, keys), nil
+	case AUTH_TYPE_JWT:
+		return NewJwtAuthenticator(cfg.Jwt)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownAuthType, cfg.Type)
+}
+
+func (f AuthenticatorFunc) Authenticate(c *fiber.Ctx) (model.Actor, error) {
+	return f(c)
+}
+
+func (AnonymousAuthenticator) Authenticate(*fiber.Ctx) (model.Actor, error) {
+	return model.Actor{}, nil
+}
+
+// NewAuthMiddleware - authenticates the request and keeps the actor in the context locals
+func NewAuthMiddleware(auth Authenticator) fiber.Handler {
+	if auth == nil {
+		auth = AnonymousAuthenticator{}
+	}
+	return func(c *fiber.Ctx) error {
+		// authenticated already by the middleware of the group matching the path by prefix,
+		// e.g. /process matches /process-definitions
+		if _, ok := c.Locals(LOCALS_ACTOR).(model.Actor); ok {
+			return c.Next()
+		}
+		actor, err := auth.Authenticate(c)
+		if err != nil {
+			log.Error("cannot authenticate request ", err)
+			return c.Status(fiber.StatusUnauthorized).JSON(UnauthenticatedErrResp)
+		}
+		c.Locals(LOCALS_ACTOR, actor)
+		return c.Next()
+	}
+}
+
+// ActorFromContext - returns the actor resolved by the auth middleware, anonymous actor if there is none
+func ActorFromContext(c *fiber.Ctx) model.Actor {
+	actor, _ := c.Locals(LOCALS_ACTOR).(model.Actor)
+	return actor
+}
+
+// NewRoleMiddleware - allows the request to the actor having any of the roles, anyone is allowed if roles are empty
+func NewRoleMiddleware(roles []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(roles) > 0 && !ActorFromContext(c).HasAnyRole(roles) {
+			return c.Status(fiber.StatusForbidden).JSON(ForbiddenErrResp)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		auth      Authenticator
+		wantCode  int
+		wantActor model.Actor
+		wantErr   *model.ProcessErrorResponse
+	}{
+		{
+			name:     "anonymous by default",
+			auth:     nil,
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "success",
+			auth: AuthenticatorFunc(func(c *fiber.Ctx) (model.Actor, error) {
+				return model.Actor{ID: c.Get("X-User"), Roles: strings.Split(c.Get("X-Roles"), ",")}, nil
+			}),
+			wantCode:  http.StatusOK,
+			wantActor: model.Actor{ID: "alex", Roles: []string{"manager", "clerk"}},
+		},
+		{
+			name: "fail - 401",
+			auth: AuthenticatorFunc(func(c *fiber.Ctx) (model.Actor, error) {
+				return model.Actor{}, errors.New("OMG error")
+			}),
+			wantCode: http.StatusUnauthorized,
+			wantErr:  &UnauthenticatedErrResp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testApp = fiber.New()
+			testApp.Get("/test", NewAuthMiddleware(tt.auth), func(c *fiber.Ctx) error {
+				return c.JSON(ActorFromContext(c))
+			})
+
+			req := httptest.NewRequest("GET", "http://localhost/test", nil)
+			req.Header.Add("X-User", "alex")
+			req.Header.Add("X-Roles", "manager,clerk")
+
+			resp, err := testApp.Test(req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.Nil(t, err)
+
+			if tt.wantErr != nil {
+				var gotResp model.ProcessErrorResponse
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, *tt.wantErr, gotResp)
+			} else {
+				var gotActor model.Actor
+				assert.Nil(t, json.Unmarshal(body, &gotActor))
+				assert.Equal(t, tt.wantActor, gotActor)
+			}
+		})
+	}
+}
+
+func TestNewAuthMiddleware_AuthenticatedAlready(t *testing.T) {
+	var calls int
+	authMiddleware := NewAuthMiddleware(AuthenticatorFunc(func(c *fiber.Ctx) (model.Actor, error) {
+		calls++
+		return model.Actor{ID: "alex"}, nil
+	}))
+	var testApp = fiber.New()
+	testApp.Group("/process", authMiddleware)
+	testApp.Group("/process-definitions", authMiddleware).Get("/", func(c *fiber.Ctx) error {
+		return c.JSON(ActorFromContext(c))
+	})
+
+	// both groups match the path, the request is authenticated once
+	resp, err := testApp.Test(httptest.NewRequest("GET", "http://localhost/process-definitions", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 1, calls)
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	dir := t.TempDir()
+	keysFile := filepath.Join(dir, "keys.json")
+	assert.Nil(t, os.WriteFile(keysFile, []byte(`[{"key": "secret", "id": "alex"}]`), 0600))
+	secretFile := filepath.Join(dir, "secret")
+	assert.Nil(t, os.WriteFile(secretFile, []byte("jwt-secret\n"), 0600))
+
+	tests := []struct {
+		name    string
+		cfg     config.AuthConfig
+		want    Authenticator
+		wantErr error
+	}{
+		{
+			name: "none by default",
+			cfg:  config.AuthConfig{},
+			want: AnonymousAuthenticator{},
+		},
+		{
+			name: "api key",
+			cfg: config.AuthConfig{
+				Type:   AUTH_TYPE_API_KEY,
+				ApiKey: config.ApiKeyConfig{KeysFile: keysFile},
+			},
+			want: NewApiKeyAuthenticator("", []ApiKey{{Key: "secret", ID: "alex"}}),
+		},
+		{
+			name: "jwt",
+			cfg: config.AuthConfig{
+				Type: AUTH_TYPE_JWT,
+				Jwt:  config.JwtConfig{Algorithm: JWT_ALGORITHM_HS256, KeyFile: secretFile},
+			},
+		},
+		{
+			name: "failed - api keys file not found",
+			cfg: config.AuthConfig{
+				Type:   AUTH_TYPE_API_KEY,
+				ApiKey: config.ApiKeyConfig{KeysFile: filepath.Join(dir, "missing.json")},
+			},
+			wantErr: os.ErrNotExist,
+		},
+		{
+			name: "failed - unsupported jwt algorithm",
+			cfg: config.AuthConfig{
+				Type: AUTH_TYPE_JWT,
+				Jwt:  config.JwtConfig{Algorithm: "none", KeyFile: secretFile},
+			},
+			wantErr: ErrUnsupportedJwtAlgorithm,
+		},
+		{
+			name:    "failed - unknown type",
+			cfg:     config.AuthConfig{Type: "basic"},
+			wantErr: ErrUnknownAuthType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewAuthenticator(tt.cfg)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.Nil(t, err)
+				assert.NotNil(t, got)
+				if tt.want != nil {
+					assert.Equal(t, tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+// authenticate - runs the authenticator within the auth middleware, returns the resolved actor and the status code
+func authenticate(t *testing.T, auth Authenticator, headers map[string]string) (model.Actor, int) {
+	var testApp = fiber.New()
+	testApp.Get("/test", NewAuthMiddleware(auth), func(c *fiber.Ctx) error {
+		return c.JSON(ActorFromContext(c))
+	})
+
+	req := httptest.NewRequest("GET", "http://localhost/test", nil)
+	for k, v := range headers {
+		req.Header.Add(k, v)
+	}
+	resp, err := testApp.Test(req)
+	assert.Nil(t, err)
+
+	var actor model.Actor
+	if resp.StatusCode == http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		assert.Nil(t, json.Unmarshal(body, &actor))
+	}
+	return actor, resp.StatusCode
+}
+
+func TestNewRoleMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		roles    []string
+		actor    model.Actor
+		wantCode int
+	}{
+		{
+			name:     "no roles required",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "has role",
+			roles:    []string{"admin"},
+			actor:    model.Actor{ID: "alex", Roles: []string{"manager", "admin"}},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "fail - 403",
+			roles:    []string{"admin"},
+			actor:    model.Actor{ID: "alex", Roles: []string{"manager"}},
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := AuthenticatorFunc(func(c *fiber.Ctx) (model.Actor, error) {
+				return tt.actor, nil
+			})
+			var testApp = fiber.New()
+			testApp.Get("/test", NewAuthMiddleware(auth), NewRoleMiddleware(tt.roles), func(c *fiber.Ctx) error {
+				return c.SendStatus(http.StatusOK)
+			})
+
+			resp, err := testApp.Test(httptest.NewRequest("GET", "http://localhost/test", nil))
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+}
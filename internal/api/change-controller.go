@@ -0,0 +1,98 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	log "github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	QUERYPARAM_AFTER = "after"
+	QUERYPARAM_LIMIT = "limit"
+
+	DEFAULT_CHANGES_LIMIT = 100
+	MAX_CHANGES_LIMIT     = 1000
+)
+
+type (
+	// ChangeFeedResponse - batch of the change feed, NextAfter is the offset to request the next batch from
+	ChangeFeedResponse struct {
+		Data      model.ProcessChangeListDTO `json:"data"`
+		NextAfter uint                       `json:"next_after" example:"42"`
+		HasMore   bool                       `json:"has_more"`
+	}
+
+	ChangeController struct {
+		service ProcessService
+	}
+)
+
+var (
+	NotSupportedValueForAfterErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "not supported value for " + QUERYPARAM_AFTER,
+	}
+	NotSupportedValueForLimitErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "not supported value for " + QUERYPARAM_LIMIT,
+	}
+	CannotGetChangesErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "cannot get process changes",
+	}
+)
+
+func NewChangeController(service ProcessService) *ChangeController {
+	return &ChangeController{
+		service: service,
+	}
+}
+
+func (cc *ChangeController) SetupRouter(router fiber.Router) {
+	router.Get("/", cc.GetList)
+}
+
+// @Summary Change feed of processes
+// @Description Submits and status changes of all processes ordered by the sequence number (ID of the change).
+// @Description The consumer keeps next_after of the batch and requests the next one after it, so the feed can be replayed from any offset
+// @Tags changes
+// @Param	after	query	int	false	"Sequence number of the last consumed change, 0 to start from the beginning"
+// @Param	limit	query	int	false	"Max number of changes in the batch, 100 by default, 1000 max"
+// @Produce json
+// @Success 200 {object} ChangeFeedResponse
+// @Failed	400 {object} model.ProcessErrorResponse
+// @Router /api/v1/changes [get]
+func (cc *ChangeController) GetList(c *fiber.Ctx) error {
+	after, err := strconv.ParseUint(c.Query(QUERYPARAM_AFTER, "0"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(NotSupportedValueForAfterErrResp)
+	}
+	limit, err := strconv.Atoi(c.Query(QUERYPARAM_LIMIT, strconv.Itoa(DEFAULT_CHANGES_LIMIT)))
+	if err != nil || limit <= 0 || limit > MAX_CHANGES_LIMIT {
+		return c.Status(fiber.StatusBadRequest).JSON(NotSupportedValueForLimitErrResp)
+	}
+
+	// one extra change tells whether there are more of them
+	changes, err := cc.service.Changes(c.Context(), ChangeQuery{After: uint(after), Limit: limit + 1})
+	if err != nil {
+		log.Error("cannot get process changes ", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(CannotGetChangesErrResp)
+	}
+
+	resp := ChangeFeedResponse{
+		Data:      changes,
+		NextAfter: uint(after),
+	}
+	if len(changes) > limit {
+		resp.Data = changes[:limit]
+		resp.HasMore = true
+	}
+	if len(resp.Data) > 0 {
+		resp.NextAfter = resp.Data[len(resp.Data)-1].ID
+	}
+
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
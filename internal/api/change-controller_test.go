@@ -0,0 +1,145 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetChangeList(t *testing.T) {
+	defaultUuid := uuid.NewString()
+	createdAt := time.Date(2023, 12, 8, 11, 33, 55, 0, time.UTC)
+	submitted := model.ProcessChangeDTO{ID: 41, Type: model.EVENT_PROCESS_SUBMITTED, Code: "requests", UUID: defaultUuid, Status: model.ProcessStatusDTO{Name: "open", CreatedAt: &createdAt}}
+	changed := model.ProcessChangeDTO{ID: 42, Type: model.EVENT_PROCESS_STATUS_CHANGED, Code: "orders", UUID: defaultUuid, PreviousStatus: "open", Status: model.ProcessStatusDTO{Name: "done", CreatedAt: &createdAt}}
+
+	tests := []struct {
+		name     string
+		query    string
+		mockFunc func() *ChangeController
+		wantCode int
+		wantResp *ChangeFeedResponse
+		wantErr  *model.ProcessErrorResponse
+	}{
+		{
+			name:  "success",
+			query: "?after=40&limit=5",
+			mockFunc: func() *ChangeController {
+				service := ProcessSrvcMock{}
+				service.On("Changes", mock.Anything, ChangeQuery{After: 40, Limit: 6}).
+					Return(model.ProcessChangeListDTO{submitted, changed}, nil)
+				return NewChangeController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantResp: &ChangeFeedResponse{
+				Data:      model.ProcessChangeListDTO{submitted, changed},
+				NextAfter: 42,
+			},
+		},
+		{
+			name:  "success - has more",
+			query: "?after=40&limit=1",
+			mockFunc: func() *ChangeController {
+				service := ProcessSrvcMock{}
+				service.On("Changes", mock.Anything, ChangeQuery{After: 40, Limit: 2}).
+					Return(model.ProcessChangeListDTO{submitted, changed}, nil)
+				return NewChangeController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantResp: &ChangeFeedResponse{
+				Data:      model.ProcessChangeListDTO{submitted},
+				NextAfter: 41,
+				HasMore:   true,
+			},
+		},
+		{
+			name:  "success - no new changes",
+			query: "?after=42",
+			mockFunc: func() *ChangeController {
+				service := ProcessSrvcMock{}
+				service.On("Changes", mock.Anything, ChangeQuery{After: 42, Limit: DEFAULT_CHANGES_LIMIT + 1}).
+					Return(model.ProcessChangeListDTO{}, nil)
+				return NewChangeController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantResp: &ChangeFeedResponse{
+				Data:      model.ProcessChangeListDTO{},
+				NextAfter: 42,
+			},
+		},
+		{
+			name:  "fail - 400 - after",
+			query: "?after=-1",
+			mockFunc: func() *ChangeController {
+				return NewChangeController(&ProcessSrvcMock{})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedValueForAfterErrResp,
+		},
+		{
+			name:  "fail - 400 - limit",
+			query: "?limit=0",
+			mockFunc: func() *ChangeController {
+				return NewChangeController(&ProcessSrvcMock{})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedValueForLimitErrResp,
+		},
+		{
+			name:  "fail - 400 - limit is too big",
+			query: fmt.Sprintf("?limit=%d", MAX_CHANGES_LIMIT+1),
+			mockFunc: func() *ChangeController {
+				return NewChangeController(&ProcessSrvcMock{})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedValueForLimitErrResp,
+		},
+		{
+			name: "fail - 500",
+			mockFunc: func() *ChangeController {
+				service := ProcessSrvcMock{}
+				service.On("Changes", mock.Anything, ChangeQuery{Limit: DEFAULT_CHANGES_LIMIT + 1}).
+					Return(nil, errors.New("OMG error"))
+				return NewChangeController(&service)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantErr:  &CannotGetChangesErrResp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testApp = fiber.New()
+			tt.mockFunc().SetupRouter(testApp.Group("/test/changes"))
+
+			req := httptest.NewRequest("GET", "http://localhost/test/changes"+tt.query, nil)
+			resp, err := testApp.Test(req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.Nil(t, err)
+			if tt.wantErr != nil {
+				var gotResp model.ProcessErrorResponse
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, *tt.wantErr, gotResp)
+				return
+			}
+
+			var gotResp ChangeFeedResponse
+			assert.Nil(t, json.Unmarshal(body, &gotResp))
+			assert.Equal(t, *tt.wantResp, gotResp)
+		})
+	}
+}
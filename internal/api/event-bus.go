@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	log "github.com/gofiber/fiber/v2/log"
+)
+
+type (
+	// EventHandler - hook of the process lifecycle event
+	EventHandler func(ctx context.Context, event model.ProcessEvent) error
+
+	// EventBus - in-process hooks of the process lifecycle. Synchronous handlers are called
+	// within the DB transaction and fail it with their error, asynchronous ones are called
+	// in background after the transaction is committed. Safe for concurrent use, nil bus has no handlers
+	EventBus struct {
+		mu    sync.RWMutex
+		sync  map[string][]EventHandler
+		async map[string][]EventHandler
+		// running asynchronous handlers
+		running sync.WaitGroup
+	}
+)
+
+var ErrStatusChangeVetoed = errors.New("status change is vetoed")
+
+func NewEventBus() *EventBus {
+	return &EventBus{
+		sync:  map[string][]EventHandler{},
+		async: map[string][]EventHandler{},
+	}
+}
+
+// Subscribe - registers synchronous handler of the event type
+func (b *EventBus) Subscribe(eventType string, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sync[eventType] = append(b.sync[eventType], handler)
+}
+
+// SubscribeAsync - registers asynchronous handler of the event type
+func (b *EventBus) SubscribeAsync(eventType string, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.async[eventType] = append(b.async[eventType], handler)
+}
+
+// BeforeStatusChange - registers handler called before the status is changed, error of the handler vetoes the change.
+// The handler is called on dry run as well, so it should have no side effects
+func (b *EventBus) BeforeStatusChange(handler EventHandler) {
+	b.Subscribe(model.EVENT_PROCESS_BEFORE_STATUS_CHANGE, handler)
+}
+
+// OnSubmitted - registers handler called within the transaction creating the process
+func (b *EventBus) OnSubmitted(handler EventHandler) {
+	b.Subscribe(model.EVENT_PROCESS_SUBMITTED, handler)
+}
+
+// OnSubmittedAsync - registers handler called after the process is created
+func (b *EventBus) OnSubmittedAsync(handler EventHandler) {
+	b.SubscribeAsync(model.EVENT_PROCESS_SUBMITTED, handler)
+}
+
+// OnStatusChanged - registers handler called within the transaction changing the status
+func (b *EventBus) OnStatusChanged(handler EventHandler) {
+	b.Subscribe(model.EVENT_PROCESS_STATUS_CHANGED, handler)
+}
+
+// OnStatusChangedAsync - registers handler called after the status is changed
+func (b *EventBus) OnStatusChangedAsync(handler EventHandler) {
+	b.SubscribeAsync(model.EVENT_PROCESS_STATUS_CHANGED, handler)
+}
+
+// Publish - calls the synchronous handlers in order of registration, stops on the first error
+func (b *EventBus) Publish(ctx context.Context, event model.ProcessEvent) error {
+	for _, handler := range b.handlers(event.Type, false) {
+		if err := handler(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishAsync - calls the asynchronous handlers in order of registration in background,
+// the errors are logged only. The handlers keep the request info but not the cancellation of the context
+func (b *EventBus) PublishAsync(ctx context.Context, event model.ProcessEvent) {
+	handlers := b.handlers(event.Type, true)
+	if len(handlers) == 0 {
+		return
+	}
+
+	bgCtx := ContextWithRequestInfo(context.Background(), RequestInfoFromContext(ctx))
+	b.running.Add(1)
+	go func() {
+		defer b.running.Done()
+		for _, handler := range handlers {
+			if err := callAsync(bgCtx, handler, event); err != nil {
+				log.Errorf("%s hook failed: %v", event.Type, err)
+			}
+		}
+	}()
+}
+
+// Wait - waits for the running asynchronous handlers until the context is done
+func (b *EventBus) Wait(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	return Wait(ctx, &b.running)
+}
+
+func (b *EventBus) handlers(eventType string, async bool) []EventHandler {
+	if b == nil {
+		return nil
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if async {
+		return b.async[eventType]
+	}
+	return b.sync[eventType]
+}
+
+// Wait - waits for the group until the context is done, returns the error of the context if it is done first
+func Wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// callAsync - the panic of the handler must not crash the engine
+func callAsync(ctx context.Context, handler EventHandler, event model.ProcessEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus_Publish(t *testing.T) {
+	var calls []string
+	handler := func(name string, err error) EventHandler {
+		return func(ctx context.Context, event model.ProcessEvent) error {
+			calls = append(calls, name+":"+event.Type)
+			return err
+		}
+	}
+	errVeto := errors.New("veto")
+
+	tests := []struct {
+		name      string
+		setup     func(bus *EventBus)
+		event     model.ProcessEvent
+		wantCalls []string
+		wantErr   error
+	}{
+		{
+			name: "no handlers",
+			setup: func(bus *EventBus) {
+				bus.OnSubmitted(handler("submitted", nil))
+			},
+			event: model.ProcessEvent{Type: model.EVENT_PROCESS_STATUS_CHANGED},
+		},
+		{
+			name: "in order of registration",
+			setup: func(bus *EventBus) {
+				bus.OnStatusChanged(handler("first", nil))
+				bus.OnStatusChanged(handler("second", nil))
+				bus.OnStatusChangedAsync(handler("async", nil))
+			},
+			event:     model.ProcessEvent{Type: model.EVENT_PROCESS_STATUS_CHANGED},
+			wantCalls: []string{"first:process.status_changed", "second:process.status_changed"},
+		},
+		{
+			name: "stops on the first error",
+			setup: func(bus *EventBus) {
+				bus.BeforeStatusChange(handler("first", errVeto))
+				bus.BeforeStatusChange(handler("second", nil))
+			},
+			event:     model.ProcessEvent{Type: model.EVENT_PROCESS_BEFORE_STATUS_CHANGE},
+			wantCalls: []string{"first:process.before_status_change"},
+			wantErr:   errVeto,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls = nil
+			bus := NewEventBus()
+			tt.setup(bus)
+
+			err := bus.Publish(context.Background(), tt.event)
+
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func TestEventBus_PublishAsync(t *testing.T) {
+	done := make(chan model.ProcessEvent, 2)
+	bus := NewEventBus()
+	bus.OnSubmittedAsync(func(ctx context.Context, event model.ProcessEvent) error {
+		panic("OMG panic")
+	})
+	bus.OnSubmittedAsync(func(ctx context.Context, event model.ProcessEvent) error {
+		assert.Equal(t, "42", RequestInfoFromContext(ctx).RequestID)
+		done <- event
+		return errors.New("OMG error")
+	})
+	bus.OnSubmitted(func(ctx context.Context, event model.ProcessEvent) error {
+		t.Error("synchronous handler must not be called")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(ContextWithRequestInfo(context.Background(), RequestInfo{RequestID: "42"}))
+	event := model.ProcessEvent{Type: model.EVENT_PROCESS_SUBMITTED, Actor: model.Actor{ID: "alex"}}
+	bus.PublishAsync(ctx, event)
+	cancel()
+
+	select {
+	case got := <-done:
+		assert.Equal(t, event, got)
+	case <-time.After(time.Second):
+		t.Fatal("asynchronous handler is not called")
+	}
+}
+
+func TestEventBus_Nil(t *testing.T) {
+	var bus *EventBus
+
+	assert.Nil(t, bus.Publish(context.Background(), model.ProcessEvent{Type: model.EVENT_PROCESS_SUBMITTED}))
+	bus.PublishAsync(context.Background(), model.ProcessEvent{Type: model.EVENT_PROCESS_SUBMITTED})
+}
+
+func TestWait(t *testing.T) {
+	tests := []struct {
+		name    string
+		busy    bool
+		wantErr error
+	}{
+		{
+			name: "group is done",
+		},
+		{
+			name:    "deadline exceeded",
+			busy:    true,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			release := make(chan struct{})
+			defer close(release)
+			if tt.busy {
+				wg.Add(1)
+				go func() {
+					<-release
+					wg.Done()
+				}()
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			assert.ErrorIs(t, Wait(ctx, &wg), tt.wantErr)
+		})
+	}
+}
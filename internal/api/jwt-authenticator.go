@@ -0,0 +1,258 @@
+package api
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+)
+
+const (
+	JWT_ALGORITHM_HS256 = "HS256"
+	JWT_ALGORITHM_RS256 = "RS256"
+
+	DEFAULT_JWT_ROLES_CLAIM = "roles"
+
+	// allowed clock difference between the token issuer and the engine
+	jwtLeeway = time.Minute
+)
+
+type (
+	// JwtAuthenticator - authenticates requests by `Authorization: Bearer <token>` header,
+	// `sub` claim is the actor ID, roles are taken from the roles claim
+	JwtAuthenticator struct {
+		algorithm     string
+		hmacKey       []byte
+		rsaKey        *rsa.PublicKey
+		issuer        string
+		audience      string
+		rolesClaim    string
+		allowNoExpiry bool
+		now           func() time.Time
+	}
+
+	jwtHeader struct {
+		Alg string `json:"alg"`
+		Typ string `json:"typ,omitempty"`
+	}
+)
+
+var (
+	ErrUnsupportedJwtAlgorithm = errors.New("unsupported JWT algorithm")
+	ErrInvalidJwtKey           = errors.New("invalid JWT key")
+	ErrInvalidJwt              = errors.New("invalid JWT")
+)
+
+// NewJwtAuthenticator - creates the authenticator, the key is loaded from the file of the config
+func NewJwtAuthenticator(cfg config.JwtConfig) (*JwtAuthenticator, error) {
+	content, err := os.ReadFile(cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &JwtAuthenticator{
+		algorithm:     cfg.Algorithm,
+		issuer:        cfg.Issuer,
+		audience:      cfg.Audience,
+		rolesClaim:    cfg.RolesClaim,
+		allowNoExpiry: cfg.AllowNoExpiry,
+		now:           time.Now,
+	}
+	if len(a.rolesClaim) == 0 {
+		a.rolesClaim = DEFAULT_JWT_ROLES_CLAIM
+	}
+
+	switch cfg.Algorithm {
+	case JWT_ALGORITHM_HS256:
+		a.hmacKey = bytes.TrimSpace(content)
+		if len(a.hmacKey) == 0 {
+			return nil, fmt.Errorf("%w: secret is empty", ErrInvalidJwtKey)
+		}
+	case JWT_ALGORITHM_RS256:
+		a.rsaKey, err = parseRsaPublicKey(content)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedJwtAlgorithm, cfg.Algorithm)
+	}
+
+	return a, nil
+}
+
+// parseRsaPublicKey - accepts PEM encoded PKIX or PKCS #1 public key and X.509 certificate
+func parseRsaPublicKey(content []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("%w: PEM block not found", ErrInvalidJwtKey)
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("%w: not supported PEM block %q", ErrInvalidJwtKey, block.Type)
+	}
+	if err != nil {
+		return nil, errors.Join(ErrInvalidJwtKey, err)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not RSA public key", ErrInvalidJwtKey)
+	}
+	return rsaKey, nil
+}
+
+func (a *JwtAuthenticator) Authenticate(c *fiber.Ctx) (model.Actor, error) {
+	authorization := c.Get(fiber.HeaderAuthorization)
+	token, found := strings.CutPrefix(authorization, "Bearer ")
+	if !found || len(token) == 0 {
+		return model.Actor{}, ErrUnauthenticated
+	}
+
+	claims, err := a.verify(token)
+	if err != nil {
+		return model.Actor{}, errors.Join(ErrUnauthenticated, err)
+	}
+
+	sub, _ := claims["sub"].(string)
+	if len(sub) == 0 {
+		return model.Actor{}, errors.Join(ErrUnauthenticated, fmt.Errorf("%w: sub claim is missing", ErrInvalidJwt))
+	}
+
+	return model.Actor{
+		ID:    sub,
+		Roles: rolesFromClaim(claims[a.rolesClaim]),
+	}, nil
+}
+
+// verify - checks the signature and the registered claims, returns all claims of the token
+func (a *JwtAuthenticator) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidJwt)
+	}
+
+	var header jwtHeader
+	if err := decodeJwtPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+	// the algorithm is defined by the engine config, never by the token
+	if header.Alg != a.algorithm {
+		return nil, fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidJwt, header.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidJwt)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	switch a.algorithm {
+	case JWT_ALGORITHM_HS256:
+		mac := hmac.New(sha256.New, a.hmacKey)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidJwt)
+		}
+	case JWT_ALGORITHM_RS256:
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(a.rsaKey, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidJwt)
+		}
+	default:
+		return nil, ErrUnsupportedJwtAlgorithm
+	}
+
+	var claims map[string]interface{}
+	if err := decodeJwtPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	now := a.now()
+	exp, ok := claims["exp"].(float64)
+	if !ok && !a.allowNoExpiry {
+		return nil, fmt.Errorf("%w: exp claim is missing", ErrInvalidJwt)
+	}
+	if ok && now.After(time.Unix(int64(exp), 0).Add(jwtLeeway)) {
+		return nil, fmt.Errorf("%w: token is expired", ErrInvalidJwt)
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(jwtLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("%w: token is not valid yet", ErrInvalidJwt)
+	}
+	if len(a.issuer) > 0 && claims["iss"] != a.issuer {
+		return nil, fmt.Errorf("%w: unexpected issuer", ErrInvalidJwt)
+	}
+	if len(a.audience) > 0 && !hasAudience(claims["aud"], a.audience) {
+		return nil, fmt.Errorf("%w: unexpected audience", ErrInvalidJwt)
+	}
+
+	return claims, nil
+}
+
+func decodeJwtPart(part string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("%w: malformed token", ErrInvalidJwt)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: malformed token", ErrInvalidJwt)
+	}
+	return nil
+}
+
+// hasAudience - `aud` claim is either single string or list of strings
+func hasAudience(aud interface{}, audience string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, a := range v {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// rolesFromClaim - roles claim is either list of strings or space separated string
+func rolesFromClaim(claim interface{}) []string {
+	var roles []string
+	switch v := claim.(type) {
+	case string:
+		roles = strings.Fields(v)
+	case []interface{}:
+		for _, r := range v {
+			if role, ok := r.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	}
+	return roles
+}
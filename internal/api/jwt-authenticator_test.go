@@ -0,0 +1,233 @@
+package api
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// signJwt - builds the token signed with HMAC secret ([]byte) or RSA private key
+func signJwt(t *testing.T, alg string, key interface{}, claims map[string]interface{}) string {
+	header, err := json.Marshal(jwtHeader{Alg: alg, Typ: "JWT"})
+	assert.Nil(t, err)
+	payload, err := json.Marshal(claims)
+	assert.Nil(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		assert.Nil(t, err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJwtAuthenticator(t *testing.T) {
+	dir := t.TempDir()
+	secret := []byte("jwt-secret")
+	secretFile := filepath.Join(dir, "secret")
+	assert.Nil(t, os.WriteFile(secretFile, append(secret, '\n'), 0600))
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	otherRsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	pubKey, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	assert.Nil(t, err)
+	pubKeyPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubKey})
+	pubKeyFile := filepath.Join(dir, "public.pem")
+	assert.Nil(t, os.WriteFile(pubKeyFile, pubKeyPem, 0600))
+
+	hs256 := config.JwtConfig{Algorithm: JWT_ALGORITHM_HS256, KeyFile: secretFile}
+	rs256 := config.JwtConfig{Algorithm: JWT_ALGORITHM_RS256, KeyFile: pubKeyFile, Issuer: "idp", Audience: "bp-engine"}
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name      string
+		cfg       config.JwtConfig
+		token     string
+		wantCode  int
+		wantActor model.Actor
+	}{
+		{
+			name:      "success - HS256",
+			cfg:       hs256,
+			token:     signJwt(t, "HS256", secret, map[string]interface{}{"sub": "alex", "roles": []string{"manager"}, "exp": now + 60}),
+			wantCode:  http.StatusOK,
+			wantActor: model.Actor{ID: "alex", Roles: []string{"manager"}},
+		},
+		{
+			name:      "success - HS256 - custom roles claim",
+			cfg:       config.JwtConfig{Algorithm: JWT_ALGORITHM_HS256, KeyFile: secretFile, RolesClaim: "scope"},
+			token:     signJwt(t, "HS256", secret, map[string]interface{}{"sub": "alex", "scope": "manager clerk", "exp": now + 60}),
+			wantCode:  http.StatusOK,
+			wantActor: model.Actor{ID: "alex", Roles: []string{"manager", "clerk"}},
+		},
+		{
+			name:      "success - RS256",
+			cfg:       rs256,
+			token:     signJwt(t, "RS256", rsaKey, map[string]interface{}{"sub": "alex", "iss": "idp", "aud": []string{"other", "bp-engine"}, "nbf": now, "exp": now + 60}),
+			wantCode:  http.StatusOK,
+			wantActor: model.Actor{ID: "alex"},
+		},
+		{
+			name:     "fail - no token",
+			cfg:      hs256,
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - malformed token",
+			cfg:      hs256,
+			token:    "abc.def",
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - HS256 - wrong secret",
+			cfg:      hs256,
+			token:    signJwt(t, "HS256", []byte("other-secret"), map[string]interface{}{"sub": "alex", "exp": now + 60}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - HS256 - expired",
+			cfg:      hs256,
+			token:    signJwt(t, "HS256", secret, map[string]interface{}{"sub": "alex", "exp": now - 3600}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - HS256 - no expiration",
+			cfg:      hs256,
+			token:    signJwt(t, "HS256", secret, map[string]interface{}{"sub": "alex"}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:      "success - HS256 - no expiration allowed",
+			cfg:       config.JwtConfig{Algorithm: JWT_ALGORITHM_HS256, KeyFile: secretFile, AllowNoExpiry: true},
+			token:     signJwt(t, "HS256", secret, map[string]interface{}{"sub": "alex"}),
+			wantCode:  http.StatusOK,
+			wantActor: model.Actor{ID: "alex"},
+		},
+		{
+			name:     "fail - HS256 - not valid yet",
+			cfg:      hs256,
+			token:    signJwt(t, "HS256", secret, map[string]interface{}{"sub": "alex", "nbf": now + 3600, "exp": now + 60}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - HS256 - no subject",
+			cfg:      hs256,
+			token:    signJwt(t, "HS256", secret, map[string]interface{}{"roles": []string{"manager"}, "exp": now + 60}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - HS256 - unsigned token",
+			cfg:      hs256,
+			token:    signJwt(t, "none", nil, map[string]interface{}{"sub": "alex", "exp": now + 60}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - RS256 - signed by another key",
+			cfg:      rs256,
+			token:    signJwt(t, "RS256", otherRsaKey, map[string]interface{}{"sub": "alex", "iss": "idp", "aud": "bp-engine", "exp": now + 60}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - RS256 - public key used as HS256 secret",
+			cfg:      rs256,
+			token:    signJwt(t, "HS256", pubKeyPem, map[string]interface{}{"sub": "alex", "iss": "idp", "aud": "bp-engine", "exp": now + 60}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - RS256 - wrong issuer",
+			cfg:      rs256,
+			token:    signJwt(t, "RS256", rsaKey, map[string]interface{}{"sub": "alex", "iss": "other", "aud": "bp-engine", "exp": now + 60}),
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "fail - RS256 - wrong audience",
+			cfg:      rs256,
+			token:    signJwt(t, "RS256", rsaKey, map[string]interface{}{"sub": "alex", "iss": "idp", "aud": "other", "exp": now + 60}),
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth, err := NewJwtAuthenticator(tt.cfg)
+			assert.Nil(t, err)
+
+			headers := map[string]string{}
+			if len(tt.token) > 0 {
+				headers["Authorization"] = "Bearer " + tt.token
+			}
+			gotActor, gotCode := authenticate(t, auth, headers)
+
+			assert.Equal(t, tt.wantCode, gotCode)
+			assert.Equal(t, tt.wantActor, gotActor)
+		})
+	}
+}
+
+func TestNewJwtAuthenticator(t *testing.T) {
+	dir := t.TempDir()
+	emptyFile := filepath.Join(dir, "empty")
+	assert.Nil(t, os.WriteFile(emptyFile, []byte("\n"), 0600))
+	notPemFile := filepath.Join(dir, "not.pem")
+	assert.Nil(t, os.WriteFile(notPemFile, []byte("secret"), 0600))
+
+	tests := []struct {
+		name    string
+		cfg     config.JwtConfig
+		wantErr error
+	}{
+		{
+			name:    "failed - key file not found",
+			cfg:     config.JwtConfig{Algorithm: JWT_ALGORITHM_HS256, KeyFile: filepath.Join(dir, "missing")},
+			wantErr: os.ErrNotExist,
+		},
+		{
+			name:    "failed - empty secret",
+			cfg:     config.JwtConfig{Algorithm: JWT_ALGORITHM_HS256, KeyFile: emptyFile},
+			wantErr: ErrInvalidJwtKey,
+		},
+		{
+			name:    "failed - not PEM encoded public key",
+			cfg:     config.JwtConfig{Algorithm: JWT_ALGORITHM_RS256, KeyFile: notPemFile},
+			wantErr: ErrInvalidJwtKey,
+		},
+		{
+			name:    "failed - unsupported algorithm",
+			cfg:     config.JwtConfig{Algorithm: "ES256", KeyFile: notPemFile},
+			wantErr: ErrUnsupportedJwtAlgorithm,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewJwtAuthenticator(tt.cfg)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
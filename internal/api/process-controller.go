@@ -2,6 +2,8 @@ package api
 
 import (
 	"errors"
+	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -15,13 +17,19 @@ import (
 const (
 	HEADERNAME_PAGE_SIZE = "X-Page-Size"
 	HEADERNAME_PAGE      = "X-Page"
+	HEADERNAME_IF_MATCH  = "If-Match"
+	HEADERNAME_ETAG      = "ETag"
+
+	QUERYPARAM_DRY_RUN = "dry_run"
 )
 
 type (
 	PaginatedResponse struct {
-		Data     model.ProcessListDTO `json:"data"`
-		Page     int                  `json:"page"`
-		PageSize int                  `json:"page_size"`
+		Data       model.ProcessListDTO `json:"data"`
+		Page       int                  `json:"page,omitempty"`
+		PageSize   int                  `json:"page_size"`
+		NextCursor string               `json:"next_cursor,omitempty" example:"eyJzIjoiY3JlYXRlZF9hdCIsInQiOiIyMDIzLTEyLTA4VDExOjMzOjU1WiIsImkiOjQyfQ"`
+		Total      *int64               `json:"total,omitempty" example:"42"`
 	}
 
 	ProcessController struct {
@@ -52,6 +60,14 @@ var (
 		Status:  "error",
 		Message: "cannot create new process",
 	}
+	CannotGetTransitionsErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "cannot get allowed transitions",
+	}
+	NotSupportedValueForDryRunErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "not supported value for " + QUERYPARAM_DRY_RUN,
+	}
 	CannotMoveItIntoNewStatusErrResp = model.ProcessErrorResponse{
 		Status:  "error",
 		Message: "cannot move into new status",
@@ -73,6 +89,38 @@ var (
 		Status:  "error",
 		Message: "not allowed process status",
 	}
+	NotSupportedValueForIfMatchHdrErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "not supported value for " + HEADERNAME_IF_MATCH,
+	}
+	ProcessVersionConflictErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "process has been changed by someone else",
+	}
+	ProcessInFinalStatusErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "process is in final status",
+	}
+	StatusNotAllowedOnSubmitErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "status cannot be set on submit",
+	}
+	UnknownProcessCodeErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "unknown process code",
+	}
+	InitialStatusNotDefinedErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "initial status is not defined for the process",
+	}
+	ReasonRequiredErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "reason is required to move the process into the status",
+	}
+	ForbiddenTransitionErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "not allowed to move the process into the status",
+	}
 )
 
 func NewProcessController(service ProcessService) *ProcessController {
@@ -84,7 +132,10 @@ func NewProcessController(service ProcessService) *ProcessController {
 func (pc *ProcessController) SetupRouter(router fiber.Router) {
 	router.Post("/", pc.Submit)
 	router.Get("/:code/list", pc.GetList)
+	router.Get("/:code/events", pc.StreamEvents)
 	router.Get("/:code/:uuid", pc.Get)
+	router.Get("/:code/:uuid/transitions", pc.GetTransitions)
+	router.Get("/:code/:uuid/events", pc.StreamProcessEvents)
 	router.Patch("/:code/:uuid/assign/:status", pc.AssignStatus)
 }
 
@@ -92,9 +143,11 @@ func (pc *ProcessController) SetupRouter(router fiber.Router) {
 // @Description Submits/Creates new process
 // @Tags process
 // @Accept application/json
-// @Param	request	body	ProcessDTO	true	"ProcessRequest"
+// @Param	request	body	model.ProcessDTO	true	"ProcessRequest"
 // @Produce json
-// @Success 200 {object} ProcessSubmitResponse
+// @Success 200 {object} model.ProcessSubmitResponse
+// @Failed	400 {object} model.ProcessErrorResponse
+// @Failed	500 {object} model.ProcessErrorResponse
 // @Router /api/v1/process/ [post]
 func (pc *ProcessController) Submit(c *fiber.Ctx) error {
 	var process model.ProcessDTO
@@ -105,10 +158,22 @@ func (pc *ProcessController) Submit(c *fiber.Ctx) error {
 	}
 
 	log.Infof("create new process: %v", process)
-	uuid, err := pc.service.Submit(c.Context(), &process)
+	uuid, err := pc.service.Submit(requestContext(c), &process, ActorFromContext(c))
 
 	if err != nil {
 		log.Error("cannot create new process ", err)
+		if errors.Is(err, ErrStatusOnSubmit) {
+			return c.Status(fiber.StatusBadRequest).JSON(StatusNotAllowedOnSubmitErrResp)
+		}
+		if errors.Is(err, validators.ErrUnknownProcess) {
+			return c.Status(fiber.StatusBadRequest).JSON(UnknownProcessCodeErrResp)
+		}
+		if errors.Is(err, validators.ErrInitialStatusNotDefined) {
+			return c.Status(fiber.StatusBadRequest).JSON(InitialStatusNotDefinedErrResp)
+		}
+		if errors.Is(err, validators.ErrPayloadValidation) {
+			return c.Status(fiber.StatusBadRequest).JSON(payloadValidationErrResponse(err))
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(CannotCreateNewProcessErrResp)
 	}
 	res := model.ProcessSubmitResponse{
@@ -123,8 +188,20 @@ func (pc *ProcessController) Submit(c *fiber.Ctx) error {
 // @Param	code		path	string	true	"Code of Process"
 // @Param	X-Page		header	int		false	"Page number"
 // @Param	X-Page-Size	header	int		false	"Page size"
+// @Param	page		query	int		false	"Page number, overrides X-Page"
+// @Param	page_size	query	int		false	"Page size, overrides X-Page-Size"
+// @Param	cursor		query	string	false	"Cursor of the next page, see next_cursor"
+// @Param	total		query	bool	false	"Count total number of matched processes"
+// @Param	status		query	string	false	"Current status, comma separated list is supported"
+// @Param	created_from	query	string	false	"Created at or after, RFC 3339"
+// @Param	created_to		query	string	false	"Created before, RFC 3339"
+// @Param	changed_from	query	string	false	"Changed at or after, RFC 3339"
+// @Param	changed_to		query	string	false	"Changed before, RFC 3339"
+// @Param	sort		query	string	false	"Sort by created_at or changed_at, prefix - for descending order"
+// @Param	payload.{field}	query	string	false	"Payload field filter, e.g. payload.amount[gte]=100, operators: eq, ne, gt, gte, lt, lte, contains"
 // @Produce json
-// @Success 200 {object} ProcessListDTO
+// @Success 200 {object} PaginatedResponse
+// @Header	200 {string} Link "RFC 8288 links to the first and the next pages"
 // @Router /api/v1/process/{code}/list [get]
 func (pc *ProcessController) GetList(c *fiber.Ctx) error {
 	code := c.Params("code")
@@ -149,24 +226,56 @@ func (pc *ProcessController) GetList(c *fiber.Ctx) error {
 		pageSize = DEFAULT_PAGE_SIZE
 	}
 
+	queryParams := url.Values{}
+	c.Context().QueryArgs().VisitAll(func(key, val []byte) {
+		queryParams.Add(string(key), string(val))
+	})
+	query, err := NewProcessQuery(code, queryParams)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.ProcessErrorResponse{
+			Status:  "error",
+			Message: err.Error(),
+		})
+	}
+	// Query parameters take precedence over the page headers
+	if query.Page == 0 {
+		query.Page = page
+	}
+	if query.PageSize == 0 {
+		query.PageSize = pageSize
+	}
+
 	log.Info("get lits of process by code: ", code)
-	processesList, err := pc.service.Get(c.Context(), code, "", page, pageSize)
+	processesList, pageInfo, err := pc.service.Find(c.Context(), query)
 
 	if err != nil {
 		log.Error("cannot get processes list by code ", err)
 		if errors.Is(err, ErrProcessNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(ProcessNotFoundErrResp)
 		}
+		if errors.Is(err, ErrInvalidCursor) {
+			return c.Status(fiber.StatusBadRequest).JSON(model.ProcessErrorResponse{
+				Status:  "error",
+				Message: err.Error(),
+			})
+		}
 
 		return c.Status(fiber.StatusInternalServerError).JSON(CannotGetListProcessErrResp)
 	}
 
 	resp := PaginatedResponse{
-		Data:     processesList,
-		Page:     page,
-		PageSize: pageSize,
+		Data:       processesList,
+		PageSize:   query.PageSize,
+		NextCursor: pageInfo.NextCursor,
+		Total:      pageInfo.Total,
+	}
+	// the page number is not used when paging by the cursor
+	if len(query.Cursor) == 0 {
+		resp.Page = query.Page
 	}
 
+	c.Set(fiber.HeaderLink, paginationLinks(c.BaseURL()+c.Path(), queryParams, query.PageSize, pageInfo))
+
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -176,9 +285,10 @@ func (pc *ProcessController) GetList(c *fiber.Ctx) error {
 // @Param	code	path	string	true	"Code of Process"
 // @Param	uuid	path	string	true	"UUID of Process"
 // @Produce json
-// @Success	200 {object} ProcessListDTO
-// @Failed	404 {object} ProcessErrorResponse
-// @Failed	500 {object} ProcessErrorResponse
+// @Success	200 {object} model.ProcessListDTO
+// @Header	200 {string} ETag "Version of the process"
+// @Failed	404 {object} model.ProcessErrorResponse
+// @Failed	500 {object} model.ProcessErrorResponse
 // @Router /api/v1/process/{code}/{uuid} [get]
 func (pc *ProcessController) Get(c *fiber.Ctx) error {
 	uuid := c.Params("uuid")
@@ -195,6 +305,10 @@ func (pc *ProcessController) Get(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(CannotGetProcessErrResp)
 	}
 
+	if len(process) == 1 {
+		c.Set(HEADERNAME_ETAG, formatETag(process[0].Version))
+	}
+
 	return c.Status(fiber.StatusOK).JSON(process)
 }
 
@@ -205,53 +319,150 @@ func (pc *ProcessController) Get(c *fiber.Ctx) error {
 // @Param	code	path	string				true	"Code of Process"
 // @Param	uuid	path	string				true	"UUID of Process"
 // @Param	status	path	string				true	"Status of Process"
-// @Param	request	body	ProcessStatusDTO	true	"ProcessStatus"
+// @Param	If-Match	header	string			false	"ETag of the process version to change"
+// @Param	dry_run	query	bool				false	"Validate only, the process is not changed. Rejected change is returned as valid: false"
+// @Param	request	body	model.ProcessStatusDTO	true	"ProcessStatus"
 // @Produce json
 // @Success 204
+// @Success 200 {object} model.ProcessValidationResponse
+// @Failed	403 {object} model.ProcessErrorResponse
+// @Failed	409 {object} model.ProcessErrorResponse
 // @Router /api/v1/process/{code}/{uuid}/assign/{status}	[patch]
 func (pc *ProcessController) AssignStatus(c *fiber.Ctx) error {
 	code := c.Params("code")
 	uuid := c.Params("uuid")
 	status := c.Params("status")
-	ctx := c.Context()
+	ctx := requestContext(c)
+
+	version, err := parseETag(c.Get(HEADERNAME_IF_MATCH))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(NotSupportedValueForIfMatchHdrErrResp)
+	}
 
 	var processStatus model.ProcessStatusDTO
-	err := c.BodyParser(&processStatus)
+	err = c.BodyParser(&processStatus)
 	if err != nil {
 		log.Error("cannot read request body ", err)
 		return c.Status(fiber.StatusBadRequest).JSON(CannotReadRequestBodyErrResp)
 	}
 
+	dryRun, err := strconv.ParseBool(c.Query(QUERYPARAM_DRY_RUN, "false"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(NotSupportedValueForDryRunErrResp)
+	}
+
+	// status name, actor and origin of the change are not taken from the body
+	newStatus := model.ProcessStatusDTO{
+		Name:    status,
+		Payload: processStatus.Payload,
+		Reason:  processStatus.Reason,
+	}
+
 	log.Info("get process by uuid: ", uuid)
-	log.Info("move it to: ", status)
+	if dryRun {
+		log.Info("validate moving it to: ", status)
+		err = pc.service.ValidateStatus(ctx, code, uuid, newStatus, version, ActorFromContext(c))
+		if err == nil {
+			return c.Status(fiber.StatusOK).JSON(model.ProcessValidationResponse{
+				Valid:  true,
+				Status: status,
+			})
+		}
 
-	err = pc.service.AssignStatus(ctx, code, uuid, status, processStatus.Payload)
+		// the rules rejecting the change are the validation result, other errors are returned as is
+		log.Info("cannot move into new status ", err)
+		respCode, errResp := assignStatusErr(err)
+		if respCode != fiber.StatusBadRequest && respCode != fiber.StatusForbidden {
+			return c.Status(respCode).JSON(errResp)
+		}
+		return c.Status(fiber.StatusOK).JSON(model.ProcessValidationResponse{
+			Valid:   false,
+			Status:  status,
+			Message: errResp.Message,
+			Details: errResp.Details,
+		})
+	}
+
+	log.Info("move it to: ", status)
+	err = pc.service.AssignStatus(ctx, code, uuid, newStatus, version, ActorFromContext(c))
 	if err != nil {
 		log.Error("cannot move into new status ", err)
+		return assignStatusErrResponse(c, err)
+	}
+
+	c.Status(fiber.StatusNoContent)
+	return nil
+
+}
+
+// @Summary Get allowed transitions
+// @Description Get statuses the process can be moved into from its current status
+// @Tags process
+// @Param	code	path	string	true	"Code of Process"
+// @Param	uuid	path	string	true	"UUID of Process"
+// @Produce json
+// @Success	200 {object} model.TransitionListDTO
+// @Failed	404 {object} model.ProcessErrorResponse
+// @Failed	500 {object} model.ProcessErrorResponse
+// @Router /api/v1/process/{code}/{uuid}/transitions [get]
+func (pc *ProcessController) GetTransitions(c *fiber.Ctx) error {
+	uuid := c.Params("uuid")
+	code := c.Params("code")
+	log.Infof("get transitions of process by code: %s and UUID: %s", code, uuid)
+
+	transitions, err := pc.service.Transitions(c.Context(), code, uuid, ActorFromContext(c))
+	if err != nil {
+		log.Error("cannot get allowed transitions ", err)
 		if errors.Is(err, ErrProcessNotFound) {
 			return c.Status(fiber.StatusNotFound).JSON(ProcessNotFoundErrResp)
 		}
-		if errors.Is(err, validators.ErrUnknownStatus) {
-			return c.Status(fiber.StatusBadRequest).JSON(NotSupportedProcessStatusErrResp)
-		}
-		if errors.Is(err, validators.ErrNotAllowedStatus) {
-			return c.Status(fiber.StatusBadRequest).JSON(NotAllowedProcessStatusErrResp)
-		}
-		if errors.Is(err, validators.ErrPayloadValidation) {
-			return c.Status(fiber.StatusBadRequest).JSON(
-				model.ProcessErrorResponse{
-					Status:  "error",
-					Message: strings.ReplaceAll(err.Error(), "\n", ""),
-				},
-			)
-		}
-
-		return c.Status(fiber.StatusInternalServerError).JSON(CannotMoveItIntoNewStatusErrResp)
+		return c.Status(fiber.StatusInternalServerError).JSON(CannotGetTransitionsErrResp)
 	}
 
-	c.Status(fiber.StatusNoContent)
-	return nil
+	return c.Status(fiber.StatusOK).JSON(transitions)
+}
 
+// assignStatusErrResponse - maps errors of the status assignment to the response
+func assignStatusErrResponse(c *fiber.Ctx, err error) error {
+	code, errResp := assignStatusErr(err)
+	return c.Status(code).JSON(errResp)
+}
+
+// assignStatusErr - maps errors of the status assignment to the response code and body
+func assignStatusErr(err error) (int, model.ProcessErrorResponse) {
+	if errors.Is(err, ErrProcessNotFound) {
+		return fiber.StatusNotFound, ProcessNotFoundErrResp
+	}
+	if errors.Is(err, ErrVersionConflict) {
+		return fiber.StatusConflict, ProcessVersionConflictErrResp
+	}
+	if errors.Is(err, validators.ErrUnknownStatus) {
+		return fiber.StatusBadRequest, NotSupportedProcessStatusErrResp
+	}
+	if errors.Is(err, validators.ErrNotAllowedStatus) {
+		return fiber.StatusBadRequest, NotAllowedProcessStatusErrResp
+	}
+	if errors.Is(err, validators.ErrFinalStatus) {
+		return fiber.StatusBadRequest, ProcessInFinalStatusErrResp
+	}
+	if errors.Is(err, validators.ErrReasonRequired) {
+		return fiber.StatusBadRequest, ReasonRequiredErrResp
+	}
+	if errors.Is(err, validators.ErrForbiddenTransition) {
+		return fiber.StatusForbidden, ForbiddenTransitionErrResp
+	}
+	// the reason given by the guard or the hook is returned as is
+	if errors.Is(err, validators.ErrGuardFailed) || errors.Is(err, ErrStatusChangeVetoed) {
+		return fiber.StatusBadRequest, model.ProcessErrorResponse{
+			Status:  "error",
+			Message: err.Error(),
+		}
+	}
+	if errors.Is(err, validators.ErrPayloadValidation) {
+		return fiber.StatusBadRequest, payloadValidationErrResponse(err)
+	}
+
+	return fiber.StatusInternalServerError, CannotMoveItIntoNewStatusErrResp
 }
 
 func getHeaderValue[T string | int | float64](headers map[string][]string, key string, defaultVal T) (T, error) {
@@ -275,3 +486,61 @@ func getHeaderValue[T string | int | float64](headers map[string][]string, key s
 	}
 	return defaultVal, err
 }
+
+func formatETag(version uint) string {
+	return strconv.Quote(strconv.FormatUint(uint64(version), 10))
+}
+
+// parseETag - returns version from If-Match header value, zero means any version
+func parseETag(val string) (uint, error) {
+	val = strings.TrimSpace(val)
+	if len(val) == 0 || val == "*" {
+		return 0, nil
+	}
+	val = strings.TrimPrefix(val, "W/")
+	val = strings.Trim(val, `"`)
+	version, err := strconv.ParseUint(val, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if version == 0 {
+		return 0, strconv.ErrRange
+	}
+	return uint(version), nil
+}
+
+// paginationLinks - builds RFC 8288 Link header value, filters of the request are kept
+func paginationLinks(baseURL string, params url.Values, pageSize int, pageInfo PageInfo) string {
+	link := func(rel, cursor string) string {
+		linkParams := url.Values{}
+		for k, v := range params {
+			linkParams[k] = v
+		}
+		linkParams.Del(QUERYPARAM_PAGE)
+		linkParams.Del(QUERYPARAM_CURSOR)
+		linkParams.Set(QUERYPARAM_PAGE_SIZE, strconv.Itoa(pageSize))
+		if len(cursor) > 0 {
+			linkParams.Set(QUERYPARAM_CURSOR, cursor)
+		}
+		return fmt.Sprintf(`<%s?%s>; rel="%s"`, baseURL, linkParams.Encode(), rel)
+	}
+
+	links := []string{link("first", "")}
+	if len(pageInfo.NextCursor) > 0 {
+		links = append(links, link("next", pageInfo.NextCursor))
+	}
+	return strings.Join(links, ", ")
+}
+
+// payloadValidationErrResponse - builds the response with the failed fields of the payload
+func payloadValidationErrResponse(err error) model.ProcessErrorResponse {
+	resp := model.ProcessErrorResponse{
+		Status:  "error",
+		Message: err.Error(),
+	}
+	var pve *validators.PayloadValidationError
+	if errors.As(err, &pve) {
+		resp.Details = pve.Causes
+	}
+	return resp
+}
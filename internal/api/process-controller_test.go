@@ -18,6 +18,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func TestSubmit(t *testing.T) {
@@ -27,12 +28,13 @@ func TestSubmit(t *testing.T) {
 		reqPayload model.ProcessDTO
 	}
 	tests := []struct {
-		name     string
-		args     args
-		wantCode int
-		wantResp model.ProcessSubmitResponse
-		wantErr  *model.ProcessErrorResponse
-		mockFunc func(args) *ProcessController
+		name               string
+		args               args
+		wantCode           int
+		simulateBadRequest bool
+		wantResp           model.ProcessSubmitResponse
+		wantErr            *model.ProcessErrorResponse
+		mockFunc           func(args) *ProcessController
 	}{
 		{
 			name: "fail - 400",
@@ -44,15 +46,96 @@ func TestSubmit(t *testing.T) {
 					},
 				},
 			},
+			simulateBadRequest: true,
 			mockFunc: func(args args) *ProcessController {
 				service := ProcessSrvcMock{}
-				service.On("Submit", mock.Anything, &args.reqPayload).
+				service.On("Submit", mock.Anything, &args.reqPayload, model.Actor{}).
 					Return(defaultUuid, nil)
 				return NewProcessController(&service)
 			},
 			wantCode: http.StatusBadRequest,
 			wantErr:  &CannotReadRequestBodyErrResp,
 		},
+		{
+			name: "fail - 400 - status on submit",
+			args: args{
+				reqPayload: model.ProcessDTO{
+					Code: "requests",
+					CurrentStatus: &model.ProcessStatusDTO{
+						Name: "done",
+					},
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("Submit", mock.Anything, &args.reqPayload, model.Actor{}).
+					Return("", ErrStatusOnSubmit)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &StatusNotAllowedOnSubmitErrResp,
+		},
+		{
+			name: "fail - 400 - initial status is not defined",
+			args: args{
+				reqPayload: model.ProcessDTO{
+					Code: "requests",
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("Submit", mock.Anything, &args.reqPayload, model.Actor{}).
+					Return("", validators.ErrInitialStatusNotDefined)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &InitialStatusNotDefinedErrResp,
+		},
+		{
+			name: "fail - 400 - unknown process code",
+			args: args{
+				reqPayload: model.ProcessDTO{
+					Code: "orders",
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("Submit", mock.Anything, &args.reqPayload, model.Actor{}).
+					Return("", validators.ErrUnknownProcess)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &UnknownProcessCodeErrResp,
+		},
+		{
+			name: "fail - 400 - payload validation",
+			args: args{
+				reqPayload: model.ProcessDTO{
+					Code: "requests",
+					Payload: model.Payload{
+						"customer_id": "abc",
+					},
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("Submit", mock.Anything, &args.reqPayload, model.Actor{}).
+					Return("", &validators.PayloadValidationError{
+						Causes: []model.ValidationErrorDetail{
+							{Pointer: "/customer_id", Keyword: "type", Message: "expected integer, but got string"},
+						},
+					})
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr: &model.ProcessErrorResponse{
+				Status:  "error",
+				Message: "payload validation error: /customer_id: expected integer, but got string",
+				Details: []model.ValidationErrorDetail{
+					{Pointer: "/customer_id", Keyword: "type", Message: "expected integer, but got string"},
+				},
+			},
+		},
 		{
 			name: "fail - 500",
 			args: args{
@@ -65,7 +148,7 @@ func TestSubmit(t *testing.T) {
 			},
 			mockFunc: func(args args) *ProcessController {
 				service := ProcessSrvcMock{}
-				service.On("Submit", mock.Anything, &args.reqPayload).
+				service.On("Submit", mock.Anything, &args.reqPayload, model.Actor{}).
 					Return("", errors.New("OMG error"))
 				return NewProcessController(&service)
 			},
@@ -84,7 +167,7 @@ func TestSubmit(t *testing.T) {
 			},
 			mockFunc: func(args args) *ProcessController {
 				service := ProcessSrvcMock{}
-				service.On("Submit", mock.Anything, &args.reqPayload).
+				service.On("Submit", mock.Anything, &args.reqPayload, model.Actor{}).
 					Return(defaultUuid, nil)
 				return NewProcessController(&service)
 			},
@@ -106,7 +189,7 @@ func TestSubmit(t *testing.T) {
 
 			var data []byte
 			var err error
-			if tt.wantCode == http.StatusBadRequest {
+			if tt.simulateBadRequest {
 				data = []byte("something bad")
 			} else {
 				data, err = json.Marshal(tt.args.reqPayload)
@@ -149,16 +232,19 @@ func TestSubmit(t *testing.T) {
 
 func TestGetList(t *testing.T) {
 	defaultUuid := uuid.NewString()
+	testCursor := ProcessQuery{Code: "test", SortBy: SortByCreatedAt}.nextCursor(model.Process{Model: gorm.Model{ID: 7}})
 	// ctx := context.Background()
 	type args struct {
 		code     string
 		page     string
 		pageSize string
+		query    string
 	}
 	tests := []struct {
 		name     string
 		args     args
 		wantCode int
+		wantLink string
 		wantResp PaginatedResponse
 		wantErr  *model.ProcessErrorResponse
 		mockFunc func(args) *ProcessController
@@ -172,8 +258,13 @@ func TestGetList(t *testing.T) {
 			},
 			mockFunc: func(args args) *ProcessController {
 				service := ProcessSrvcMock{}
-				service.On("Get", mock.Anything, args.code, "", DEFAULT_PAGE, 5).
-					Return(nil, ErrProcessNotFound)
+				service.On("Find", mock.Anything, ProcessQuery{
+					Code:     args.code,
+					SortBy:   SortByCreatedAt,
+					Page:     DEFAULT_PAGE,
+					PageSize: 5,
+				}).
+					Return(nil, PageInfo{}, ErrProcessNotFound)
 				return NewProcessController(&service)
 			},
 			wantCode: http.StatusBadRequest,
@@ -188,8 +279,13 @@ func TestGetList(t *testing.T) {
 			},
 			mockFunc: func(args args) *ProcessController {
 				service := ProcessSrvcMock{}
-				service.On("Get", mock.Anything, args.code, "", DEFAULT_PAGE, DEFAULT_PAGE_SIZE).
-					Return(nil, ErrProcessNotFound)
+				service.On("Find", mock.Anything, ProcessQuery{
+					Code:     args.code,
+					SortBy:   SortByCreatedAt,
+					Page:     DEFAULT_PAGE,
+					PageSize: DEFAULT_PAGE_SIZE,
+				}).
+					Return(nil, PageInfo{}, ErrProcessNotFound)
 				return NewProcessController(&service)
 			},
 			wantCode: http.StatusBadRequest,
@@ -204,8 +300,13 @@ func TestGetList(t *testing.T) {
 			},
 			mockFunc: func(args args) *ProcessController {
 				service := ProcessSrvcMock{}
-				service.On("Get", mock.Anything, args.code, "", DEFAULT_PAGE, DEFAULT_PAGE_SIZE).
-					Return(nil, ErrProcessNotFound)
+				service.On("Find", mock.Anything, ProcessQuery{
+					Code:     args.code,
+					SortBy:   SortByCreatedAt,
+					Page:     DEFAULT_PAGE,
+					PageSize: DEFAULT_PAGE_SIZE,
+				}).
+					Return(nil, PageInfo{}, ErrProcessNotFound)
 				return NewProcessController(&service)
 			},
 			wantCode: http.StatusNotFound,
@@ -220,8 +321,13 @@ func TestGetList(t *testing.T) {
 			},
 			mockFunc: func(args args) *ProcessController {
 				service := ProcessSrvcMock{}
-				service.On("Get", mock.Anything, args.code, "", DEFAULT_PAGE, DEFAULT_PAGE_SIZE).
-					Return(nil, errors.New("OMG error"))
+				service.On("Find", mock.Anything, ProcessQuery{
+					Code:     args.code,
+					SortBy:   SortByCreatedAt,
+					Page:     DEFAULT_PAGE,
+					PageSize: DEFAULT_PAGE_SIZE,
+				}).
+					Return(nil, PageInfo{}, errors.New("OMG error"))
 				return NewProcessController(&service)
 			},
 			wantCode: http.StatusInternalServerError,
@@ -236,13 +342,78 @@ func TestGetList(t *testing.T) {
 			},
 			mockFunc: func(args args) *ProcessController {
 				service := ProcessSrvcMock{}
-				service.On("Get", mock.Anything, args.code, "", 1, 5).
+				service.On("Find", mock.Anything, ProcessQuery{
+					Code:     args.code,
+					SortBy:   SortByCreatedAt,
+					Page:     1,
+					PageSize: 5,
+				}).
 					Return(model.ProcessListDTO{
 						{
 							Code: "test",
 							UUID: defaultUuid,
 						},
-					}, nil)
+					}, PageInfo{}, nil)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantResp: PaginatedResponse{
+				Page:     1,
+				PageSize: 5,
+				Data: model.ProcessListDTO{
+					{
+						Code: "test",
+						UUID: defaultUuid,
+					},
+				},
+			},
+		},
+		{
+			name: "failed - 400 wrong filter",
+			args: args{
+				code:     "test",
+				page:     "1",
+				pageSize: "5",
+				query:    "payload.amount[between]=1",
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr: &model.ProcessErrorResponse{
+				Status:  "error",
+				Message: `invalid query parameter: not supported operator "between"`,
+			},
+		},
+		{
+			name: "success - filters",
+			args: args{
+				code:     "test",
+				page:     "1",
+				pageSize: "5",
+				query:    "status=open,in_progress&payload.customer_id=42&payload.amount[lt]=10000&sort=-changed_at",
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("Find", mock.Anything, ProcessQuery{
+					Code:     args.code,
+					Statuses: []string{"open", "in_progress"},
+					Payload: []PayloadFilter{
+						{Field: "amount", Operator: OperatorLt, Value: "10000"},
+						{Field: "customer_id", Operator: OperatorEq, Value: "42"},
+					},
+					SortBy:   SortByChangedAt,
+					SortDesc: true,
+					Page:     1,
+					PageSize: 5,
+				}).
+					Return(model.ProcessListDTO{
+						{
+							Code: "test",
+							UUID: defaultUuid,
+						},
+					}, PageInfo{}, nil)
 				return NewProcessController(&service)
 			},
 			wantCode: http.StatusOK,
@@ -257,6 +428,103 @@ func TestGetList(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "success - query params, cursor and total",
+			args: args{
+				code:     "test",
+				page:     "3",
+				pageSize: "5",
+				query:    "page_size=2&total=true&status=open",
+			},
+			mockFunc: func(args args) *ProcessController {
+				total := int64(3)
+				service := ProcessSrvcMock{}
+				service.On("Find", mock.Anything, ProcessQuery{
+					Code:      args.code,
+					Statuses:  []string{"open"},
+					SortBy:    SortByCreatedAt,
+					Page:      3,
+					PageSize:  2,
+					WithTotal: true,
+				}).
+					Return(model.ProcessListDTO{
+						{
+							Code: "test",
+							UUID: defaultUuid,
+						},
+					}, PageInfo{NextCursor: "next", Total: &total}, nil)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantLink: `<http://localhost/test/test/list?page_size=2&status=open&total=true>; rel="first", ` +
+				`<http://localhost/test/test/list?cursor=next&page_size=2&status=open&total=true>; rel="next"`,
+			wantResp: PaginatedResponse{
+				Page:       3,
+				PageSize:   2,
+				NextCursor: "next",
+				Total:      func() *int64 { total := int64(3); return &total }(),
+				Data: model.ProcessListDTO{
+					{
+						Code: "test",
+						UUID: defaultUuid,
+					},
+				},
+			},
+		},
+		{
+			name: "success - cursor - page is omitted",
+			args: args{
+				code:     "test",
+				page:     "3",
+				pageSize: "5",
+				query:    "cursor=" + testCursor,
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("Find", mock.Anything, ProcessQuery{
+					Code:     args.code,
+					SortBy:   SortByCreatedAt,
+					Page:     3,
+					PageSize: 5,
+					Cursor:   testCursor,
+				}).
+					Return(model.ProcessListDTO{
+						{
+							Code: "test",
+							UUID: defaultUuid,
+						},
+					}, PageInfo{}, nil)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantResp: PaginatedResponse{
+				PageSize: 5,
+				Data: model.ProcessListDTO{
+					{
+						Code: "test",
+						UUID: defaultUuid,
+					},
+				},
+			},
+		},
+		{
+			name: "failed - 400 cursor issued for other filters",
+			args: args{
+				code:     "test",
+				page:     "1",
+				pageSize: "5",
+				query:    "status=open&cursor=" + testCursor,
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr: &model.ProcessErrorResponse{
+				Status:  "error",
+				Message: "invalid cursor: cursor was issued for other filters",
+			},
+		},
 		{
 			name: "success - default page and pageSize",
 			args: args{
@@ -266,13 +534,18 @@ func TestGetList(t *testing.T) {
 			},
 			mockFunc: func(args args) *ProcessController {
 				service := ProcessSrvcMock{}
-				service.On("Get", mock.Anything, args.code, "", DEFAULT_PAGE, DEFAULT_PAGE_SIZE).
+				service.On("Find", mock.Anything, ProcessQuery{
+					Code:     args.code,
+					SortBy:   SortByCreatedAt,
+					Page:     DEFAULT_PAGE,
+					PageSize: DEFAULT_PAGE_SIZE,
+				}).
 					Return(model.ProcessListDTO{
 						{
 							Code: "test",
 							UUID: defaultUuid,
 						},
-					}, nil)
+					}, PageInfo{}, nil)
 				return NewProcessController(&service)
 			},
 			wantCode: http.StatusOK,
@@ -296,7 +569,7 @@ func TestGetList(t *testing.T) {
 
 			testGroup := testApp.Group("/test/")
 			controller.SetupRouter(testGroup)
-			url := fmt.Sprintf("http://localhost/test/%s/list", tt.args.code)
+			url := fmt.Sprintf("http://localhost/test/%s/list?%s", tt.args.code, tt.args.query)
 			req := httptest.NewRequest("GET", url, nil)
 			req.Header.Add(HEADERNAME_PAGE, tt.args.page)
 			req.Header.Add(HEADERNAME_PAGE_SIZE, tt.args.pageSize)
@@ -324,6 +597,11 @@ func TestGetList(t *testing.T) {
 				assert.Equal(t, tt.wantResp.Page, gotResp.Page)
 				assert.Equal(t, tt.wantResp.PageSize, gotResp.PageSize)
 				assert.Equal(t, tt.wantResp.Data, gotResp.Data)
+				assert.Equal(t, tt.wantResp.NextCursor, gotResp.NextCursor)
+				assert.Equal(t, tt.wantResp.Total, gotResp.Total)
+				if len(tt.wantLink) > 0 {
+					assert.Equal(t, tt.wantLink, resp.Header.Get(fiber.HeaderLink))
+				}
 
 			}
 
@@ -342,6 +620,7 @@ func TestGet(t *testing.T) {
 		name     string
 		args     args
 		wantCode int
+		wantETag string
 		wantResp model.ProcessListDTO
 		wantErr  *model.ProcessErrorResponse
 		mockFunc func(args) *ProcessController
@@ -387,17 +666,20 @@ func TestGet(t *testing.T) {
 				service.On("Get", mock.Anything, args.code, args.uuid, DEFAULT_PAGE, DEFAULT_PAGE_SIZE).
 					Return(model.ProcessListDTO{
 						{
-							Code: "test",
-							UUID: defaultUuid,
+							Code:    "test",
+							UUID:    defaultUuid,
+							Version: 2,
 						},
 					}, nil)
 				return NewProcessController(&service)
 			},
 			wantCode: http.StatusOK,
+			wantETag: `"2"`,
 			wantResp: model.ProcessListDTO{
 				{
-					Code: "test",
-					UUID: defaultUuid,
+					Code:    "test",
+					UUID:    defaultUuid,
+					Version: 2,
 				},
 			},
 		},
@@ -434,6 +716,7 @@ func TestGet(t *testing.T) {
 				assert.Nil(t, err)
 
 				assert.Equal(t, tt.wantResp, gotResp)
+				assert.Equal(t, tt.wantETag, resp.Header.Get(HEADERNAME_ETAG))
 			}
 
 		})
@@ -447,6 +730,9 @@ func TestAssignStatus(t *testing.T) {
 		code       string
 		uuid       string
 		status     string
+		ifMatch    string
+		version    uint
+		dryRun     string
 		reqPayload model.ProcessStatusDTO
 	}
 	tests := []struct {
@@ -455,6 +741,7 @@ func TestAssignStatus(t *testing.T) {
 		wantCode           int
 		simulateBadRequest bool
 		wantResp           model.ProcessSubmitResponse
+		wantValidation     *model.ProcessValidationResponse
 		wantErr            *model.ProcessErrorResponse
 		mockFunc           func(args) *ProcessController
 	}{
@@ -477,7 +764,9 @@ func TestAssignStatus(t *testing.T) {
 					args.code,
 					args.uuid,
 					args.status,
-					&args.reqPayload).
+					&args.reqPayload,
+					args.version,
+					model.Actor{}).
 					Return(nil)
 				return NewProcessController(&service)
 			},
@@ -501,8 +790,9 @@ func TestAssignStatus(t *testing.T) {
 				service.On("AssignStatus", mock.Anything,
 					args.code,
 					args.uuid,
-					args.status,
-					args.reqPayload.Payload).
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
 					Return(validators.ErrUnknownStatus)
 				return NewProcessController(&service)
 			},
@@ -526,14 +816,41 @@ func TestAssignStatus(t *testing.T) {
 				service.On("AssignStatus", mock.Anything,
 					args.code,
 					args.uuid,
-					args.status,
-					args.reqPayload.Payload).
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
 					Return(validators.ErrNotAllowedStatus)
 				return NewProcessController(&service)
 			},
 			wantCode: http.StatusBadRequest,
 			wantErr:  &NotAllowedProcessStatusErrResp,
 		},
+		{
+			name: "fail - 400 - final status",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "open",
+				reqPayload: model.ProcessStatusDTO{
+					Payload: model.Payload{
+						"sample": "data",
+					},
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("AssignStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
+					Return(validators.ErrFinalStatus)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &ProcessInFinalStatusErrResp,
+		},
 		{
 			name: "fail - 404",
 			args: args{
@@ -551,8 +868,9 @@ func TestAssignStatus(t *testing.T) {
 				service.On("AssignStatus", mock.Anything,
 					args.code,
 					args.uuid,
-					args.status,
-					args.reqPayload.Payload).
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
 					Return(ErrProcessNotFound)
 				return NewProcessController(&service)
 			},
@@ -560,7 +878,7 @@ func TestAssignStatus(t *testing.T) {
 			wantErr:  &ProcessNotFoundErrResp,
 		},
 		{
-			name: "fail - 500",
+			name: "fail - 400 - reason is required",
 			args: args{
 				code:   "requests",
 				uuid:   defaultUuid,
@@ -576,16 +894,17 @@ func TestAssignStatus(t *testing.T) {
 				service.On("AssignStatus", mock.Anything,
 					args.code,
 					args.uuid,
-					args.status,
-					args.reqPayload.Payload).
-					Return(errors.New("OMG error"))
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
+					Return(validators.ErrReasonRequired)
 				return NewProcessController(&service)
 			},
-			wantCode: http.StatusInternalServerError,
-			wantErr:  &CannotMoveItIntoNewStatusErrResp,
+			wantCode: http.StatusBadRequest,
+			wantErr:  &ReasonRequiredErrResp,
 		},
 		{
-			name: "success",
+			name: "fail - 403",
 			args: args{
 				code:   "requests",
 				uuid:   defaultUuid,
@@ -601,58 +920,436 @@ func TestAssignStatus(t *testing.T) {
 				service.On("AssignStatus", mock.Anything,
 					args.code,
 					args.uuid,
-					args.status,
-					args.reqPayload.Payload).
-					Return(nil)
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
+					Return(validators.ErrForbiddenTransition)
 				return NewProcessController(&service)
 			},
-			wantCode: http.StatusNoContent,
+			wantCode: http.StatusForbidden,
+			wantErr:  &ForbiddenTransitionErrResp,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			var testApp = fiber.New()
-			controller := tt.mockFunc(tt.args)
-
-			testGroup := testApp.Group("/test/")
-			controller.SetupRouter(testGroup)
-			url := fmt.Sprintf("http://localhost/test/%s/%s/assign/%s", tt.args.code, tt.args.uuid, tt.args.status)
-
-			var data []byte
-			var err error
-			if tt.simulateBadRequest {
-				data = []byte("something bad")
-			} else {
-				data, err = json.Marshal(tt.args.reqPayload)
-				assert.Nil(t, err)
-			}
-
-			reqBody := bytes.NewBuffer(data)
-
-			req := httptest.NewRequest("PATCH", url, reqBody)
-
-			req.Header.Add("Content-Type", "application/json")
-
-			resp, err := testApp.Test(req)
-
-			assert.Nil(t, err)
-			assert.Equal(t, tt.wantCode, resp.StatusCode)
-
-			respBody, err := io.ReadAll(resp.Body)
-			assert.Nil(t, err)
-
-			if tt.wantErr != nil {
-				var gotResp model.ProcessErrorResponse
-				json.Unmarshal(respBody, &gotResp)
-				assert.Nil(t, err)
-
-				assert.Equal(t, *tt.wantErr, gotResp)
-
-			} else {
-				var gotResp model.ProcessSubmitResponse
-				json.Unmarshal(respBody, &gotResp)
-				assert.Nil(t, err)
+		{
+			name: "fail - 400 - guard is not satisfied",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "done",
+				reqPayload: model.ProcessStatusDTO{
+					Payload: model.Payload{
+						"sample": "data",
+					},
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("AssignStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
+					Return(&validators.GuardError{Status: "done", Guard: "payload.amount < 10000"})
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr: &model.ProcessErrorResponse{
+				Status:  "error",
+				Message: `transition guard is not satisfied: status "done" requires payload.amount < 10000`,
+			},
+		},
+		{
+			name: "fail - 400 - vetoed by hook",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "done",
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("AssignStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status},
+					args.version,
+					model.Actor{}).
+					Return(fmt.Errorf("%w: %w", ErrStatusChangeVetoed, errors.New("invoice is not paid")))
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr: &model.ProcessErrorResponse{
+				Status:  "error",
+				Message: "status change is vetoed: invoice is not paid",
+			},
+		},
+		{
+			name: "fail - 400 - payload validation",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "done",
+				reqPayload: model.ProcessStatusDTO{
+					Payload: model.Payload{
+						"data": map[string]interface{}{},
+					},
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("AssignStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
+					Return(&validators.PayloadValidationError{
+						Causes: []model.ValidationErrorDetail{
+							{Pointer: "/data", Keyword: "required", Message: "missing properties: 'user_name'"},
+						},
+					})
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr: &model.ProcessErrorResponse{
+				Status:  "error",
+				Message: "payload validation error: /data: missing properties: 'user_name'",
+				Details: []model.ValidationErrorDetail{
+					{Pointer: "/data", Keyword: "required", Message: "missing properties: 'user_name'"},
+				},
+			},
+		},
+		{
+			name: "fail - 400 - wrong If-Match value",
+			args: args{
+				code:    "requests",
+				uuid:    defaultUuid,
+				status:  "done",
+				ifMatch: `"abc"`,
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedValueForIfMatchHdrErrResp,
+		},
+		{
+			name: "fail - 409",
+			args: args{
+				code:    "requests",
+				uuid:    defaultUuid,
+				status:  "done",
+				ifMatch: `"3"`,
+				version: 3,
+				reqPayload: model.ProcessStatusDTO{
+					Payload: model.Payload{
+						"sample": "data",
+					},
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("AssignStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
+					Return(ErrVersionConflict)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusConflict,
+			wantErr:  &ProcessVersionConflictErrResp,
+		},
+		{
+			name: "fail - 500",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "done",
+				reqPayload: model.ProcessStatusDTO{
+					Payload: model.Payload{
+						"sample": "data",
+					},
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("AssignStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
+					Return(errors.New("OMG error"))
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantErr:  &CannotMoveItIntoNewStatusErrResp,
+		},
+		{
+			name: "success",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "done",
+				reqPayload: model.ProcessStatusDTO{
+					Payload: model.Payload{
+						"sample": "data",
+					},
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("AssignStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
+					Return(nil)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "success - reason",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "done",
+				reqPayload: model.ProcessStatusDTO{
+					Name:      "rejected",
+					Reason:    "customer asked to cancel",
+					CreatedBy: "someone else",
+					SourceIP:  "10.0.0.1",
+					RequestID: "fake",
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("AssignStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status, Reason: "customer asked to cancel"},
+					args.version,
+					model.Actor{}).
+					Return(nil)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "success - If-Match",
+			args: args{
+				code:    "requests",
+				uuid:    defaultUuid,
+				status:  "done",
+				ifMatch: `W/"4"`,
+				version: 4,
+				reqPayload: model.ProcessStatusDTO{
+					Payload: model.Payload{
+						"sample": "data",
+					},
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("AssignStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
+					Return(nil)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name: "fail - 400 - wrong dry_run value",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "done",
+				dryRun: "maybe",
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedValueForDryRunErrResp,
+		},
+		{
+			name: "success - dry run - not allowed status",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "done",
+				dryRun: "true",
+				reqPayload: model.ProcessStatusDTO{
+					Payload: model.Payload{
+						"sample": "data",
+					},
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("ValidateStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
+					Return(validators.ErrNotAllowedStatus)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantValidation: &model.ProcessValidationResponse{
+				Valid:   false,
+				Status:  "done",
+				Message: NotAllowedProcessStatusErrResp.Message,
+			},
+		},
+		{
+			name: "success - dry run - invalid payload",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "done",
+				dryRun: "true",
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("ValidateStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status},
+					args.version,
+					model.Actor{}).
+					Return(&validators.PayloadValidationError{
+						Causes: []model.ValidationErrorDetail{{Pointer: "/comment", Keyword: "required", Message: "missing property 'comment'"}},
+					})
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantValidation: &model.ProcessValidationResponse{
+				Valid:   false,
+				Status:  "done",
+				Message: "payload validation error: /comment: missing property 'comment'",
+				Details: []model.ValidationErrorDetail{{Pointer: "/comment", Keyword: "required", Message: "missing property 'comment'"}},
+			},
+		},
+		{
+			name: "fail - 404 - dry run - process not found",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "done",
+				dryRun: "true",
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("ValidateStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status},
+					args.version,
+					model.Actor{}).
+					Return(ErrProcessNotFound)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  &ProcessNotFoundErrResp,
+		},
+		{
+			name: "success - dry run",
+			args: args{
+				code:   "requests",
+				uuid:   defaultUuid,
+				status: "done",
+				dryRun: "true",
+				reqPayload: model.ProcessStatusDTO{
+					Payload: model.Payload{
+						"sample": "data",
+					},
+				},
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("ValidateStatus", mock.Anything,
+					args.code,
+					args.uuid,
+					model.ProcessStatusDTO{Name: args.status, Payload: args.reqPayload.Payload},
+					args.version,
+					model.Actor{}).
+					Return(nil)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantValidation: &model.ProcessValidationResponse{
+				Valid:  true,
+				Status: "done",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testApp = fiber.New()
+			controller := tt.mockFunc(tt.args)
+
+			testGroup := testApp.Group("/test/")
+			controller.SetupRouter(testGroup)
+			url := fmt.Sprintf("http://localhost/test/%s/%s/assign/%s", tt.args.code, tt.args.uuid, tt.args.status)
+			if len(tt.args.dryRun) > 0 {
+				url += "?dry_run=" + tt.args.dryRun
+			}
+
+			var data []byte
+			var err error
+			if tt.simulateBadRequest {
+				data = []byte("something bad")
+			} else {
+				data, err = json.Marshal(tt.args.reqPayload)
+				assert.Nil(t, err)
+			}
+
+			reqBody := bytes.NewBuffer(data)
+
+			req := httptest.NewRequest("PATCH", url, reqBody)
+
+			req.Header.Add("Content-Type", "application/json")
+			if len(tt.args.ifMatch) > 0 {
+				req.Header.Add(HEADERNAME_IF_MATCH, tt.args.ifMatch)
+			}
+
+			resp, err := testApp.Test(req)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			respBody, err := io.ReadAll(resp.Body)
+			assert.Nil(t, err)
+
+			if tt.wantErr != nil {
+				var gotResp model.ProcessErrorResponse
+				json.Unmarshal(respBody, &gotResp)
+				assert.Nil(t, err)
+
+				assert.Equal(t, *tt.wantErr, gotResp)
+
+			} else if tt.wantValidation != nil {
+				var gotResp model.ProcessValidationResponse
+				json.Unmarshal(respBody, &gotResp)
+				assert.Nil(t, err)
+
+				assert.Equal(t, *tt.wantValidation, gotResp)
+			} else {
+				var gotResp model.ProcessSubmitResponse
+				json.Unmarshal(respBody, &gotResp)
+				assert.Nil(t, err)
 
 				assert.Equal(t, tt.wantResp.Uuid, gotResp.Uuid)
 			}
@@ -661,6 +1358,125 @@ func TestAssignStatus(t *testing.T) {
 	}
 }
 
+func TestGetTransitions(t *testing.T) {
+	defaultUuid := uuid.NewString()
+	type args struct {
+		code string
+		uuid string
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantCode int
+		wantResp model.TransitionListDTO
+		wantErr  *model.ProcessErrorResponse
+		mockFunc func(args) *ProcessController
+	}{
+		{
+			name: "fail - 404",
+			args: args{
+				code: "requests",
+				uuid: defaultUuid,
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("Transitions", mock.Anything, args.code, args.uuid, model.Actor{}).
+					Return(nil, ErrProcessNotFound)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  &ProcessNotFoundErrResp,
+		},
+		{
+			name: "fail - 500",
+			args: args{
+				code: "requests",
+				uuid: defaultUuid,
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("Transitions", mock.Anything, args.code, args.uuid, model.Actor{}).
+					Return(nil, errors.New("OMG error"))
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantErr:  &CannotGetTransitionsErrResp,
+		},
+		{
+			name: "success",
+			args: args{
+				code: "requests",
+				uuid: defaultUuid,
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("Transitions", mock.Anything, args.code, args.uuid, model.Actor{}).
+					Return(model.TransitionListDTO{
+						{Name: "in_progress"},
+						{Name: "rejected", Final: true},
+					}, nil)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantResp: model.TransitionListDTO{
+				{Name: "in_progress"},
+				{Name: "rejected", Final: true},
+			},
+		},
+		{
+			name: "success - final status",
+			args: args{
+				code: "requests",
+				uuid: defaultUuid,
+			},
+			mockFunc: func(args args) *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("Transitions", mock.Anything, args.code, args.uuid, model.Actor{}).
+					Return(model.TransitionListDTO{}, nil)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantResp: model.TransitionListDTO{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testApp = fiber.New()
+			controller := tt.mockFunc(tt.args)
+
+			testGroup := testApp.Group("/test/")
+			controller.SetupRouter(testGroup)
+			url := fmt.Sprintf("http://localhost/test/%s/%s/transitions", tt.args.code, tt.args.uuid)
+			req := httptest.NewRequest("GET", url, nil)
+
+			resp, err := testApp.Test(req)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.Nil(t, err)
+
+			if tt.wantErr != nil {
+				var gotResp model.ProcessErrorResponse
+				json.Unmarshal(body, &gotResp)
+				assert.Nil(t, err)
+
+				assert.Equal(t, *tt.wantErr, gotResp)
+
+			} else {
+				var gotResp model.TransitionListDTO
+				json.Unmarshal(body, &gotResp)
+				assert.Nil(t, err)
+
+				assert.Equal(t, tt.wantResp, gotResp)
+			}
+
+		})
+	}
+}
+
 func Test_getHeaderValue(t *testing.T) {
 
 	type args struct {
@@ -758,3 +1574,35 @@ func Test_getHeaderValue(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseETag(t *testing.T) {
+	tests := []struct {
+		name        string
+		val         string
+		wantVersion uint
+		wantErr     bool
+	}{
+		{name: "empty", val: "", wantVersion: 0},
+		{name: "any", val: "*", wantVersion: 0},
+		{name: "strong", val: `"12"`, wantVersion: 12},
+		{name: "weak", val: `W/"7"`, wantVersion: 7},
+		{name: "not quoted", val: "5", wantVersion: 5},
+		{name: "zero", val: `"0"`, wantErr: true},
+		{name: "not a number", val: `"abc"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotVersion, gotErr := parseETag(tt.val)
+			if tt.wantErr {
+				assert.NotNil(t, gotErr)
+			} else {
+				assert.Nil(t, gotErr)
+				assert.Equal(t, tt.wantVersion, gotVersion)
+				if gotVersion > 0 {
+					assert.Equal(t, fmt.Sprintf(`"%d"`, gotVersion), formatETag(gotVersion))
+				}
+			}
+		})
+	}
+}
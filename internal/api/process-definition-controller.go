@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	log "github.com/gofiber/fiber/v2/log"
+)
+
+type (
+	ProcessDefinitionController struct {
+		definitions model.ProcessDefinitionListDTO
+	}
+)
+
+var (
+	ProcessDefinitionNotFoundErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "process definition not found",
+	}
+)
+
+func NewProcessDefinitionController(conf config.ProcessConfigList) *ProcessDefinitionController {
+	definitions := model.ProcessDefinitionListDTO{}
+	for _, pc := range conf {
+		definitions = append(definitions, toProcessDefinitionDTO(pc))
+	}
+
+	return &ProcessDefinitionController{
+		definitions: definitions,
+	}
+}
+
+func (pdc *ProcessDefinitionController) SetupRouter(router fiber.Router) {
+	router.Get("/", pdc.GetList)
+	router.Get("/:code", pdc.Get)
+}
+
+// @Summary Get list of process definitions
+// @Description Get processes the engine runs with: statuses, allowed transitions and JSON Schemas
+// @Tags process-definitions
+// @Produce json
+// @Success 200 {object} model.ProcessDefinitionListDTO
+// @Router /api/v1/process-definitions [get]
+func (pdc *ProcessDefinitionController) GetList(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(pdc.definitions)
+}
+
+// @Summary Get process definition
+// @Description Get process definition by code
+// @Tags process-definitions
+// @Param	code	path	string	true	"Code of Process"
+// @Produce json
+// @Success	200 {object} model.ProcessDefinitionDTO
+// @Failed	404 {object} model.ProcessErrorResponse
+// @Router /api/v1/process-definitions/{code} [get]
+func (pdc *ProcessDefinitionController) Get(c *fiber.Ctx) error {
+	code := c.Params("code")
+	for _, d := range pdc.definitions {
+		if d.Name == code {
+			return c.Status(fiber.StatusOK).JSON(d)
+		}
+	}
+
+	log.Info("process definition not found: ", code)
+	return c.Status(fiber.StatusNotFound).JSON(ProcessDefinitionNotFoundErrResp)
+}
+
+func toProcessDefinitionDTO(pc config.ProcessConfig) model.ProcessDefinitionDTO {
+	statuses := []model.StatusDefinitionDTO{}
+	for _, sc := range pc.Statuses {
+		next := []model.NextDefinitionDTO{}
+		for _, n := range sc.Next {
+			next = append(next, model.NextDefinitionDTO{Name: n.Name, When: n.When, Roles: n.Roles, RequireReason: n.RequireReason})
+		}
+		statuses = append(statuses, model.StatusDefinitionDTO{
+			Name:          sc.Name,
+			Initial:       sc.Initial,
+			Final:         sc.Final,
+			Next:          next,
+			Schema:        toRawSchema(sc.Schema),
+			Roles:         sc.Roles,
+			RequireReason: sc.RequireReason,
+		})
+	}
+
+	return model.ProcessDefinitionDTO{
+		Name:     pc.Name,
+		Schema:   toRawSchema(pc.Schema),
+		Statuses: statuses,
+	}
+}
+
+// toRawSchema - returns the schema as JSON object to be embedded into the response
+func toRawSchema(schema string) json.RawMessage {
+	if len(schema) == 0 || !json.Valid([]byte(schema)) {
+		return nil
+	}
+	return json.RawMessage(schema)
+}
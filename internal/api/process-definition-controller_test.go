@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+var testProcessConfig = config.ProcessConfigList{{
+	Name:   "requests",
+	Schema: `{"type": "object"}`,
+	Statuses: []config.StatusConfig{
+		{Name: "open", Initial: true, Next: config.NextList{{Name: "done", When: "payload.amount < 10000", Roles: []string{"clerk"}}}},
+		{Name: "done", Final: true, Roles: []string{"manager"}, Schema: `{"type": "object", "required": ["comment"]}`},
+	},
+}}
+
+var testProcessDefinition = model.ProcessDefinitionDTO{
+	Name:   "requests",
+	Schema: json.RawMessage(`{"type":"object"}`),
+	Statuses: []model.StatusDefinitionDTO{
+		{Name: "open", Initial: true, Next: []model.NextDefinitionDTO{{Name: "done", When: "payload.amount < 10000", Roles: []string{"clerk"}}}},
+		{Name: "done", Final: true, Roles: []string{"manager"}, Next: []model.NextDefinitionDTO{}, Schema: json.RawMessage(`{"type":"object","required":["comment"]}`)},
+	},
+}
+
+func TestGetProcessDefinitionList(t *testing.T) {
+	var testApp = fiber.New()
+	controller := NewProcessDefinitionController(testProcessConfig)
+	controller.SetupRouter(testApp.Group("/test"))
+
+	req := httptest.NewRequest("GET", "http://localhost/test", nil)
+	resp, err := testApp.Test(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.Nil(t, err)
+
+	var gotResp model.ProcessDefinitionListDTO
+	assert.Nil(t, json.Unmarshal(body, &gotResp))
+	assert.Equal(t, model.ProcessDefinitionListDTO{testProcessDefinition}, gotResp)
+}
+
+func TestGetProcessDefinition(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		wantCode int
+		wantResp model.ProcessDefinitionDTO
+		wantErr  *model.ProcessErrorResponse
+	}{
+		{
+			name:     "success",
+			code:     "requests",
+			wantCode: http.StatusOK,
+			wantResp: testProcessDefinition,
+		},
+		{
+			name:     "failed - 404",
+			code:     "orders",
+			wantCode: http.StatusNotFound,
+			wantErr:  &ProcessDefinitionNotFoundErrResp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testApp = fiber.New()
+			controller := NewProcessDefinitionController(testProcessConfig)
+			controller.SetupRouter(testApp.Group("/test"))
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("http://localhost/test/%s", tt.code), nil)
+			resp, err := testApp.Test(req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.Nil(t, err)
+
+			if tt.wantErr != nil {
+				var gotResp model.ProcessErrorResponse
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, *tt.wantErr, gotResp)
+			} else {
+				var gotResp model.ProcessDefinitionDTO
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, tt.wantResp, gotResp)
+			}
+		})
+	}
+}
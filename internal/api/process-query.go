@@ -0,0 +1,427 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	QUERYPARAM_STATUS       = "status"
+	QUERYPARAM_CREATED_FROM = "created_from"
+	QUERYPARAM_CREATED_TO   = "created_to"
+	QUERYPARAM_CHANGED_FROM = "changed_from"
+	QUERYPARAM_CHANGED_TO   = "changed_to"
+	QUERYPARAM_SORT         = "sort"
+	QUERYPARAM_PAYLOAD      = "payload."
+	QUERYPARAM_PAGE         = "page"
+	QUERYPARAM_PAGE_SIZE    = "page_size"
+	QUERYPARAM_CURSOR       = "cursor"
+	QUERYPARAM_TOTAL        = "total"
+)
+
+const (
+	OperatorEq       FilterOperator = "eq"
+	OperatorNe       FilterOperator = "ne"
+	OperatorGt       FilterOperator = "gt"
+	OperatorGte      FilterOperator = "gte"
+	OperatorLt       FilterOperator = "lt"
+	OperatorLte      FilterOperator = "lte"
+	OperatorContains FilterOperator = "contains"
+
+	SortByCreatedAt SortField = "created_at"
+	SortByChangedAt SortField = "changed_at"
+)
+
+type (
+	FilterOperator string
+	SortField      string
+
+	// PayloadFilter - condition on the field of the process payload, e.g. `payload.amount[gte]=100`
+	PayloadFilter struct {
+		Field    string
+		Operator FilterOperator
+		Value    string
+	}
+
+	// ProcessQuery - filters, sorting and paging of the processes list
+	ProcessQuery struct {
+		Code        string
+		Statuses    []string
+		CreatedFrom *time.Time
+		CreatedTo   *time.Time
+		ChangedFrom *time.Time
+		ChangedTo   *time.Time
+		Payload     []PayloadFilter
+		SortBy      SortField
+		SortDesc    bool
+		Page        int
+		PageSize    int
+		// Cursor - position after the last process of the previous page, takes precedence over Page
+		Cursor    string
+		WithTotal bool
+	}
+
+	// PageInfo - position of the returned page in the whole result
+	PageInfo struct {
+		NextCursor string
+		Total      *int64
+	}
+
+	// payloadField - SQL expressions of the payload field: the value as text with unquoted strings
+	// and the value as number, NULL if the field is not a number
+	payloadField struct {
+		text   clause.Expr
+		number clause.Expr
+	}
+
+	// cursor - keyset of the last returned process, encoded into opaque string.
+	// Filters - digest of the filters it was issued for
+	cursor struct {
+		SortBy   SortField `json:"s"`
+		SortDesc bool      `json:"d,omitempty"`
+		Filters  string    `json:"f"`
+		Time     time.Time `json:"t"`
+		ID       uint      `json:"i"`
+	}
+)
+
+var (
+	ErrInvalidQueryParam = errors.New("invalid query parameter")
+	ErrInvalidCursor     = errors.New("invalid cursor")
+
+	payloadFieldRe = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+	payloadParamRe = regexp.MustCompile(`^payload\.([^\[\]]+)(\[([a-z]+)\])?$`)
+)
+
+// NewProcessQuery - builds the query from URL query parameters
+func NewProcessQuery(code string, values url.Values) (ProcessQuery, error) {
+	query := ProcessQuery{
+		Code:   code,
+		SortBy: SortByCreatedAt,
+	}
+
+	// keep the order of conditions stable
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var err error
+	for _, key := range keys {
+		vals := values[key]
+		switch {
+		case key == QUERYPARAM_STATUS:
+			for _, v := range vals {
+				for _, status := range strings.Split(v, ",") {
+					if status = strings.TrimSpace(status); len(status) > 0 {
+						query.Statuses = append(query.Statuses, status)
+					}
+				}
+			}
+		case key == QUERYPARAM_CREATED_FROM:
+			query.CreatedFrom, err = parseTimeParam(key, vals)
+		case key == QUERYPARAM_CREATED_TO:
+			query.CreatedTo, err = parseTimeParam(key, vals)
+		case key == QUERYPARAM_CHANGED_FROM:
+			query.ChangedFrom, err = parseTimeParam(key, vals)
+		case key == QUERYPARAM_CHANGED_TO:
+			query.ChangedTo, err = parseTimeParam(key, vals)
+		case key == QUERYPARAM_SORT:
+			query.SortBy, query.SortDesc, err = parseSortParam(vals[len(vals)-1])
+		case key == QUERYPARAM_PAGE:
+			query.Page, err = parseIntParam(key, vals)
+		case key == QUERYPARAM_PAGE_SIZE:
+			query.PageSize, err = parseIntParam(key, vals)
+		case key == QUERYPARAM_CURSOR:
+			query.Cursor = vals[len(vals)-1]
+		case key == QUERYPARAM_TOTAL:
+			query.WithTotal, err = strconv.ParseBool(vals[len(vals)-1])
+			if err != nil {
+				err = fmt.Errorf("%w: %s must be boolean", ErrInvalidQueryParam, key)
+			}
+		case strings.HasPrefix(key, QUERYPARAM_PAYLOAD):
+			var filters []PayloadFilter
+			filters, err = parsePayloadParam(key, vals)
+			query.Payload = append(query.Payload, filters...)
+		}
+		if err != nil {
+			return query, err
+		}
+	}
+
+	// cursor is bound to the sort order and the filters it was issued for
+	if len(query.Cursor) > 0 {
+		c, err := decodeCursor(query.Cursor)
+		if err != nil {
+			return query, err
+		}
+		if c.SortBy != query.SortBy || c.SortDesc != query.SortDesc {
+			return query, fmt.Errorf("%w: cursor was issued for another sort order", ErrInvalidCursor)
+		}
+		if c.Filters != query.filtersDigest() {
+			return query, fmt.Errorf("%w: cursor was issued for other filters", ErrInvalidCursor)
+		}
+	}
+
+	return query, nil
+}
+
+func parseIntParam(key string, vals []string) (int, error) {
+	val, err := strconv.Atoi(vals[len(vals)-1])
+	if err != nil || val < 0 {
+		return 0, fmt.Errorf("%w: %s must be positive integer", ErrInvalidQueryParam, key)
+	}
+	return val, nil
+}
+
+func parseTimeParam(key string, vals []string) (*time.Time, error) {
+	t, err := time.Parse(time.RFC3339, vals[len(vals)-1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be RFC 3339 date-time", ErrInvalidQueryParam, key)
+	}
+	return &t, nil
+}
+
+func parseSortParam(val string) (SortField, bool, error) {
+	desc := strings.HasPrefix(val, "-")
+	field := SortField(strings.TrimPrefix(val, "-"))
+	switch field {
+	case SortByCreatedAt, SortByChangedAt:
+		return field, desc, nil
+	}
+	return "", false, fmt.Errorf("%w: cannot sort by %q", ErrInvalidQueryParam, field)
+}
+
+func parsePayloadParam(key string, vals []string) ([]PayloadFilter, error) {
+	matches := payloadParamRe.FindStringSubmatch(key)
+	if matches == nil || !payloadFieldRe.MatchString(matches[1]) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidQueryParam, key)
+	}
+
+	operator := OperatorEq
+	if len(matches[3]) > 0 {
+		operator = FilterOperator(matches[3])
+	}
+	switch operator {
+	case OperatorEq, OperatorNe, OperatorGt, OperatorGte, OperatorLt, OperatorLte, OperatorContains:
+	default:
+		return nil, fmt.Errorf("%w: not supported operator %q", ErrInvalidQueryParam, operator)
+	}
+
+	var filters []PayloadFilter
+	for _, v := range vals {
+		filters = append(filters, PayloadFilter{
+			Field:    matches[1],
+			Operator: operator,
+			Value:    v,
+		})
+	}
+	return filters, nil
+}
+
+// filter - adds query conditions to the statement
+func (q ProcessQuery) filter(db *gorm.DB) *gorm.DB {
+	db = db.Where("code = ?", q.Code)
+
+	if len(q.Statuses) > 0 {
+		db = db.Where("current_status IN ?", q.Statuses)
+	}
+	// timestamps are stored in the local time zone
+	if q.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", q.CreatedFrom.Local())
+	}
+	if q.CreatedTo != nil {
+		db = db.Where("created_at < ?", q.CreatedTo.Local())
+	}
+	if q.ChangedFrom != nil {
+		db = db.Where("updated_at >= ?", q.ChangedFrom.Local())
+	}
+	if q.ChangedTo != nil {
+		db = db.Where("updated_at < ?", q.ChangedTo.Local())
+	}
+	for _, f := range q.Payload {
+		db = f.apply(db)
+	}
+	return db
+}
+
+// page - adds sorting and the page bounds to the statement, one extra row is requested
+// to find out whether the next page exists
+func (q ProcessQuery) page(db *gorm.DB) (*gorm.DB, error) {
+	order, comparison := "ASC", ">"
+	if q.SortDesc {
+		order, comparison = "DESC", "<"
+	}
+	column := q.SortBy.column()
+
+	if len(q.Cursor) > 0 {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(
+			fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", column, comparison, column, comparison),
+			c.Time, c.Time, c.ID,
+		)
+	} else if q.Page > 1 {
+		db = db.Offset((q.Page - 1) * q.PageSize)
+	}
+
+	return db.
+		Order(fmt.Sprintf("%s %s, id %s", column, order, order)).
+		Limit(q.PageSize + 1), nil
+}
+
+// nextCursor - returns cursor pointing after the given process
+func (q ProcessQuery) nextCursor(last model.Process) string {
+	c := cursor{
+		SortBy:   q.SortBy,
+		SortDesc: q.SortDesc,
+		Filters:  q.filtersDigest(),
+		Time:     last.CreatedAt,
+		ID:       last.ID,
+	}
+	if q.SortBy == SortByChangedAt {
+		c.Time = last.UpdatedAt
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// filtersDigest - hash of the filters independent of their order and of the time zones of the time ranges
+func (q ProcessQuery) filtersDigest() string {
+	statuses := append([]string{}, q.Statuses...)
+	sort.Strings(statuses)
+
+	times := make([]string, 0, 4)
+	for _, t := range []*time.Time{q.CreatedFrom, q.CreatedTo, q.ChangedFrom, q.ChangedTo} {
+		var val string
+		if t != nil {
+			val = t.UTC().Format(time.RFC3339Nano)
+		}
+		times = append(times, val)
+	}
+
+	payload := make([]string, 0, len(q.Payload))
+	for _, f := range q.Payload {
+		payload = append(payload, fmt.Sprintf("%s[%s]=%s", f.Field, f.Operator, f.Value))
+	}
+	sort.Strings(payload)
+
+	data, _ := json.Marshal([]interface{}{q.Code, statuses, times, payload})
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
+
+func decodeCursor(val string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(val)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+func (sf SortField) column() string {
+	if sf == SortByChangedAt {
+		return "updated_at"
+	}
+	return "created_at"
+}
+
+// apply - adds the condition on the payload field, numbers are compared as numbers when the value is a number
+// and as text otherwise
+func (f PayloadFilter) apply(db *gorm.DB) *gorm.DB {
+	field := newPayloadField(db.Dialector.Name(), f.Field)
+	num, numErr := strconv.ParseFloat(f.Value, 64)
+
+	switch f.Operator {
+	case OperatorEq:
+		if numErr == nil {
+			return db.Where("(? = ? OR ? = ?)", field.text, f.Value, field.number, num)
+		}
+		return db.Where("? = ?", field.text, f.Value)
+	case OperatorNe:
+		if numErr == nil {
+			return db.Where("? <> ? AND (? IS NULL OR ? <> ?)", field.text, f.Value, field.number, field.number, num)
+		}
+		return db.Where("? <> ?", field.text, f.Value)
+	case OperatorContains:
+		return db.Where("LOWER(?) LIKE LOWER(?) ESCAPE '!'", field.text, "%"+escapeLike(f.Value)+"%")
+	}
+
+	comparison := map[FilterOperator]string{
+		OperatorGt:  ">",
+		OperatorGte: ">=",
+		OperatorLt:  "<",
+		OperatorLte: "<=",
+	}[f.Operator]
+	if numErr == nil {
+		return db.Where(fmt.Sprintf("? %s ?", comparison), field.number, num)
+	}
+	return db.Where(fmt.Sprintf("? %s ?", comparison), field.text, f.Value)
+}
+
+// newPayloadField - builds the expressions with JSON functions of the dialect, sqlite ones by default
+func newPayloadField(dialect, field string) payloadField {
+	keys := strings.Split(field, ".")
+	// keys are checked by payloadFieldRe, so they need no escaping within the quotes
+	path := `$."` + strings.Join(keys, `"."`) + `"`
+
+	switch dialect {
+	case "postgres":
+		args := make([]interface{}, 0, len(keys))
+		for _, key := range keys {
+			args = append(args, key)
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(keys)), ", ")
+		text := clause.Expr{SQL: "json_extract_path_text(payload::json, " + placeholders + ")", Vars: args}
+		return payloadField{
+			text: text,
+			number: clause.Expr{
+				SQL:  "CASE WHEN json_typeof(json_extract_path(payload::json, " + placeholders + ")) = 'number' THEN CAST(? AS numeric) END",
+				Vars: append(append([]interface{}{}, args...), text),
+			},
+		}
+	case "mysql":
+		return payloadField{
+			text: clause.Expr{SQL: "JSON_UNQUOTE(JSON_EXTRACT(payload, ?))", Vars: []interface{}{path}},
+			number: clause.Expr{
+				SQL:  "CASE WHEN JSON_TYPE(JSON_EXTRACT(payload, ?)) IN ('INTEGER', 'UNSIGNED INTEGER', 'DOUBLE', 'DECIMAL') THEN JSON_EXTRACT(payload, ?) + 0 END",
+				Vars: []interface{}{path, path},
+			},
+		}
+	}
+	return payloadField{
+		text: clause.Expr{
+			SQL:  "CASE json_type(payload, ?) WHEN 'true' THEN 'true' WHEN 'false' THEN 'false' ELSE CAST(json_extract(payload, ?) AS TEXT) END",
+			Vars: []interface{}{path, path},
+		},
+		number: clause.Expr{
+			SQL:  "CASE WHEN json_type(payload, ?) IN ('integer', 'real') THEN json_extract(payload, ?) END",
+			Vars: []interface{}{path, path},
+		},
+	}
+}
+
+func escapeLike(val string) string {
+	return strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`).Replace(val)
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestNewProcessQuery(t *testing.T) {
+	createdFrom := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	changedTo := time.Date(2023, 12, 31, 12, 30, 0, 0, time.FixedZone("", -6*60*60))
+	tests := []struct {
+		name      string
+		query     string
+		wantQuery ProcessQuery
+		wantErr   error
+	}{
+		{
+			name:  "success - defaults",
+			query: "",
+			wantQuery: ProcessQuery{
+				Code:   "requests",
+				SortBy: SortByCreatedAt,
+			},
+		},
+		{
+			name:  "success - statuses",
+			query: "status=open,in_progress&status=done",
+			wantQuery: ProcessQuery{
+				Code:     "requests",
+				Statuses: []string{"open", "in_progress", "done"},
+				SortBy:   SortByCreatedAt,
+			},
+		},
+		{
+			name:  "success - time ranges and sorting",
+			query: "created_from=2023-12-01T00:00:00Z&changed_to=2023-12-31T12:30:00-06:00&sort=-created_at",
+			wantQuery: ProcessQuery{
+				Code:        "requests",
+				CreatedFrom: &createdFrom,
+				ChangedTo:   &changedTo,
+				SortBy:      SortByCreatedAt,
+				SortDesc:    true,
+			},
+		},
+		{
+			name:  "success - payload",
+			query: "payload.customer.id=42&payload.amount[gte]=100&payload.amount[lt]=500&payload.name[contains]=ale",
+			wantQuery: ProcessQuery{
+				Code: "requests",
+				Payload: []PayloadFilter{
+					{Field: "amount", Operator: OperatorGte, Value: "100"},
+					{Field: "amount", Operator: OperatorLt, Value: "500"},
+					{Field: "customer.id", Operator: OperatorEq, Value: "42"},
+					{Field: "name", Operator: OperatorContains, Value: "ale"},
+				},
+				SortBy: SortByCreatedAt,
+			},
+		},
+		{
+			name:  "success - paging",
+			query: "page=2&page_size=20&total=true",
+			wantQuery: ProcessQuery{
+				Code:      "requests",
+				SortBy:    SortByCreatedAt,
+				Page:      2,
+				PageSize:  20,
+				WithTotal: true,
+			},
+		},
+		{
+			name:  "success - cursor",
+			query: "sort=-changed_at&cursor=" + ProcessQuery{Code: "requests", SortBy: SortByChangedAt, SortDesc: true}.nextCursor(model.Process{Model: gorm.Model{ID: 7}}),
+			wantQuery: ProcessQuery{
+				Code:     "requests",
+				SortBy:   SortByChangedAt,
+				SortDesc: true,
+			},
+		},
+		{
+			name: "success - cursor - same filters in another order and time zone",
+			query: "status=done,open&created_from=2023-12-01T03:00:00%2B03:00&cursor=" + ProcessQuery{
+				Code:        "requests",
+				Statuses:    []string{"open", "done"},
+				CreatedFrom: &createdFrom,
+				SortBy:      SortByCreatedAt,
+			}.nextCursor(model.Process{Model: gorm.Model{ID: 7}}),
+			wantQuery: ProcessQuery{
+				Code:        "requests",
+				Statuses:    []string{"done", "open"},
+				CreatedFrom: &createdFrom,
+				SortBy:      SortByCreatedAt,
+			},
+		},
+		{
+			name:    "failed - cursor issued for another sort order",
+			query:   "cursor=" + ProcessQuery{Code: "requests", SortBy: SortByChangedAt}.nextCursor(model.Process{Model: gorm.Model{ID: 7}}),
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "failed - cursor issued for another process",
+			query:   "cursor=" + ProcessQuery{Code: "orders", SortBy: SortByCreatedAt}.nextCursor(model.Process{Model: gorm.Model{ID: 7}}),
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "failed - cursor issued for other statuses",
+			query:   "status=open&cursor=" + ProcessQuery{Code: "requests", SortBy: SortByCreatedAt}.nextCursor(model.Process{Model: gorm.Model{ID: 7}}),
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name: "failed - cursor issued for another time range",
+			query: "created_from=2023-12-02T00:00:00Z&cursor=" + ProcessQuery{
+				Code:        "requests",
+				CreatedFrom: &createdFrom,
+				SortBy:      SortByCreatedAt,
+			}.nextCursor(model.Process{Model: gorm.Model{ID: 7}}),
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name: "failed - cursor issued for other payload filters",
+			query: "payload.amount[gte]=200&cursor=" + ProcessQuery{
+				Code:    "requests",
+				Payload: []PayloadFilter{{Field: "amount", Operator: OperatorGte, Value: "100"}},
+				SortBy:  SortByCreatedAt,
+			}.nextCursor(model.Process{Model: gorm.Model{ID: 7}}),
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "failed - broken cursor",
+			query:   "cursor=abc",
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "failed - wrong page size",
+			query:   "page_size=-1",
+			wantErr: ErrInvalidQueryParam,
+		},
+		{
+			name:    "failed - wrong date",
+			query:   "created_from=yesterday",
+			wantErr: ErrInvalidQueryParam,
+		},
+		{
+			name:    "failed - wrong sort field",
+			query:   "sort=uuid",
+			wantErr: ErrInvalidQueryParam,
+		},
+		{
+			name:    "failed - wrong payload field",
+			query:   "payload.a'b=1",
+			wantErr: ErrInvalidQueryParam,
+		},
+		{
+			name:    "failed - wrong operator",
+			query:   "payload.amount[like]=1",
+			wantErr: ErrInvalidQueryParam,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			assert.Nil(t, err)
+
+			gotQuery, gotErr := NewProcessQuery("requests", values)
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(gotErr, tt.wantErr))
+			} else {
+				assert.Nil(t, gotErr)
+				assert.Equal(t, tt.wantQuery.Code, gotQuery.Code)
+				assert.Equal(t, tt.wantQuery.Statuses, gotQuery.Statuses)
+				assert.Equal(t, tt.wantQuery.Payload, gotQuery.Payload)
+				assert.Equal(t, tt.wantQuery.SortBy, gotQuery.SortBy)
+				assert.Equal(t, tt.wantQuery.SortDesc, gotQuery.SortDesc)
+				assert.Equal(t, tt.wantQuery.Page, gotQuery.Page)
+				assert.Equal(t, tt.wantQuery.PageSize, gotQuery.PageSize)
+				assert.Equal(t, tt.wantQuery.WithTotal, gotQuery.WithTotal)
+				assertTimeEqual(t, tt.wantQuery.CreatedFrom, gotQuery.CreatedFrom)
+				assertTimeEqual(t, tt.wantQuery.ChangedTo, gotQuery.ChangedTo)
+			}
+		})
+	}
+}
+
+func assertTimeEqual(t *testing.T, want, got *time.Time) {
+	if want == nil {
+		assert.Nil(t, got)
+		return
+	}
+	assert.NotNil(t, got)
+	assert.True(t, want.Equal(*got))
+}
+
+func TestPayloadFilter_apply(t *testing.T) {
+	tests := []struct {
+		name      string
+		dialector gorm.Dialector
+		filter    PayloadFilter
+		wantSQL   string
+		wantVars  []interface{}
+	}{
+		{
+			name:      "sqlite number",
+			dialector: sqlite.Open("file::memory:"),
+			filter:    PayloadFilter{Field: "amount", Operator: OperatorGte, Value: "100"},
+			wantSQL:   "CASE WHEN json_type(payload, ?) IN ('integer', 'real') THEN json_extract(payload, ?) END >= ?",
+			wantVars:  []interface{}{`$."amount"`, `$."amount"`, float64(100)},
+		},
+		{
+			name:      "postgres nested text",
+			dialector: postgres.New(postgres.Config{DSN: "host=localhost"}),
+			filter:    PayloadFilter{Field: "customer.name", Operator: OperatorEq, Value: "alex"},
+			wantSQL:   "json_extract_path_text(payload::json, $2, $3) = $4",
+			wantVars:  []interface{}{"customer", "name", "alex"},
+		},
+		{
+			name:      "mysql contains",
+			dialector: mysql.New(mysql.Config{DSN: "user:pass@tcp(localhost:3306)/db", SkipInitializeWithVersion: true}),
+			filter:    PayloadFilter{Field: "name", Operator: OperatorContains, Value: "50%_off"},
+			wantSQL:   "LOWER(JSON_UNQUOTE(JSON_EXTRACT(payload, ?))) LIKE LOWER(?) ESCAPE '!'",
+			wantVars:  []interface{}{`$."name"`, "%50!%!_off%"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := gorm.Open(tt.dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+			assert.NoError(t, err)
+
+			stmt := tt.filter.apply(db.Model(&model.Process{}).Where("code = ?", "requests")).Find(&[]model.Process{}).Statement
+
+			assert.Contains(t, stmt.SQL.String(), tt.wantSQL)
+			assert.Equal(t, append([]interface{}{"requests"}, tt.wantVars...), stmt.Vars)
+		})
+	}
+}
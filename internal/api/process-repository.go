@@ -2,11 +2,11 @@ package api
 
 import (
 	"context"
+	"errors"
 
 	"github.com/alex-bezverkhniy/bp-engine/internal/model"
 
 	"github.com/google/uuid"
-	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -20,13 +20,30 @@ type (
 		Create(ctx context.Context, process *model.Process) (string, error)
 		GetByUUID(ctx context.Context, code string, uuid string) (*model.Process, error)
 		GetByCode(ctx context.Context, code string, page int, pageSize int) ([]model.Process, error)
-		SetStatus(ctx context.Context, code string, uuid string, status string, metadata datatypes.JSON) error
+		Find(ctx context.Context, query ProcessQuery) ([]model.Process, PageInfo, error)
+		SetStatus(ctx context.Context, process *model.Process, status *model.ProcessStatus) error
+		AddWebhookEvents(ctx context.Context, events []model.WebhookEvent) error
+		ResetTimers(ctx context.Context, processID uint, timer *model.ProcessTimer) error
+		Changes(ctx context.Context, query ChangeQuery) ([]model.ProcessChange, error)
+		LatestChangeID(ctx context.Context) (uint, error)
+		Transaction(ctx context.Context, fn func(repo ProcessRepository) error) error
 	}
 	ProcessRepo struct {
 		db *gorm.DB
 	}
+
+	// ChangeQuery - changes after the given position in order they were made, empty UUID or Code match any
+	ChangeQuery struct {
+		Code  string
+		UUID  string
+		After uint
+		Limit int
+	}
 )
 
+// ErrChangeSeqNotFound - counter row of the stream of changes is missing, it is added by the migrations
+var ErrChangeSeqNotFound = errors.New("counter of the process changes not found")
+
 func NewProcessRepository(db *gorm.DB) ProcessRepository {
 	return &ProcessRepo{
 		db: db,
@@ -38,7 +55,19 @@ func (r *ProcessRepo) Create(ctx context.Context, process *model.Process) (strin
 	if len(process.UUID) == 0 {
 		process.UUID = uuid.NewString()
 	}
-	err := r.db.WithContext(ctx).Create(process).Error
+	if process.Version == 0 {
+		process.Version = 1
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		seq, err := nextChangeSeq(tx, len(process.Statuses))
+		if err != nil {
+			return err
+		}
+		for i := range process.Statuses {
+			process.Statuses[i].Seq = seq + uint(i)
+		}
+		return tx.Create(process).Error
+	})
 	if err != nil {
 		return "", err
 	}
@@ -50,11 +79,8 @@ func (r *ProcessRepo) GetByUUID(ctx context.Context, code string, uuid string) (
 	var process model.Process
 	err := r.db.WithContext(ctx).
 		Model(&model.Process{}).
-		Preload("CurrentStatus", func(db *gorm.DB) *gorm.DB {
-			return db.Order("created_at ASC")
-		}).
 		Preload("Statuses", func(db *gorm.DB) *gorm.DB {
-			return db.Order("created_at DESC")
+			return db.Order("created_at DESC, id DESC")
 		}).
 		Where("code = ? AND uuid = ?", code, uuid).
 		First(&process).Error
@@ -63,48 +89,168 @@ func (r *ProcessRepo) GetByUUID(ctx context.Context, code string, uuid string) (
 }
 
 func (r *ProcessRepo) GetByCode(ctx context.Context, code string, page int, pageSize int) ([]model.Process, error) {
-	offset := (page - 1) * pageSize
+	processes, _, err := r.Find(ctx, ProcessQuery{
+		Code:     code,
+		SortBy:   SortByCreatedAt,
+		Page:     page,
+		PageSize: pageSize,
+	})
+	return processes, err
+}
+
+// Find - returns page of processes matched the query, the statuses history is not loaded
+func (r *ProcessRepo) Find(ctx context.Context, query ProcessQuery) ([]model.Process, PageInfo, error) {
+	var pageInfo PageInfo
+
+	if query.WithTotal {
+		var total int64
+		err := query.filter(r.db.WithContext(ctx).Model(&model.Process{})).
+			Count(&total).Error
+		if err != nil {
+			return nil, pageInfo, err
+		}
+		pageInfo.Total = &total
+	}
+
+	stmt, err := query.page(query.filter(r.db.WithContext(ctx).Model(&model.Process{})))
+	if err != nil {
+		return nil, pageInfo, err
+	}
 
 	var processes []model.Process
-	err := r.db.WithContext(ctx).
-		Offset(offset).
-		Limit(pageSize).
-		Model(&model.Process{}).
-		Preload("Statuses").
-		Find(&processes, "code = ?", code).Error
+	err = stmt.Find(&processes).Error
 	if err != nil {
-		return nil, err
+		return nil, pageInfo, err
 	}
 
 	if len(processes) == 0 {
-		return nil, gorm.ErrRecordNotFound
+		return nil, pageInfo, gorm.ErrRecordNotFound
 	}
 
-	return processes, nil
+	if len(processes) > query.PageSize {
+		processes = processes[:query.PageSize]
+		pageInfo.NextCursor = query.nextCursor(processes[len(processes)-1])
+	}
 
+	return processes, pageInfo, nil
 }
 
-func (r *ProcessRepo) SetStatus(ctx context.Context, code string, uuid string, status string, metadata datatypes.JSON) error {
-	process, err := r.GetByUUID(ctx, code, uuid)
+// SetStatus - adds new status to the process, fails with ErrVersionConflict
+// if the process has been changed since it was read
+func (r *ProcessRepo) SetStatus(ctx context.Context, process *model.Process, status *model.ProcessStatus) error {
+	// the position of the change is reserved until the status is written
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.
+			Model(&model.Process{}).
+			Where("id = ? AND version = ?", process.ID, process.Version).
+			Updates(map[string]interface{}{
+				"version":        gorm.Expr("version + 1"),
+				"current_status": status.Name,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+
+		seq, err := nextChangeSeq(tx, 1)
+		if err != nil {
+			return err
+		}
+		status.ProcessID = process.ID
+		status.Seq = seq
+		return tx.Create(status).Error
+	})
 	if err != nil {
 		return err
 	}
+	process.Version++
+	process.CurrentStatus = status.Name
+	return nil
+}
 
-	newStatus := &model.ProcessStatus{
-		ProcessID: process.ID,
-		Name:      status,
-		Payload:   metadata,
+// nextChangeSeq - reserves n positions in the stream of changes, returns the first one.
+// The counter row stays locked until the transaction is over, so the transactions writing the changes are
+// serialized and the change is visible before any change of the greater position. Consumers reading
+// the changes after the last seen position skip none of them. The counter is rolled back with the transaction,
+// so the position of the rolled back change is taken by the next one
+func nextChangeSeq(tx *gorm.DB, n int) (uint, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	res := tx.Exec("UPDATE process_change_seqs SET seq = seq + ? WHERE id = 1", n)
+	if res.Error != nil {
+		return 0, res.Error
 	}
+	if res.RowsAffected == 0 {
+		return 0, ErrChangeSeqNotFound
+	}
+	var last uint
+	err := tx.Raw("SELECT seq FROM process_change_seqs WHERE id = 1").Scan(&last).Error
+	return last - uint(n) + 1, err
+}
 
-	err = r.db.WithContext(ctx).Model(&model.ProcessStatus{}).Save(&newStatus).Error
-	if err != nil {
+// AddWebhookEvents - writes the events into the outbox, to be called within the transaction changing the process
+func (r *ProcessRepo) AddWebhookEvents(ctx context.Context, events []model.WebhookEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(&events).Error
+}
+
+// ResetTimers - cancels pending timers of the process and adds the timer of its new status, if any.
+// To be called within the transaction changing the process
+func (r *ProcessRepo) ResetTimers(ctx context.Context, processID uint, timer *model.ProcessTimer) error {
+	err := r.db.WithContext(ctx).
+		Model(&model.ProcessTimer{}).
+		Where("process_id = ? AND state = ?", processID, model.TIMER_STATE_PENDING).
+		Update("state", model.TIMER_STATE_CANCELLED).Error
+	if err != nil || timer == nil {
 		return err
 	}
+	timer.ProcessID = processID
+	timer.State = model.TIMER_STATE_PENDING
+	return r.db.WithContext(ctx).Create(timer).Error
+}
 
-	// process.Statuses = append(process.Statuses, *newStatus)
-	return r.db.WithContext(ctx).
-		Model(&model.Process{}).
-		Where("id = ?", process.ID).
-		Association("Statuses").
-		Append(&newStatus)
+// Changes - returns the status history records of the processes, the Seq of the record is the position of the change.
+// The auto-incremented ID is not the position, as the transactions may commit out of its order and the consumer
+// would skip the change committed after the one of the greater ID. Seq is taken by nextChangeSeq instead
+func (r *ProcessRepo) Changes(ctx context.Context, query ChangeQuery) ([]model.ProcessChange, error) {
+	stmt := r.db.WithContext(ctx).
+		Table("process_statuses ps").
+		Select(`ps.seq AS id, p.code, p.uuid, ps.name, ps.payload, ps.created_by, ps.reason, ps.source_ip, ps.request_id, ps.created_at,
+			(SELECT prev.name FROM process_statuses prev
+				WHERE prev.process_id = ps.process_id AND prev.seq < ps.seq AND prev.deleted_at IS NULL
+				ORDER BY prev.seq DESC LIMIT 1) AS previous_status`).
+		Joins("JOIN processes p ON p.id = ps.process_id").
+		Where("ps.deleted_at IS NULL AND ps.seq > ?", query.After)
+	if len(query.Code) > 0 {
+		stmt = stmt.Where("p.code = ?", query.Code)
+	}
+	if len(query.UUID) > 0 {
+		stmt = stmt.Where("p.uuid = ?", query.UUID)
+	}
+
+	var changes []model.ProcessChange
+	err := stmt.Order("ps.seq").Limit(query.Limit).Scan(&changes).Error
+	return changes, err
+}
+
+// LatestChangeID - returns the position of the latest change, 0 if there are no changes
+func (r *ProcessRepo) LatestChangeID(ctx context.Context) (uint, error) {
+	var id uint
+	err := r.db.WithContext(ctx).
+		Model(&model.ProcessStatus{}).
+		Select("COALESCE(MAX(seq), 0)").
+		Scan(&id).Error
+	return id, err
+}
+
+// Transaction - runs fn within single DB transaction, the repo passed to fn is bound to it
+func (r *ProcessRepo) Transaction(ctx context.Context, fn func(repo ProcessRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&ProcessRepo{db: tx})
+	})
 }
@@ -6,7 +6,6 @@ import (
 	"github.com/alex-bezverkhniy/bp-engine/internal/model"
 
 	"github.com/stretchr/testify/mock"
-	"gorm.io/datatypes"
 )
 
 type ProcessRepoMock struct {
@@ -25,7 +24,30 @@ func (r *ProcessRepoMock) GetByCode(ctx context.Context, code string, page int,
 	args := r.Called(ctx, code, page, pageSize)
 	return args.Get(0).([]model.Process), args.Error(1)
 }
-func (r *ProcessRepoMock) SetStatus(ctx context.Context, code string, uuid string, status string, payload datatypes.JSON) error {
-	args := r.Called(ctx, code, uuid, status, payload)
+func (r *ProcessRepoMock) Find(ctx context.Context, query ProcessQuery) ([]model.Process, PageInfo, error) {
+	args := r.Called(ctx, query)
+	return args.Get(0).([]model.Process), args.Get(1).(PageInfo), args.Error(2)
+}
+func (r *ProcessRepoMock) SetStatus(ctx context.Context, process *model.Process, status *model.ProcessStatus) error {
+	args := r.Called(ctx, process, status)
+	return args.Error(0)
+}
+func (r *ProcessRepoMock) AddWebhookEvents(ctx context.Context, events []model.WebhookEvent) error {
+	args := r.Called(ctx, events)
+	return args.Error(0)
+}
+func (r *ProcessRepoMock) ResetTimers(ctx context.Context, processID uint, timer *model.ProcessTimer) error {
+	args := r.Called(ctx, processID, timer)
 	return args.Error(0)
 }
+func (r *ProcessRepoMock) Changes(ctx context.Context, query ChangeQuery) ([]model.ProcessChange, error) {
+	args := r.Called(ctx, query)
+	return args.Get(0).([]model.ProcessChange), args.Error(1)
+}
+func (r *ProcessRepoMock) LatestChangeID(ctx context.Context) (uint, error) {
+	args := r.Called(ctx)
+	return args.Get(0).(uint), args.Error(1)
+}
+func (r *ProcessRepoMock) Transaction(ctx context.Context, fn func(repo ProcessRepository) error) error {
+	return fn(r)
+}
@@ -0,0 +1,469 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/migrations"
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TEST_POSTGRES_DSN_ENV - DSN of the Postgres DB the repository tests are run against besides SQLite,
+// e.g. `host=localhost user=postgres password=postgres dbname=bp_engine_test`. Each test gets its own schema
+const TEST_POSTGRES_DSN_ENV = "BP_ENGINE_TEST_POSTGRES_DSN"
+
+// forEachDB - runs the test against in-memory SQLite and against Postgres if its DSN is given
+func forEachDB(t *testing.T, test func(t *testing.T, db *gorm.DB)) {
+	t.Run("sqlite", func(t *testing.T) {
+		test(t, testDB(t))
+	})
+	t.Run("postgres", func(t *testing.T) {
+		test(t, testPostgresDB(t))
+	})
+}
+
+// testDB - in-memory SQLite DB of the test migrated to the latest version
+func testDB(t *testing.T) *gorm.DB {
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	db := openTestDB(t, sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", name)))
+	migrateTestDB(t, db)
+	return db
+}
+
+// testPostgresDB - schema of the test in the Postgres DB migrated to the latest version,
+// the test is skipped if the DSN is not given
+func testPostgresDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv(TEST_POSTGRES_DSN_ENV)
+	if len(dsn) == 0 {
+		t.Skipf("%s is not set", TEST_POSTGRES_DSN_ENV)
+	}
+
+	schema := fmt.Sprintf("bp_engine_test_%d", time.Now().UnixNano())
+	admin := openTestDB(t, postgres.Open(dsn))
+	if err := admin.Exec(fmt.Sprintf(`CREATE SCHEMA "%s"`, schema)).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		admin.Exec(fmt.Sprintf(`DROP SCHEMA "%s" CASCADE`, schema))
+	})
+
+	// unknown parameters of the DSN are sent to the server as the session settings
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		dsn += separator + "search_path=" + schema
+	} else {
+		dsn += " search_path=" + schema
+	}
+	db := openTestDB(t, postgres.Open(dsn))
+	migrateTestDB(t, db)
+	return db
+}
+
+// openTestDB - connects to the DB, the connection is closed once the test is over
+func openTestDB(t *testing.T, dialector gorm.Dialector) *gorm.DB {
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// migrateTestDB - migrates the DB schema to the latest version
+func migrateTestDB(t *testing.T, db *gorm.DB) {
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testProcess - creates the process in the open status
+func testProcess(t *testing.T, repo ProcessRepository, uuid string) *model.Process {
+	process := &model.Process{
+		UUID:          uuid,
+		Code:          "requests",
+		Payload:       []byte(`{"amount": 100}`),
+		CurrentStatus: "open",
+		Statuses:      model.ProcessStatusList{{Name: "open"}},
+	}
+	if _, err := repo.Create(context.Background(), process); err != nil {
+		t.Fatal(err)
+	}
+	return process
+}
+
+func TestProcessRepo_SetStatus(t *testing.T) {
+	errRollback := errors.New("rollback")
+	tests := []struct {
+		name string
+		// moves the process read before into approved status
+		setStatus         func(ctx context.Context, repo ProcessRepository, process *model.Process) error
+		wantErr           error
+		wantVersion       uint
+		wantCurrentStatus string
+		wantStatuses      []string
+	}{
+		{
+			name: "success",
+			setStatus: func(ctx context.Context, repo ProcessRepository, process *model.Process) error {
+				return repo.SetStatus(ctx, process, &model.ProcessStatus{Name: "approved"})
+			},
+			wantVersion:       2,
+			wantCurrentStatus: "approved",
+			wantStatuses:      []string{"approved", "open"},
+		},
+		{
+			name: "version conflict - changed since it was read",
+			setStatus: func(ctx context.Context, repo ProcessRepository, process *model.Process) error {
+				stale := *process
+				if err := repo.SetStatus(ctx, process, &model.ProcessStatus{Name: "rejected"}); err != nil {
+					return err
+				}
+				return repo.SetStatus(ctx, &stale, &model.ProcessStatus{Name: "approved"})
+			},
+			wantErr:           ErrVersionConflict,
+			wantVersion:       2,
+			wantCurrentStatus: "rejected",
+			wantStatuses:      []string{"rejected", "open"},
+		},
+		{
+			name: "rolled back with the transaction",
+			setStatus: func(ctx context.Context, repo ProcessRepository, process *model.Process) error {
+				return repo.Transaction(ctx, func(repo ProcessRepository) error {
+					if err := repo.SetStatus(ctx, process, &model.ProcessStatus{Name: "approved"}); err != nil {
+						return err
+					}
+					return errRollback
+				})
+			},
+			wantErr:           errRollback,
+			wantVersion:       1,
+			wantCurrentStatus: "open",
+			wantStatuses:      []string{"open"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachDB(t, func(t *testing.T, db *gorm.DB) {
+				ctx := context.Background()
+				repo := NewProcessRepository(db)
+				created := testProcess(t, repo, "b8f5b3a4-5c1e-4a7e-9f1a-1c6f7c2d3e4f")
+
+				process, err := repo.GetByUUID(ctx, created.Code, created.UUID)
+				assert.NoError(t, err)
+				err = tt.setStatus(ctx, repo, process)
+				assert.ErrorIs(t, err, tt.wantErr)
+
+				// the current status is in step with the statuses history
+				got, err := repo.GetByUUID(ctx, created.Code, created.UUID)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantVersion, got.Version)
+				assert.Equal(t, tt.wantCurrentStatus, got.CurrentStatus)
+				var statuses []string
+				for _, status := range got.Statuses {
+					statuses = append(statuses, status.Name)
+				}
+				assert.Equal(t, tt.wantStatuses, statuses)
+			})
+		})
+	}
+}
+
+func TestProcessRepo_Changes(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		repo := NewProcessRepository(db)
+		first := testProcess(t, repo, "5f0c6f1e-8a4b-4d2c-9e7f-0a1b2c3d4e01")
+		second := testProcess(t, repo, "5f0c6f1e-8a4b-4d2c-9e7f-0a1b2c3d4e02")
+		assert.NoError(t, repo.SetStatus(ctx, first, &model.ProcessStatus{Name: "approved"}))
+		// the position of the rolled back change is taken by the next one
+		err := repo.Transaction(ctx, func(repo ProcessRepository) error {
+			if err := repo.SetStatus(ctx, second, &model.ProcessStatus{Name: "rejected"}); err != nil {
+				return err
+			}
+			return errors.New("rollback")
+		})
+		assert.Error(t, err)
+		second.Version = 1
+		assert.NoError(t, repo.SetStatus(ctx, second, &model.ProcessStatus{Name: "approved"}))
+
+		type change struct {
+			ID             uint
+			UUID           string
+			PreviousStatus string
+			Name           string
+		}
+		tests := []struct {
+			name  string
+			query ChangeQuery
+			want  []change
+		}{
+			{
+				name:  "all",
+				query: ChangeQuery{Limit: 10},
+				want: []change{
+					{ID: 1, UUID: first.UUID, Name: "open"},
+					{ID: 2, UUID: second.UUID, Name: "open"},
+					{ID: 3, UUID: first.UUID, PreviousStatus: "open", Name: "approved"},
+					{ID: 4, UUID: second.UUID, PreviousStatus: "open", Name: "approved"},
+				},
+			},
+			{
+				name:  "after the position",
+				query: ChangeQuery{After: 2, Limit: 1},
+				want: []change{
+					{ID: 3, UUID: first.UUID, PreviousStatus: "open", Name: "approved"},
+				},
+			},
+			{
+				name:  "of the process",
+				query: ChangeQuery{Code: "requests", UUID: second.UUID, Limit: 10},
+				want: []change{
+					{ID: 2, UUID: second.UUID, Name: "open"},
+					{ID: 4, UUID: second.UUID, PreviousStatus: "open", Name: "approved"},
+				},
+			},
+			{
+				name:  "none after the latest",
+				query: ChangeQuery{After: 4, Limit: 10},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				changes, err := repo.Changes(ctx, tt.query)
+				assert.NoError(t, err)
+				var got []change
+				for _, c := range changes {
+					got = append(got, change{ID: c.ID, UUID: c.UUID, PreviousStatus: c.PreviousStatus, Name: c.Name})
+				}
+				assert.Equal(t, tt.want, got)
+			})
+		}
+
+		latest, err := repo.LatestChangeID(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(4), latest)
+	})
+}
+
+func TestProcessRepo_ChangesCommitOrder(t *testing.T) {
+	// SQLite has single writer, the concurrent transactions are checked on Postgres only
+	db := testPostgresDB(t)
+	ctx := context.Background()
+	repo := NewProcessRepository(db)
+	first := testProcess(t, repo, "5f0c6f1e-8a4b-4d2c-9e7f-0a1b2c3d4e01")
+	second := testProcess(t, repo, "5f0c6f1e-8a4b-4d2c-9e7f-0a1b2c3d4e02")
+
+	written := make(chan struct{})
+	commit := make(chan struct{})
+	firstDone := make(chan error, 1)
+	go func() {
+		firstDone <- repo.Transaction(ctx, func(repo ProcessRepository) error {
+			if err := repo.SetStatus(ctx, first, &model.ProcessStatus{Name: "approved"}); err != nil {
+				return err
+			}
+			close(written)
+			<-commit
+			return nil
+		})
+	}()
+	select {
+	case <-written:
+	case err := <-firstDone:
+		t.Fatal(err)
+	}
+
+	secondDone := make(chan error, 1)
+	go func() {
+		secondDone <- repo.Transaction(ctx, func(repo ProcessRepository) error {
+			return repo.SetStatus(ctx, second, &model.ProcessStatus{Name: "approved"})
+		})
+	}()
+	// the change of the greater position waits for the one of the lower position to commit
+	select {
+	case err := <-secondDone:
+		t.Fatalf("change is committed before the one of the lower position: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	changes, err := repo.Changes(ctx, ChangeQuery{After: 2, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	close(commit)
+	assert.NoError(t, <-firstDone)
+	assert.NoError(t, <-secondDone)
+
+	changes, err = repo.Changes(ctx, ChangeQuery{After: 2, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 2) {
+		assert.Equal(t, []uint{3, 4}, []uint{changes[0].ID, changes[1].ID})
+		assert.Equal(t, []string{first.UUID, second.UUID}, []string{changes[0].UUID, changes[1].UUID})
+	}
+}
+
+func TestProcessRepo_Find(t *testing.T) {
+	// the time range may be given in other time zone than the local one of the stored timestamps
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	base := time.Date(2023, 12, 1, 9, 0, 0, 0, time.Local)
+	at := func(hours int) *time.Time {
+		t := base.Add(time.Duration(hours) * time.Hour)
+		return &t
+	}
+	// created hourly, changed in the reverse order, the last two are created at the same time
+	processes := []model.Process{
+		{UUID: "1", CurrentStatus: "open", Payload: []byte(`{"amount": 9, "customer": {"name": "Alex"}, "urgent": true}`)},
+		{UUID: "2", CurrentStatus: "approved", Payload: []byte(`{"amount": 20, "customer": {"name": "Maria"}}`)},
+		{UUID: "3", CurrentStatus: "open", Payload: []byte(`{"amount": 100, "customer": {"name": "Alexandra"}}`)},
+		{UUID: "4", CurrentStatus: "rejected", Payload: []byte(`{"amount": "1000", "customer": {"name": "100%_sure"}}`)},
+	}
+	for i := range processes {
+		hours := i
+		if i == len(processes)-1 {
+			hours = i - 1
+		}
+		processes[i].Code = "requests"
+		processes[i].CreatedAt = *at(hours)
+		processes[i].UpdatedAt = *at(10 - i)
+	}
+
+	tests := []struct {
+		name  string
+		query ProcessQuery
+		// UUIDs of the pages
+		want [][]string
+	}{
+		{
+			name:  "statuses",
+			query: ProcessQuery{Statuses: []string{"open", "rejected"}},
+			want:  [][]string{{"1", "3", "4"}},
+		},
+		{
+			name:  "created within the time range",
+			query: ProcessQuery{CreatedFrom: at(1), CreatedTo: at(2)},
+			want:  [][]string{{"2"}},
+		},
+		{
+			name:  "created within the time range given in other time zone",
+			query: ProcessQuery{CreatedFrom: utc(at(1)), CreatedTo: utc(at(2))},
+			want:  [][]string{{"2"}},
+		},
+		{
+			name:  "changed since",
+			query: ProcessQuery{ChangedFrom: at(9)},
+			want:  [][]string{{"1", "2"}},
+		},
+		{
+			name:  "payload number compared as number",
+			query: ProcessQuery{Payload: []PayloadFilter{{Field: "amount", Operator: OperatorGte, Value: "20"}}},
+			want:  [][]string{{"2", "3"}},
+		},
+		{
+			name:  "payload number equal to the string",
+			query: ProcessQuery{Payload: []PayloadFilter{{Field: "amount", Operator: OperatorEq, Value: "1000"}}},
+			want:  [][]string{{"4"}},
+		},
+		{
+			name:  "payload not equal",
+			query: ProcessQuery{Payload: []PayloadFilter{{Field: "amount", Operator: OperatorNe, Value: "9"}}},
+			want:  [][]string{{"2", "3", "4"}},
+		},
+		{
+			name:  "payload boolean",
+			query: ProcessQuery{Payload: []PayloadFilter{{Field: "urgent", Operator: OperatorEq, Value: "true"}}},
+			want:  [][]string{{"1"}},
+		},
+		{
+			name:  "payload nested field contains ignoring case",
+			query: ProcessQuery{Payload: []PayloadFilter{{Field: "customer.name", Operator: OperatorContains, Value: "ALEX"}}},
+			want:  [][]string{{"1", "3"}},
+		},
+		{
+			name:  "payload contains wildcards literally",
+			query: ProcessQuery{Payload: []PayloadFilter{{Field: "customer.name", Operator: OperatorContains, Value: "0%_"}}},
+			want:  [][]string{{"4"}},
+		},
+		{
+			name:  "keyset pages",
+			query: ProcessQuery{PageSize: 2},
+			want:  [][]string{{"1", "2"}, {"3", "4"}},
+		},
+		{
+			name:  "keyset pages - same created time in descending order",
+			query: ProcessQuery{SortDesc: true, PageSize: 1},
+			want:  [][]string{{"4"}, {"3"}, {"2"}, {"1"}},
+		},
+		{
+			name:  "keyset pages by changed time",
+			query: ProcessQuery{SortBy: SortByChangedAt, PageSize: 3},
+			want:  [][]string{{"4", "3", "2"}, {"1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachDB(t, func(t *testing.T, db *gorm.DB) {
+				ctx := context.Background()
+				repo := NewProcessRepository(db)
+				for _, process := range processes {
+					assert.NoError(t, db.Create(&process).Error)
+				}
+				other := model.Process{UUID: "5", Code: "orders", CurrentStatus: "open", Payload: []byte(`{"amount": 100}`)}
+				assert.NoError(t, db.Create(&other).Error)
+
+				query := tt.query
+				query.Code = "requests"
+				if len(query.SortBy) == 0 {
+					query.SortBy = SortByCreatedAt
+				}
+				if query.PageSize == 0 {
+					query.PageSize = DEFAULT_PAGE_SIZE
+				}
+				var got [][]string
+				for {
+					page, pageInfo, err := repo.Find(ctx, query)
+					assert.NoError(t, err)
+					var uuids []string
+					for _, process := range page {
+						uuids = append(uuids, process.UUID)
+					}
+					got = append(got, uuids)
+					if len(pageInfo.NextCursor) == 0 || len(got) > len(tt.want) {
+						break
+					}
+					query.Cursor = pageInfo.NextCursor
+				}
+				assert.Equal(t, tt.want, got)
+			})
+		})
+	}
+}
+
+func utc(t *time.Time) *time.Time {
+	res := t.UTC()
+	return &res
+}
@@ -2,7 +2,11 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
 	"github.com/alex-bezverkhniy/bp-engine/internal/model"
 	"github.com/alex-bezverkhniy/bp-engine/internal/validators"
 
@@ -14,34 +18,108 @@ import (
 
 type (
 	ProcessService interface {
-		Submit(ctx context.Context, process *model.ProcessDTO) (string, error)
+		Submit(ctx context.Context, process *model.ProcessDTO, actor model.Actor) (string, error)
 		Get(ctx context.Context, code string, uuid string, page int, pageSize int) (model.ProcessListDTO, error)
-		AssignStatus(ctx context.Context, code string, uuid string, status string, metadata model.Payload) error
+		Find(ctx context.Context, query ProcessQuery) (model.ProcessListDTO, PageInfo, error)
+		AssignStatus(ctx context.Context, code string, uuid string, status model.ProcessStatusDTO, version uint, actor model.Actor) error
+		ValidateStatus(ctx context.Context, code string, uuid string, status model.ProcessStatusDTO, version uint, actor model.Actor) error
+		Transitions(ctx context.Context, code string, uuid string, actor model.Actor) (model.TransitionListDTO, error)
+		Changes(ctx context.Context, query ChangeQuery) (model.ProcessChangeListDTO, error)
+		LatestChangeID(ctx context.Context) (uint, error)
+		WaitChanges() <-chan struct{}
 	}
 	ProcessSrvc struct {
 		validator validators.Validator
 		repo      ProcessRepository
+		webhooks  config.WebhookConfigList
+		events    *EventBus
+		changes   *changeSignal
+	}
+
+	// changeSignal - wakes up everyone waiting for the next change
+	changeSignal struct {
+		mu sync.Mutex
+		ch chan struct{}
 	}
 )
 
 var (
 	ErrProcessNotFound     error = errors.New("process not found")
 	ErrCannotCreateProcess error = errors.New("cannot create process")
+	ErrStatusOnSubmit      error = errors.New("status cannot be set on submit")
+	ErrVersionConflict     error = errors.New("process has been changed by someone else")
 )
 
-func NewProcessService(repo ProcessRepository, validator validators.Validator) ProcessService {
+// NewProcessService - creates the service, status changes matched the webhooks are written into the outbox
+// and published to the hooks of the event bus, which is optional
+func NewProcessService(repo ProcessRepository, validator validators.Validator, webhooks config.WebhookConfigList, events *EventBus) ProcessService {
 	return &ProcessSrvc{
 		validator: validator,
 		repo:      repo,
+		webhooks:  webhooks,
+		events:    events,
+		changes:   &changeSignal{ch: make(chan struct{})},
 	}
 }
 
-func (s *ProcessSrvc) Submit(ctx context.Context, process *model.ProcessDTO) (string, error) {
-	uuid, err := s.repo.Create(ctx, process.ToEntity())
+func (s *ProcessSrvc) Submit(ctx context.Context, process *model.ProcessDTO, actor model.Actor) (string, error) {
+	// The status of a new process is defined by the process config only
+	if process.CurrentStatus != nil || len(process.Statuses) > 0 {
+		return "", ErrStatusOnSubmit
+	}
+
+	// Check process code and payload
+	if err := s.validator.ValidateSubmit(*process); err != nil {
+		return "", err
+	}
+
+	initialStatus, err := s.validator.InitialStatus(process.Code)
+	if err != nil {
+		return "", err
+	}
+	info := RequestInfoFromContext(ctx)
+	process.CreatedBy = actor.ID
+	process.CurrentStatus = &model.ProcessStatusDTO{
+		Name:      initialStatus,
+		CreatedBy: actor.ID,
+		SourceIP:  info.SourceIP,
+		RequestID: info.RequestID,
+	}
+
+	entity := process.ToEntity()
+	var event model.ProcessEvent
+	err = s.repo.Transaction(ctx, func(repo ProcessRepository) error {
+		if _, err := repo.Create(ctx, entity); err != nil {
+			return err
+		}
+
+		events, err := newWebhookEvents(s.webhooks, model.EVENT_PROCESS_SUBMITTED, entity, "", entity.Statuses.Latest())
+		if err != nil {
+			return err
+		}
+		if err := repo.AddWebhookEvents(ctx, events); err != nil {
+			return err
+		}
+		if err := repo.ResetTimers(ctx, entity.ID, s.newTimer(entity, entity.Statuses.Latest())); err != nil {
+			return err
+		}
+
+		after := entity.ToDTO()
+		event = model.ProcessEvent{
+			Type:   model.EVENT_PROCESS_SUBMITTED,
+			Actor:  actor,
+			After:  &after,
+			Status: *after.CurrentStatus,
+		}
+		return s.events.Publish(ctx, event)
+	})
 	if err != nil {
 		return "", errors.Join(err, ErrCannotCreateProcess)
 	}
-	return uuid, nil
+
+	s.changes.notify()
+	s.events.PublishAsync(ctx, event)
+	return entity.UUID, nil
 }
 
 func (s *ProcessSrvc) Get(ctx context.Context, code string, uuid string, page int, pageSize int) (model.ProcessListDTO, error) {
@@ -77,27 +155,146 @@ func (s *ProcessSrvc) Get(ctx context.Context, code string, uuid string, page in
 	return processes.ToDTO(), nil
 }
 
-func (s *ProcessSrvc) AssignStatus(ctx context.Context, code string, uuid string, status string, payload model.Payload) error {
-	// Check process exist
-	process, err := s.repo.GetByUUID(ctx, code, uuid)
+// Find - returns page of processes matched the query
+func (s *ProcessSrvc) Find(ctx context.Context, query ProcessQuery) (model.ProcessListDTO, PageInfo, error) {
+	if query.Page <= 0 {
+		query.Page = DEFAULT_PAGE
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = DEFAULT_PAGE_SIZE
+	}
+
+	processes, pageInfo, err := s.repo.Find(ctx, query)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrProcessNotFound
+			return nil, pageInfo, ErrProcessNotFound
 		}
-
-		return err
+		return nil, pageInfo, err
 	}
-	newStatus := model.ProcessStatusDTO{
-		Name:    status,
-		Payload: payload,
+
+	return model.ProcessList(processes).ToDTO(), pageInfo, nil
+}
+
+// AssignStatus - validates and moves the process into the status within single transaction.
+// Non zero version is the version of the process the caller expects to change.
+// The actor and the request info of the context are recorded into the status history.
+func (s *ProcessSrvc) AssignStatus(ctx context.Context, code string, uuid string, status model.ProcessStatusDTO, version uint, actor model.Actor) error {
+	return s.assignStatus(ctx, code, uuid, status, version, actor, false)
+}
+
+// ValidateStatus - runs the same checks as AssignStatus without changing the process
+func (s *ProcessSrvc) ValidateStatus(ctx context.Context, code string, uuid string, status model.ProcessStatusDTO, version uint, actor model.Actor) error {
+	return s.assignStatus(ctx, code, uuid, status, version, actor, true)
+}
+
+// Transitions - returns statuses the actor can move the process into
+func (s *ProcessSrvc) Transitions(ctx context.Context, code string, uuid string, actor model.Actor) (model.TransitionListDTO, error) {
+	process, err := s.repo.GetByUUID(ctx, code, uuid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProcessNotFound
+		}
+		return nil, err
 	}
-	// Validate the status
-	err = s.validator.Validate(process.ToDTO(), newStatus)
+
+	return s.validator.AllowedTransitions(process.ToDTO(), actor)
+}
+
+// Changes - returns submits and status changes of the processes in order they were made
+func (s *ProcessSrvc) Changes(ctx context.Context, query ChangeQuery) (model.ProcessChangeListDTO, error) {
+	changes, err := s.repo.Changes(ctx, query)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return model.ProcessChangeList(changes).ToDTO(), nil
+}
+
+// LatestChangeID - returns the position of the latest change
+func (s *ProcessSrvc) LatestChangeID(ctx context.Context) (uint, error) {
+	return s.repo.LatestChangeID(ctx)
+}
+
+// WaitChanges - returns the channel closed on the next change made by this service,
+// changes made by other instances of the engine are not signaled
+func (s *ProcessSrvc) WaitChanges() <-chan struct{} {
+	s.changes.mu.Lock()
+	defer s.changes.mu.Unlock()
+	return s.changes.ch
+}
+
+func (s *ProcessSrvc) assignStatus(ctx context.Context, code string, uuid string, status model.ProcessStatusDTO, version uint, actor model.Actor, dryRun bool) error {
+	var event model.ProcessEvent
+	err := s.repo.Transaction(ctx, func(repo ProcessRepository) error {
+		// Check process exist
+		process, err := repo.GetByUUID(ctx, code, uuid)
+		if err != nil {
+			return err
+		}
+		if version > 0 && process.Version != version {
+			return ErrVersionConflict
+		}
+
+		// Validate the status
+		before := process.ToDTO()
+		err = s.validator.Validate(before, status, actor)
+		if err != nil {
+			return err
+		}
+
+		// Let the hooks veto the change
+		err = s.events.Publish(ctx, model.ProcessEvent{
+			Type:   model.EVENT_PROCESS_BEFORE_STATUS_CHANGE,
+			Actor:  actor,
+			Before: &before,
+			Status: status,
+			DryRun: dryRun,
+		})
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrStatusChangeVetoed, err)
+		}
+		if dryRun {
+			return nil
+		}
+
+		info := RequestInfoFromContext(ctx)
+		previousStatus := process.CurrentStatus
+		newStatus := &model.ProcessStatus{
+			Name:      status.Name,
+			Payload:   datatypes.JSON(status.Payload.ToBytes()),
+			CreatedBy: actor.ID,
+			Reason:    strings.TrimSpace(status.Reason),
+			SourceIP:  info.SourceIP,
+			RequestID: info.RequestID,
+		}
+		if err := repo.SetStatus(ctx, process, newStatus); err != nil {
+			return err
+		}
+
+		// the outbox is written within the same transaction, so no change is lost or announced twice
+		events, err := newWebhookEvents(s.webhooks, model.EVENT_PROCESS_STATUS_CHANGED, process, previousStatus, newStatus)
+		if err != nil {
+			return err
+		}
+		if err := repo.AddWebhookEvents(ctx, events); err != nil {
+			return err
+		}
+		// timer of the previous status is cancelled, so it cannot move the process twice
+		if err := repo.ResetTimers(ctx, process.ID, s.newTimer(process, newStatus)); err != nil {
+			return err
+		}
+
+		process.Statuses = append(model.ProcessStatusList{*newStatus}, process.Statuses...)
+		after := process.ToDTO()
+		event = model.ProcessEvent{
+			Type:   model.EVENT_PROCESS_STATUS_CHANGED,
+			Actor:  actor,
+			Before: &before,
+			After:  &after,
+			Status: *after.CurrentStatus,
+		}
+		return s.events.Publish(ctx, event)
+	})
 
-	err = s.repo.SetStatus(ctx, code, uuid, status, datatypes.JSON(payload.ToBytes()))
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrProcessNotFound
@@ -105,5 +302,32 @@ func (s *ProcessSrvc) AssignStatus(ctx context.Context, code string, uuid string
 		return err
 	}
 
+	if !dryRun {
+		s.changes.notify()
+		s.events.PublishAsync(ctx, event)
+	}
 	return nil
 }
+
+// newTimer - timer of the status the process has just entered, nil if the status has no timeout
+func (s *ProcessSrvc) newTimer(process *model.Process, status *model.ProcessStatus) *model.ProcessTimer {
+	timeout, onTimeout := s.validator.StatusTimeout(process.Code, status.Name)
+	if timeout <= 0 {
+		return nil
+	}
+	return &model.ProcessTimer{
+		Code:      process.Code,
+		UUID:      process.UUID,
+		Version:   process.Version,
+		Status:    status.Name,
+		OnTimeout: onTimeout,
+		DueAt:     status.CreatedAt.Add(timeout),
+	}
+}
+
+func (cs *changeSignal) notify() {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	close(cs.ch)
+	cs.ch = make(chan struct{})
+}
@@ -12,8 +12,8 @@ type ProcessSrvcMock struct {
 	mock.Mock
 }
 
-func (s *ProcessSrvcMock) Submit(ctx context.Context, process *model.ProcessDTO) (string, error) {
-	args := s.Called(ctx, process)
+func (s *ProcessSrvcMock) Submit(ctx context.Context, process *model.ProcessDTO, actor model.Actor) (string, error) {
+	args := s.Called(ctx, process, actor)
 	return args.Get(0).(string), args.Error(1)
 }
 func (s *ProcessSrvcMock) Get(ctx context.Context, code string, uuid string, page int, pageSize int) (model.ProcessListDTO, error) {
@@ -24,7 +24,43 @@ func (s *ProcessSrvcMock) Get(ctx context.Context, code string, uuid string, pag
 	}
 	return nil, args.Error(1)
 }
-func (s *ProcessSrvcMock) AssignStatus(ctx context.Context, code string, uuid string, status string, payload model.Payload) error {
-	args := s.Called(ctx, code, uuid, status, payload)
+func (s *ProcessSrvcMock) Find(ctx context.Context, query ProcessQuery) (model.ProcessListDTO, PageInfo, error) {
+	args := s.Called(ctx, query)
+	res := args.Get(0)
+	if res != nil {
+		return args.Get(0).(model.ProcessListDTO), args.Get(1).(PageInfo), args.Error(2)
+	}
+	return nil, PageInfo{}, args.Error(2)
+}
+func (s *ProcessSrvcMock) AssignStatus(ctx context.Context, code string, uuid string, status model.ProcessStatusDTO, version uint, actor model.Actor) error {
+	args := s.Called(ctx, code, uuid, status, version, actor)
 	return args.Error(0)
 }
+func (s *ProcessSrvcMock) ValidateStatus(ctx context.Context, code string, uuid string, status model.ProcessStatusDTO, version uint, actor model.Actor) error {
+	args := s.Called(ctx, code, uuid, status, version, actor)
+	return args.Error(0)
+}
+func (s *ProcessSrvcMock) Transitions(ctx context.Context, code string, uuid string, actor model.Actor) (model.TransitionListDTO, error) {
+	args := s.Called(ctx, code, uuid, actor)
+	res := args.Get(0)
+	if res != nil {
+		return res.(model.TransitionListDTO), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (s *ProcessSrvcMock) Changes(ctx context.Context, query ChangeQuery) (model.ProcessChangeListDTO, error) {
+	args := s.Called(ctx, query)
+	res := args.Get(0)
+	if res != nil {
+		return res.(model.ProcessChangeListDTO), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (s *ProcessSrvcMock) LatestChangeID(ctx context.Context) (uint, error) {
+	args := s.Called(ctx)
+	return args.Get(0).(uint), args.Error(1)
+}
+func (s *ProcessSrvcMock) WaitChanges() <-chan struct{} {
+	args := s.Called()
+	return args.Get(0).(chan struct{})
+}
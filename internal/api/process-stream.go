@@ -0,0 +1,176 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	log "github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	HEADERNAME_LAST_EVENT_ID = "Last-Event-ID"
+
+	QUERYPARAM_LAST_EVENT_ID = "last_event_id"
+
+	// changes of other engine instances are not signaled, so the stream polls the DB as well
+	ssePollInterval = 5 * time.Second
+	sseRetry        = 3 * time.Second
+	sseBatchSize    = 100
+)
+
+var (
+	NotSupportedValueForLastEventIdErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "not supported value for " + HEADERNAME_LAST_EVENT_ID,
+	}
+	CannotStreamEventsErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "cannot stream process events",
+	}
+)
+
+// @Summary Stream of process changes
+// @Description Server-Sent Events of submits and status changes of the processes,
+// @Description the event ID is the position of the change to resume the stream from
+// @Tags process
+// @Param	code			path	string	true	"Code of Process"
+// @Param	Last-Event-ID	header	int		false	"ID of the last received event, only new changes are streamed if not set"
+// @Param	last_event_id	query	int		false	"Same as Last-Event-ID, for clients unable to set the header"
+// @Produce text/event-stream
+// @Success	200 {object} model.ProcessChangeDTO
+// @Failed	400 {object} model.ProcessErrorResponse
+// @Router /api/v1/process/{code}/events [get]
+func (pc *ProcessController) StreamEvents(c *fiber.Ctx) error {
+	return pc.streamEvents(c, ChangeQuery{Code: c.Params("code")})
+}
+
+// @Summary Stream of changes of the process
+// @Description Server-Sent Events of submit and status changes of the process,
+// @Description the event ID is the position of the change to resume the stream from
+// @Tags process
+// @Param	code			path	string	true	"Code of Process"
+// @Param	uuid			path	string	true	"UUID of Process"
+// @Param	Last-Event-ID	header	int		false	"ID of the last received event, only new changes are streamed if not set"
+// @Param	last_event_id	query	int		false	"Same as Last-Event-ID, for clients unable to set the header"
+// @Produce text/event-stream
+// @Success	200 {object} model.ProcessChangeDTO
+// @Failed	400 {object} model.ProcessErrorResponse
+// @Failed	404 {object} model.ProcessErrorResponse
+// @Router /api/v1/process/{code}/{uuid}/events [get]
+func (pc *ProcessController) StreamProcessEvents(c *fiber.Ctx) error {
+	code := c.Params("code")
+	uuid := c.Params("uuid")
+
+	_, err := pc.service.Get(c.Context(), code, uuid, DEFAULT_PAGE, DEFAULT_PAGE_SIZE)
+	if err != nil {
+		log.Error("cannot get process by UUID ", err)
+		if errors.Is(err, ErrProcessNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ProcessNotFoundErrResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(CannotStreamEventsErrResp)
+	}
+
+	return pc.streamEvents(c, ChangeQuery{Code: code, UUID: uuid})
+}
+
+func (pc *ProcessController) streamEvents(c *fiber.Ctx, query ChangeQuery) error {
+	lastEventID := c.Get(HEADERNAME_LAST_EVENT_ID, c.Query(QUERYPARAM_LAST_EVENT_ID))
+	if len(lastEventID) > 0 {
+		after, err := strconv.ParseUint(strings.TrimSpace(lastEventID), 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(NotSupportedValueForLastEventIdErrResp)
+		}
+		query.After = uint(after)
+	} else {
+		// new subscriber gets the changes made since it is connected
+		latest, err := pc.service.LatestChangeID(c.Context())
+		if err != nil {
+			log.Error("cannot get latest change ", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(CannotStreamEventsErrResp)
+		}
+		query.After = latest
+	}
+	query.Limit = sseBatchSize
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	log.Infof("stream events of process %s %s after %d", query.Code, query.UUID, query.After)
+	// closed by the server on shutdown, the streams are ended then so the shutdown is not blocked by them
+	shutdown := c.Context().Done()
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-shutdown:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		pc.writeEvents(ctx, w, query)
+	})
+	return nil
+}
+
+// writeEvents - writes the changes until the client is gone or the context is done, the stream is closed
+// on DB error and the client is expected to reconnect with the last received event ID
+func (pc *ProcessController) writeEvents(ctx context.Context, w *bufio.Writer, query ChangeQuery) {
+	ticker := time.NewTicker(ssePollInterval)
+	defer ticker.Stop()
+
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	for {
+		// subscribe before reading, so the change made in between is not missed
+		wait := pc.service.WaitChanges()
+
+		changes, err := pc.service.Changes(ctx, query)
+		if err != nil {
+			log.Error("cannot get process changes ", err)
+			return
+		}
+		for _, change := range changes {
+			if err := writeServerSentEvent(w, change); err != nil {
+				log.Error("cannot write process change ", err)
+				return
+			}
+			query.After = change.ID
+		}
+		// comment line keeps the connection alive and detects gone clients
+		if len(changes) == 0 {
+			w.WriteString(": ping\n\n")
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		if len(changes) < query.Limit {
+			select {
+			case <-wait:
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func writeServerSentEvent(w *bufio.Writer, change model.ProcessChangeDTO) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", change.ID, change.Type, data)
+	return err
+}
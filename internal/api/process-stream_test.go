@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStreamEvents_Errors(t *testing.T) {
+	defaultUuid := uuid.NewString()
+	tests := []struct {
+		name     string
+		url      string
+		headers  map[string]string
+		mockFunc func() *ProcessController
+		wantCode int
+		wantErr  *model.ProcessErrorResponse
+	}{
+		{
+			name:    "fail - 400 - Last-Event-ID header",
+			url:     "/test/requests/events",
+			headers: map[string]string{HEADERNAME_LAST_EVENT_ID: "abc"},
+			mockFunc: func() *ProcessController {
+				return NewProcessController(&ProcessSrvcMock{})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedValueForLastEventIdErrResp,
+		},
+		{
+			name: "fail - 400 - last_event_id query param",
+			url:  "/test/requests/events?last_event_id=-1",
+			mockFunc: func() *ProcessController {
+				return NewProcessController(&ProcessSrvcMock{})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedValueForLastEventIdErrResp,
+		},
+		{
+			name: "fail - 404 - process not found",
+			url:  "/test/requests/" + defaultUuid + "/events",
+			mockFunc: func() *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("Get", mock.Anything, "requests", defaultUuid, DEFAULT_PAGE, DEFAULT_PAGE_SIZE).
+					Return(nil, ErrProcessNotFound)
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  &ProcessNotFoundErrResp,
+		},
+		{
+			name: "fail - 500 - latest change",
+			url:  "/test/requests/events",
+			mockFunc: func() *ProcessController {
+				service := ProcessSrvcMock{}
+				service.On("LatestChangeID", mock.Anything).
+					Return(uint(0), errors.New("OMG error"))
+				return NewProcessController(&service)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantErr:  &CannotStreamEventsErrResp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testApp = fiber.New()
+			tt.mockFunc().SetupRouter(testApp.Group("/test"))
+
+			req := httptest.NewRequest("GET", "http://localhost"+tt.url, nil)
+			for k, v := range tt.headers {
+				req.Header.Add(k, v)
+			}
+			resp, err := testApp.Test(req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.Nil(t, err)
+			var gotResp model.ProcessErrorResponse
+			assert.Nil(t, json.Unmarshal(body, &gotResp))
+			assert.Equal(t, *tt.wantErr, gotResp)
+		})
+	}
+}
+
+func TestWriteEvents(t *testing.T) {
+	defaultUuid := uuid.NewString()
+	createdAt := time.Date(2023, 12, 8, 11, 33, 55, 0, time.UTC)
+	changes := model.ProcessChangeListDTO{
+		{ID: 41, Type: model.EVENT_PROCESS_SUBMITTED, Code: "requests", UUID: defaultUuid, Status: model.ProcessStatusDTO{Name: "open", CreatedAt: &createdAt}},
+		{ID: 42, Type: model.EVENT_PROCESS_STATUS_CHANGED, Code: "requests", UUID: defaultUuid, PreviousStatus: "open", Status: model.ProcessStatusDTO{Name: "done", CreatedAt: &createdAt}},
+	}
+	changed := make(chan struct{})
+	close(changed)
+
+	service := ProcessSrvcMock{}
+	service.On("WaitChanges").Return(changed)
+	service.On("Changes", mock.Anything, ChangeQuery{Code: "requests", After: 40, Limit: sseBatchSize}).
+		Return(changes, nil).Once()
+	service.On("Changes", mock.Anything, ChangeQuery{Code: "requests", After: 42, Limit: sseBatchSize}).
+		Return(model.ProcessChangeListDTO{}, nil).Once()
+	// the stream is closed on error
+	service.On("Changes", mock.Anything, ChangeQuery{Code: "requests", After: 42, Limit: sseBatchSize}).
+		Return(nil, errors.New("OMG error")).Once()
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	NewProcessController(&service).writeEvents(context.Background(), w, ChangeQuery{Code: "requests", After: 40, Limit: sseBatchSize})
+	w.Flush()
+
+	assert.Equal(t, "retry: 3000\n\n"+
+		"id: 41\nevent: process.submitted\n"+
+		`data: {"id":41,"type":"process.submitted","code":"requests","uuid":"`+defaultUuid+`","status":{"name":"open","created_at":"2023-12-08T11:33:55Z"}}`+"\n\n"+
+		"id: 42\nevent: process.status_changed\n"+
+		`data: {"id":42,"type":"process.status_changed","code":"requests","uuid":"`+defaultUuid+`","previous_status":"open","status":{"name":"done","created_at":"2023-12-08T11:33:55Z"}}`+"\n\n"+
+		": ping\n\n", buf.String())
+	service.AssertExpectations(t)
+}
+
+func TestWriteEvents_Shutdown(t *testing.T) {
+	service := ProcessSrvcMock{}
+	service.On("WaitChanges").Return(make(chan struct{}))
+	service.On("Changes", mock.Anything, ChangeQuery{Code: "requests", After: 40, Limit: sseBatchSize}).
+		Return(model.ProcessChangeListDTO{}, nil).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	// the stream waiting for changes is ended once the context is done
+	NewProcessController(&service).writeEvents(ctx, w, ChangeQuery{Code: "requests", After: 40, Limit: sseBatchSize})
+	w.Flush()
+
+	assert.Equal(t, "retry: 3000\n\n: ping\n\n", buf.String())
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+)
+
+type (
+	// RequestInfo - origin of the API request, recorded into the status history
+	RequestInfo struct {
+		SourceIP  string
+		RequestID string
+	}
+
+	requestInfoKey struct{}
+)
+
+// ContextWithRequestInfo - returns copy of the context carrying the request info
+func ContextWithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext - returns the request info, empty one if the context has none
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
+
+// requestContext - context of the request carrying its source IP and ID,
+// the ID is set by the requestid middleware or taken from the X-Request-ID header
+func requestContext(c *fiber.Ctx) context.Context {
+	requestID, _ := c.Locals(requestid.ConfigDefault.ContextKey).(string)
+	if len(requestID) == 0 {
+		requestID = c.Get(fiber.HeaderXRequestID)
+	}
+	return ContextWithRequestInfo(c.Context(), RequestInfo{
+		SourceIP:  c.IP(),
+		RequestID: requestID,
+	})
+}
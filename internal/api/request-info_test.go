@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_requestContext(t *testing.T) {
+	tests := []struct {
+		name          string
+		withRequestID bool
+		requestID     string
+		wantRequestID string
+	}{
+		{
+			name:          "request id header",
+			requestID:     "42",
+			wantRequestID: "42",
+		},
+		{
+			name:          "requestid middleware",
+			withRequestID: true,
+			requestID:     "43",
+			wantRequestID: "43",
+		},
+		{
+			name: "no request id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotInfo RequestInfo
+			var testApp = fiber.New()
+			if tt.withRequestID {
+				testApp.Use(requestid.New())
+			}
+			testApp.Get("/test", func(c *fiber.Ctx) error {
+				gotInfo = RequestInfoFromContext(requestContext(c))
+				return nil
+			})
+
+			req := httptest.NewRequest("GET", "http://localhost/test", nil)
+			if len(tt.requestID) > 0 {
+				req.Header.Add(fiber.HeaderXRequestID, tt.requestID)
+			}
+			_, err := testApp.Test(req)
+			assert.Nil(t, err)
+
+			assert.Equal(t, tt.wantRequestID, gotInfo.RequestID)
+			assert.Equal(t, "0.0.0.0", gotInfo.SourceIP)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	log "github.com/gofiber/fiber/v2/log"
+)
+
+type (
+	ScheduleController struct {
+		service ScheduleService
+	}
+)
+
+var (
+	ScheduleStatusRequiredErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "status is required",
+	}
+	RunAtNotInFutureErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "run_at has to be in the future",
+	}
+	ScheduledAssignmentNotFoundErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "scheduled assignment not found",
+	}
+	ScheduledAssignmentNotPendingErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "only pending scheduled assignments can be cancelled",
+	}
+	NotSupportedScheduledAssignmentIdErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "not supported scheduled assignment id",
+	}
+	NotSupportedScheduledAssignmentStateErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "not supported scheduled assignment state",
+	}
+	CannotScheduleAssignmentErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "cannot schedule status assignment",
+	}
+	CannotGetScheduledAssignmentsErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "cannot get scheduled assignments",
+	}
+	CannotCancelScheduledAssignmentErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "cannot cancel scheduled assignment",
+	}
+)
+
+func NewScheduleController(service ScheduleService) *ScheduleController {
+	return &ScheduleController{
+		service: service,
+	}
+}
+
+func (sc *ScheduleController) SetupRouter(router fiber.Router) {
+	router.Post("/:code/:uuid/schedule", sc.Schedule)
+	router.Get("/:code/:uuid/schedule", sc.GetList)
+	router.Delete("/:code/:uuid/schedule/:id", sc.Cancel)
+}
+
+// @Summary Schedule status assignment
+// @Description Schedules the process to be moved into the status at the given time,
+// @Description the transition is validated when it is made and failure is recorded into the assignment
+// @Tags process
+// @Accept application/json
+// @Param	code	path	string					true	"Code of Process"
+// @Param	uuid	path	string					true	"UUID of Process"
+// @Param	request	body	model.ScheduledAssignmentDTO	true	"Status, payload, reason and run_at of the assignment"
+// @Produce json
+// @Success 201 {object} model.ScheduledAssignmentDTO
+// @Failed	400 {object} model.ProcessErrorResponse
+// @Failed	404 {object} model.ProcessErrorResponse
+// @Router /api/v1/process/{code}/{uuid}/schedule [post]
+func (sc *ScheduleController) Schedule(c *fiber.Ctx) error {
+	var assignment model.ScheduledAssignmentDTO
+	if err := c.BodyParser(&assignment); err != nil {
+		log.Error("cannot read request body ", err)
+		return c.Status(fiber.StatusBadRequest).JSON(CannotReadRequestBodyErrResp)
+	}
+
+	res, err := sc.service.Schedule(c.Context(), c.Params("code"), c.Params("uuid"), assignment, ActorFromContext(c))
+	if err != nil {
+		log.Error("cannot schedule status assignment ", err)
+		if errors.Is(err, ErrScheduleStatusRequired) {
+			return c.Status(fiber.StatusBadRequest).JSON(ScheduleStatusRequiredErrResp)
+		}
+		if errors.Is(err, ErrRunAtNotInFuture) {
+			return c.Status(fiber.StatusBadRequest).JSON(RunAtNotInFutureErrResp)
+		}
+		if errors.Is(err, ErrProcessNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ProcessNotFoundErrResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(CannotScheduleAssignmentErrResp)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
+
+// @Summary Get scheduled status assignments
+// @Description Get status assignments scheduled for the process in order they are to be made
+// @Tags process
+// @Param	code	path	string	true	"Code of Process"
+// @Param	uuid	path	string	true	"UUID of Process"
+// @Param	state	query	string	false	"State of the assignment: pending, done, cancelled, failed"
+// @Produce json
+// @Success 200 {object} model.ScheduledAssignmentListDTO
+// @Failed	400 {object} model.ProcessErrorResponse
+// @Router /api/v1/process/{code}/{uuid}/schedule [get]
+func (sc *ScheduleController) GetList(c *fiber.Ctx) error {
+	state := c.Query("state")
+	switch state {
+	case "", model.SCHEDULE_STATE_PENDING, model.SCHEDULE_STATE_DONE, model.SCHEDULE_STATE_CANCELLED, model.SCHEDULE_STATE_FAILED:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(NotSupportedScheduledAssignmentStateErrResp)
+	}
+
+	assignments, err := sc.service.List(c.Context(), c.Params("code"), c.Params("uuid"), state)
+	if err != nil {
+		log.Error("cannot get scheduled assignments ", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(CannotGetScheduledAssignmentsErrResp)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(assignments)
+}
+
+// @Summary Cancel scheduled status assignment
+// @Description Cancels the pending status assignment of the process
+// @Tags process
+// @Param	code	path	string	true	"Code of Process"
+// @Param	uuid	path	string	true	"UUID of Process"
+// @Param	id		path	int		true	"ID of the assignment"
+// @Success 204
+// @Failed	404 {object} model.ProcessErrorResponse
+// @Failed	409 {object} model.ProcessErrorResponse
+// @Router /api/v1/process/{code}/{uuid}/schedule/{id} [delete]
+func (sc *ScheduleController) Cancel(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(NotSupportedScheduledAssignmentIdErrResp)
+	}
+
+	err = sc.service.Cancel(c.Context(), c.Params("code"), c.Params("uuid"), uint(id))
+	if err != nil {
+		log.Error("cannot cancel scheduled assignment ", err)
+		if errors.Is(err, ErrScheduledAssignmentNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(ScheduledAssignmentNotFoundErrResp)
+		}
+		if errors.Is(err, ErrScheduledAssignmentNotPending) {
+			return c.Status(fiber.StatusConflict).JSON(ScheduledAssignmentNotPendingErrResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(CannotCancelScheduledAssignmentErrResp)
+	}
+
+	c.Status(fiber.StatusNoContent)
+	return nil
+}
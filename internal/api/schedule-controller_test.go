@@ -0,0 +1,288 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestScheduleAssignment(t *testing.T) {
+	runAt := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2023, 12, 8, 11, 33, 55, 0, time.UTC)
+	reqBody := `{"status": "in_progress", "reason": "work starts on Monday", "run_at": "2030-01-07T09:00:00Z", "payload": {"data": {"age": 42}}}`
+	wantAssignment := model.ScheduledAssignmentDTO{
+		Status:  "in_progress",
+		Reason:  "work starts on Monday",
+		RunAt:   &runAt,
+		Payload: model.Payload{"data": map[string]interface{}{"age": float64(42)}},
+	}
+	scheduled := wantAssignment
+	scheduled.ID = 42
+	scheduled.State = model.SCHEDULE_STATE_PENDING
+	scheduled.CreatedAt = &createdAt
+
+	tests := []struct {
+		name     string
+		body     string
+		mockFunc func() *ScheduleController
+		wantCode int
+		wantErr  *model.ProcessErrorResponse
+	}{
+		{
+			name: "success",
+			body: reqBody,
+			mockFunc: func() *ScheduleController {
+				service := ScheduleSrvcMock{}
+				service.On("Schedule", mock.Anything, "requests", "42", wantAssignment, model.Actor{}).
+					Return(scheduled, nil)
+				return NewScheduleController(&service)
+			},
+			wantCode: http.StatusCreated,
+		},
+		{
+			name: "fail - 400 - body",
+			body: `{"status": `,
+			mockFunc: func() *ScheduleController {
+				return NewScheduleController(&ScheduleSrvcMock{})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &CannotReadRequestBodyErrResp,
+		},
+		{
+			name: "fail - 400 - status is required",
+			body: reqBody,
+			mockFunc: func() *ScheduleController {
+				service := ScheduleSrvcMock{}
+				service.On("Schedule", mock.Anything, "requests", "42", wantAssignment, model.Actor{}).
+					Return(model.ScheduledAssignmentDTO{}, ErrScheduleStatusRequired)
+				return NewScheduleController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &ScheduleStatusRequiredErrResp,
+		},
+		{
+			name: "fail - 400 - run_at in the past",
+			body: reqBody,
+			mockFunc: func() *ScheduleController {
+				service := ScheduleSrvcMock{}
+				service.On("Schedule", mock.Anything, "requests", "42", wantAssignment, model.Actor{}).
+					Return(model.ScheduledAssignmentDTO{}, ErrRunAtNotInFuture)
+				return NewScheduleController(&service)
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &RunAtNotInFutureErrResp,
+		},
+		{
+			name: "fail - 404",
+			body: reqBody,
+			mockFunc: func() *ScheduleController {
+				service := ScheduleSrvcMock{}
+				service.On("Schedule", mock.Anything, "requests", "42", wantAssignment, model.Actor{}).
+					Return(model.ScheduledAssignmentDTO{}, ErrProcessNotFound)
+				return NewScheduleController(&service)
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  &ProcessNotFoundErrResp,
+		},
+		{
+			name: "fail - 500",
+			body: reqBody,
+			mockFunc: func() *ScheduleController {
+				service := ScheduleSrvcMock{}
+				service.On("Schedule", mock.Anything, "requests", "42", wantAssignment, model.Actor{}).
+					Return(model.ScheduledAssignmentDTO{}, errors.New("OMG error"))
+				return NewScheduleController(&service)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantErr:  &CannotScheduleAssignmentErrResp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testApp = fiber.New()
+			tt.mockFunc().SetupRouter(testApp.Group("/test"))
+
+			req := httptest.NewRequest("POST", "http://localhost/test/requests/42/schedule", strings.NewReader(tt.body))
+			req.Header.Add("Content-Type", "application/json")
+			resp, err := testApp.Test(req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.Nil(t, err)
+
+			if tt.wantErr != nil {
+				var gotResp model.ProcessErrorResponse
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, *tt.wantErr, gotResp)
+			} else {
+				var gotResp model.ScheduledAssignmentDTO
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, scheduled, gotResp)
+			}
+		})
+	}
+}
+
+func TestGetScheduledAssignmentList(t *testing.T) {
+	runAt := time.Date(2030, 1, 7, 9, 0, 0, 0, time.UTC)
+	assignments := model.ScheduledAssignmentListDTO{
+		{ID: 42, Status: "in_progress", RunAt: &runAt, State: model.SCHEDULE_STATE_FAILED, LastError: "not allowed status"},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		mockFunc func() *ScheduleController
+		wantCode int
+		wantResp model.ScheduledAssignmentListDTO
+		wantErr  *model.ProcessErrorResponse
+	}{
+		{
+			name:  "success",
+			query: "?state=failed",
+			mockFunc: func() *ScheduleController {
+				service := ScheduleSrvcMock{}
+				service.On("List", mock.Anything, "requests", "42", model.SCHEDULE_STATE_FAILED).
+					Return(assignments, nil)
+				return NewScheduleController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantResp: assignments,
+		},
+		{
+			name: "success - empty",
+			mockFunc: func() *ScheduleController {
+				service := ScheduleSrvcMock{}
+				service.On("List", mock.Anything, "requests", "42", "").
+					Return(model.ScheduledAssignmentListDTO{}, nil)
+				return NewScheduleController(&service)
+			},
+			wantCode: http.StatusOK,
+			wantResp: model.ScheduledAssignmentListDTO{},
+		},
+		{
+			name:  "fail - 400 - unknown state",
+			query: "?state=lost",
+			mockFunc: func() *ScheduleController {
+				return NewScheduleController(&ScheduleSrvcMock{})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedScheduledAssignmentStateErrResp,
+		},
+		{
+			name: "fail - 500",
+			mockFunc: func() *ScheduleController {
+				service := ScheduleSrvcMock{}
+				service.On("List", mock.Anything, "requests", "42", "").
+					Return(nil, errors.New("OMG error"))
+				return NewScheduleController(&service)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantErr:  &CannotGetScheduledAssignmentsErrResp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testApp = fiber.New()
+			tt.mockFunc().SetupRouter(testApp.Group("/test"))
+
+			req := httptest.NewRequest("GET", "http://localhost/test/requests/42/schedule"+tt.query, nil)
+			resp, err := testApp.Test(req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.Nil(t, err)
+
+			if tt.wantErr != nil {
+				var gotResp model.ProcessErrorResponse
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, *tt.wantErr, gotResp)
+			} else {
+				var gotResp model.ScheduledAssignmentListDTO
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, tt.wantResp, gotResp)
+			}
+		})
+	}
+}
+
+func TestCancelScheduledAssignment(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		mockErr  error
+		wantCode int
+		wantErr  *model.ProcessErrorResponse
+	}{
+		{
+			name:     "success",
+			id:       "7",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "fail - 400",
+			id:       "abc",
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedScheduledAssignmentIdErrResp,
+		},
+		{
+			name:     "fail - 404",
+			id:       "7",
+			mockErr:  ErrScheduledAssignmentNotFound,
+			wantCode: http.StatusNotFound,
+			wantErr:  &ScheduledAssignmentNotFoundErrResp,
+		},
+		{
+			name:     "fail - 409",
+			id:       "7",
+			mockErr:  ErrScheduledAssignmentNotPending,
+			wantCode: http.StatusConflict,
+			wantErr:  &ScheduledAssignmentNotPendingErrResp,
+		},
+		{
+			name:     "fail - 500",
+			id:       "7",
+			mockErr:  errors.New("OMG error"),
+			wantCode: http.StatusInternalServerError,
+			wantErr:  &CannotCancelScheduledAssignmentErrResp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := ScheduleSrvcMock{}
+			service.On("Cancel", mock.Anything, "requests", "42", uint(7)).Return(tt.mockErr)
+
+			var testApp = fiber.New()
+			NewScheduleController(&service).SetupRouter(testApp.Group("/test"))
+
+			req := httptest.NewRequest("DELETE", "http://localhost/test/requests/42/schedule/"+tt.id, nil)
+			resp, err := testApp.Test(req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			if tt.wantErr != nil {
+				body, err := io.ReadAll(resp.Body)
+				assert.Nil(t, err)
+				var gotResp model.ProcessErrorResponse
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, *tt.wantErr, gotResp)
+			}
+		})
+	}
+}
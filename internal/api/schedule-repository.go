@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type (
+	ScheduleRepository interface {
+		Create(ctx context.Context, assignment *model.ScheduledAssignment) error
+		Find(ctx context.Context, code string, uuid string, state string) ([]model.ScheduledAssignment, error)
+		Cancel(ctx context.Context, code string, uuid string, id uint) error
+		Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.ScheduledAssignment, error)
+		Finish(ctx context.Context, id uint, state string, lastError string, executedAt time.Time) error
+	}
+	ScheduleRepo struct {
+		db *gorm.DB
+	}
+)
+
+var (
+	ErrScheduledAssignmentNotFound   = errors.New("scheduled assignment not found")
+	ErrScheduledAssignmentNotPending = errors.New("scheduled assignment is not pending")
+)
+
+func NewScheduleRepository(db *gorm.DB) ScheduleRepository {
+	return &ScheduleRepo{
+		db: db,
+	}
+}
+
+func (r *ScheduleRepo) Create(ctx context.Context, assignment *model.ScheduledAssignment) error {
+	assignment.State = model.SCHEDULE_STATE_PENDING
+	return r.db.WithContext(ctx).Create(assignment).Error
+}
+
+// Find - returns assignments of the process in order they are to be made, empty state matches any
+func (r *ScheduleRepo) Find(ctx context.Context, code string, uuid string, state string) ([]model.ScheduledAssignment, error) {
+	stmt := r.db.WithContext(ctx).
+		Where("code = ? AND uuid = ?", code, uuid)
+	if len(state) > 0 {
+		stmt = stmt.Where("state = ?", state)
+	}
+
+	var assignments []model.ScheduledAssignment
+	err := stmt.Order("run_at, id").Find(&assignments).Error
+	return assignments, err
+}
+
+// Cancel - cancels the pending assignment of the process
+func (r *ScheduleRepo) Cancel(ctx context.Context, code string, uuid string, id uint) error {
+	var assignment model.ScheduledAssignment
+	err := r.db.WithContext(ctx).
+		Where("code = ? AND uuid = ?", code, uuid).
+		First(&assignment, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrScheduledAssignmentNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	res := r.db.WithContext(ctx).
+		Model(&model.ScheduledAssignment{}).
+		Where("id = ? AND state = ?", id, model.SCHEDULE_STATE_PENDING).
+		Update("state", model.SCHEDULE_STATE_CANCELLED)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrScheduledAssignmentNotPending
+	}
+	return nil
+}
+
+// Claim - returns pending assignments due at the given time and locks them for the lease,
+// so other workers skip them while they are being made
+func (r *ScheduleRepo) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.ScheduledAssignment, error) {
+	var due []model.ScheduledAssignment
+	err := r.db.WithContext(ctx).
+		Where("state = ? AND run_at <= ? AND locked_until <= ?", model.SCHEDULE_STATE_PENDING, now, now).
+		Order("run_at, id").
+		Limit(limit).
+		Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []model.ScheduledAssignment
+	for _, assignment := range due {
+		res := r.db.WithContext(ctx).
+			Model(&model.ScheduledAssignment{}).
+			Where("id = ? AND state = ? AND locked_until <= ?", assignment.ID, model.SCHEDULE_STATE_PENDING, now).
+			Update("locked_until", now.Add(lease))
+		if res.Error != nil {
+			return claimed, res.Error
+		}
+		// claimed by someone else
+		if res.RowsAffected == 0 {
+			continue
+		}
+		claimed = append(claimed, assignment)
+	}
+	return claimed, nil
+}
+
+// Finish - sets the final state of the assignment
+func (r *ScheduleRepo) Finish(ctx context.Context, id uint, state string, lastError string, executedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&model.ScheduledAssignment{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"state":       state,
+			"last_error":  lastError,
+			"executed_at": executedAt,
+		}).Error
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ScheduleRepoMock struct {
+	mock.Mock
+}
+
+func (r *ScheduleRepoMock) Create(ctx context.Context, assignment *model.ScheduledAssignment) error {
+	args := r.Called(ctx, assignment)
+	return args.Error(0)
+}
+func (r *ScheduleRepoMock) Find(ctx context.Context, code string, uuid string, state string) ([]model.ScheduledAssignment, error) {
+	args := r.Called(ctx, code, uuid, state)
+	res := args.Get(0)
+	if res != nil {
+		return res.([]model.ScheduledAssignment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (r *ScheduleRepoMock) Cancel(ctx context.Context, code string, uuid string, id uint) error {
+	args := r.Called(ctx, code, uuid, id)
+	return args.Error(0)
+}
+func (r *ScheduleRepoMock) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.ScheduledAssignment, error) {
+	args := r.Called(ctx, now, lease, limit)
+	res := args.Get(0)
+	if res != nil {
+		return res.([]model.ScheduledAssignment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (r *ScheduleRepoMock) Finish(ctx context.Context, id uint, state string, lastError string, executedAt time.Time) error {
+	args := r.Called(ctx, id, state, lastError, executedAt)
+	return args.Error(0)
+}
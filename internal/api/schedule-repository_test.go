@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestScheduleRepo_Claim(t *testing.T) {
+	// run_at given in other time zone than the local one of the worker
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	now := time.Now()
+	runAt := now.Add(time.Hour).UTC()
+	tests := []struct {
+		name        string
+		claimAt     time.Time
+		wantClaimed int
+	}{
+		{
+			name:    "not due yet",
+			claimAt: now,
+		},
+		{
+			name:        "due",
+			claimAt:     now.Add(2 * time.Hour),
+			wantClaimed: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachDB(t, func(t *testing.T, db *gorm.DB) {
+				ctx := context.Background()
+				processes := NewProcessRepository(db)
+				repo := NewScheduleRepository(db)
+				process := testProcess(t, processes, "0d1c5a3e-2b4f-4c6d-8e9f-a1b2c3d4e5f6")
+
+				_, err := NewScheduleService(repo, processes).Schedule(ctx, process.Code, process.UUID,
+					model.ScheduledAssignmentDTO{Status: "approved", RunAt: &runAt}, model.Actor{ID: "alex"})
+				assert.NoError(t, err)
+
+				claimed, err := repo.Claim(ctx, tt.claimAt, scheduleLease, scheduleBatchSize)
+				assert.NoError(t, err)
+				assert.Len(t, claimed, tt.wantClaimed)
+
+				// the claimed assignment is locked for the lease
+				locked, err := repo.Claim(ctx, tt.claimAt, scheduleLease, scheduleBatchSize)
+				assert.NoError(t, err)
+				assert.Empty(t, locked)
+
+				// and is claimed again once the lease is over unless it is finished
+				leaseOver := tt.claimAt.Add(scheduleLease)
+				reclaimed, err := repo.Claim(ctx, leaseOver, scheduleLease, scheduleBatchSize)
+				assert.NoError(t, err)
+				assert.Len(t, reclaimed, tt.wantClaimed)
+				for _, assignment := range claimed {
+					assert.NoError(t, repo.Finish(ctx, assignment.ID, model.SCHEDULE_STATE_DONE, "", leaseOver))
+				}
+				finished, err := repo.Claim(ctx, leaseOver.Add(scheduleLease), scheduleLease, scheduleBatchSize)
+				assert.NoError(t, err)
+				assert.Empty(t, finished)
+			})
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type (
+	ScheduleService interface {
+		Schedule(ctx context.Context, code string, uuid string, assignment model.ScheduledAssignmentDTO, actor model.Actor) (model.ScheduledAssignmentDTO, error)
+		List(ctx context.Context, code string, uuid string, state string) (model.ScheduledAssignmentListDTO, error)
+		Cancel(ctx context.Context, code string, uuid string, id uint) error
+	}
+	ScheduleSrvc struct {
+		repo      ScheduleRepository
+		processes ProcessRepository
+		now       func() time.Time
+	}
+)
+
+var (
+	ErrScheduleStatusRequired = errors.New("status is required")
+	ErrRunAtNotInFuture       = errors.New("run_at has to be in the future")
+)
+
+func NewScheduleService(repo ScheduleRepository, processes ProcessRepository) ScheduleService {
+	return &ScheduleSrvc{
+		repo:      repo,
+		processes: processes,
+		now:       time.Now,
+	}
+}
+
+// Schedule - saves the status assignment to be made at the given time on behalf of the actor.
+// The transition is validated when the assignment is made, so it may be scheduled ahead of the process being ready for it
+func (s *ScheduleSrvc) Schedule(ctx context.Context, code string, uuid string, assignment model.ScheduledAssignmentDTO, actor model.Actor) (model.ScheduledAssignmentDTO, error) {
+	if len(strings.TrimSpace(assignment.Status)) == 0 {
+		return model.ScheduledAssignmentDTO{}, ErrScheduleStatusRequired
+	}
+	if assignment.RunAt == nil || !assignment.RunAt.After(s.now()) {
+		return model.ScheduledAssignmentDTO{}, ErrRunAtNotInFuture
+	}
+
+	process, err := s.processes.GetByUUID(ctx, code, uuid)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.ScheduledAssignmentDTO{}, ErrProcessNotFound
+		}
+		return model.ScheduledAssignmentDTO{}, err
+	}
+
+	entity := &model.ScheduledAssignment{
+		ProcessID:      process.ID,
+		Code:           process.Code,
+		UUID:           process.UUID,
+		Status:         strings.TrimSpace(assignment.Status),
+		Payload:        datatypes.JSON(assignment.Payload.ToBytes()),
+		Reason:         strings.TrimSpace(assignment.Reason),
+		RunAt:          assignment.RunAt.Local(),
+		CreatedBy:      actor.ID,
+		CreatedByRoles: actor.Roles,
+	}
+	if err := s.repo.Create(ctx, entity); err != nil {
+		return model.ScheduledAssignmentDTO{}, err
+	}
+	return entity.ToDTO(), nil
+}
+
+// List - returns the assignments of the process in order they are to be made
+func (s *ScheduleSrvc) List(ctx context.Context, code string, uuid string, state string) (model.ScheduledAssignmentListDTO, error) {
+	assignments, err := s.repo.Find(ctx, code, uuid, state)
+	if err != nil {
+		return nil, err
+	}
+	return model.ScheduledAssignmentList(assignments).ToDTO(), nil
+}
+
+// Cancel - cancels the pending assignment of the process
+func (s *ScheduleSrvc) Cancel(ctx context.Context, code string, uuid string, id uint) error {
+	return s.repo.Cancel(ctx, code, uuid, id)
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"context"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ScheduleSrvcMock struct {
+	mock.Mock
+}
+
+func (s *ScheduleSrvcMock) Schedule(ctx context.Context, code string, uuid string, assignment model.ScheduledAssignmentDTO, actor model.Actor) (model.ScheduledAssignmentDTO, error) {
+	args := s.Called(ctx, code, uuid, assignment, actor)
+	return args.Get(0).(model.ScheduledAssignmentDTO), args.Error(1)
+}
+func (s *ScheduleSrvcMock) List(ctx context.Context, code string, uuid string, state string) (model.ScheduledAssignmentListDTO, error) {
+	args := s.Called(ctx, code, uuid, state)
+	res := args.Get(0)
+	if res != nil {
+		return res.(model.ScheduledAssignmentListDTO), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (s *ScheduleSrvcMock) Cancel(ctx context.Context, code string, uuid string, id uint) error {
+	args := s.Called(ctx, code, uuid, id)
+	return args.Error(0)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	log "github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	schedulePollInterval = 5 * time.Second
+	scheduleBatchSize    = 50
+	scheduleLease        = time.Minute
+)
+
+type (
+	// ScheduleWorker - makes the scheduled status assignments when they are due.
+	// The assignment interrupted by the engine stop is made again once its lease is over
+	ScheduleWorker struct {
+		repo    ScheduleRepository
+		service ProcessService
+		now     func() time.Time
+	}
+)
+
+func NewScheduleWorker(repo ScheduleRepository, service ProcessService) *ScheduleWorker {
+	return &ScheduleWorker{
+		repo:    repo,
+		service: service,
+		now:     time.Now,
+	}
+}
+
+// Run - polls the due assignments until the context is done
+func (w *ScheduleWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(schedulePollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.RunDue(ctx); err != nil {
+			log.Error("cannot run scheduled assignments ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue - makes the due assignments, returns number of processed assignments.
+// The assignment failed with unexpected error is logged and does not stop the rest of the batch
+func (w *ScheduleWorker) RunDue(ctx context.Context) (int, error) {
+	assignments, err := w.repo.Claim(ctx, w.now(), scheduleLease, scheduleBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range assignments {
+		// the rest is claimed again once the lease is over
+		if ctx.Err() != nil {
+			return i, nil
+		}
+		// the started assignment is not cancelled with the context, so it is finished on shutdown
+		if err := w.execute(context.Background(), assignments[i]); err != nil {
+			log.Errorf("cannot run scheduled assignment %d of process %s %s: %v", assignments[i].ID, assignments[i].Code, assignments[i].UUID, err)
+		}
+	}
+	return len(assignments), nil
+}
+
+// execute - moves the process on behalf of the actor scheduled the assignment, the transition is validated now.
+// The assignment stays pending on unexpected error and is made again once the lease is over
+func (w *ScheduleWorker) execute(ctx context.Context, assignment model.ScheduledAssignment) error {
+	ctx = ContextWithRequestInfo(ctx, RequestInfo{RequestID: fmt.Sprintf("schedule-%d", assignment.ID)})
+	status := model.ProcessStatusDTO{
+		Name:    assignment.Status,
+		Payload: model.ToDTO(assignment.Payload),
+		Reason:  assignment.Reason,
+	}
+
+	err := w.service.AssignStatus(ctx, assignment.Code, assignment.UUID, status, 0, assignment.Actor())
+	switch {
+	case err == nil:
+		return w.repo.Finish(ctx, assignment.ID, model.SCHEDULE_STATE_DONE, "", w.now())
+	case errors.Is(err, ErrVersionConflict):
+		// the process is changed concurrently, the assignment is made again once the lease is over
+		return nil
+	case errors.Is(err, ErrProcessNotFound), isTransitionRejected(err):
+		log.Errorf("scheduled assignment %d of process %s %s failed: %v", assignment.ID, assignment.Code, assignment.UUID, err)
+		return w.repo.Finish(ctx, assignment.ID, model.SCHEDULE_STATE_FAILED, err.Error(), w.now())
+	}
+	return err
+}
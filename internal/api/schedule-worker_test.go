@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+	"github.com/alex-bezverkhniy/bp-engine/internal/validators"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestScheduleWorker_RunDue(t *testing.T) {
+	now := time.Date(2023, 12, 11, 9, 0, 0, 0, time.UTC)
+	actor := model.Actor{ID: "alex", Roles: []string{"manager"}}
+	wantStatus := model.ProcessStatusDTO{
+		Name:    "in_progress",
+		Payload: model.Payload{"data": map[string]interface{}{"age": float64(42)}},
+		Reason:  "work starts on Monday",
+	}
+
+	tests := []struct {
+		name      string
+		assignErr error
+		wantState string
+		wantError string
+	}{
+		{
+			name:      "done",
+			wantState: model.SCHEDULE_STATE_DONE,
+		},
+		{
+			name:      "failed - transition is not allowed now",
+			assignErr: validators.ErrNotAllowedStatus,
+			wantState: model.SCHEDULE_STATE_FAILED,
+			wantError: "not allowed status",
+		},
+		{
+			name:      "failed - process is not found",
+			assignErr: ErrProcessNotFound,
+			wantState: model.SCHEDULE_STATE_FAILED,
+			wantError: "process not found",
+		},
+		{
+			name:      "process is changed concurrently - stays pending",
+			assignErr: ErrVersionConflict,
+		},
+		{
+			name:      "DB error - stays pending",
+			assignErr: errors.New("OMG error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assignment := model.ScheduledAssignment{
+				Code:           "requests",
+				UUID:           "42",
+				Status:         "in_progress",
+				Payload:        []byte(`{"data": {"age": 42}}`),
+				Reason:         "work starts on Monday",
+				RunAt:          now,
+				CreatedBy:      actor.ID,
+				CreatedByRoles: actor.Roles,
+			}
+			assignment.ID = 7
+
+			repo := ScheduleRepoMock{}
+			repo.On("Claim", mock.Anything, now, scheduleLease, scheduleBatchSize).
+				Return([]model.ScheduledAssignment{assignment}, nil)
+			if len(tt.wantState) > 0 {
+				repo.On("Finish", mock.Anything, uint(7), tt.wantState, tt.wantError, now).
+					Return(nil)
+			}
+			service := ProcessSrvcMock{}
+			service.On("AssignStatus", mock.Anything, "requests", "42", wantStatus, uint(0), actor).
+				Return(tt.assignErr)
+
+			worker := NewScheduleWorker(&repo, &service)
+			worker.now = func() time.Time { return now }
+
+			gotCount, err := worker.RunDue(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 1, gotCount)
+			repo.AssertExpectations(t)
+			service.AssertExpectations(t)
+
+			gotCtx := service.Calls[0].Arguments.Get(0).(context.Context)
+			assert.Equal(t, "schedule-7", RequestInfoFromContext(gotCtx).RequestID)
+		})
+	}
+}
+
+func TestScheduleWorker_RunDue_ContinuesOnError(t *testing.T) {
+	now := time.Date(2023, 12, 11, 9, 0, 0, 0, time.UTC)
+	broken := model.ScheduledAssignment{Code: "requests", UUID: "1", Status: "in_progress", RunAt: now}
+	broken.ID = 1
+	next := model.ScheduledAssignment{Code: "requests", UUID: "2", Status: "in_progress", RunAt: now}
+	next.ID = 2
+
+	repo := ScheduleRepoMock{}
+	repo.On("Claim", mock.Anything, now, scheduleLease, scheduleBatchSize).
+		Return([]model.ScheduledAssignment{broken, next}, nil)
+	repo.On("Finish", mock.Anything, uint(2), model.SCHEDULE_STATE_DONE, "", now).
+		Return(nil)
+	service := ProcessSrvcMock{}
+	service.On("AssignStatus", mock.Anything, "requests", "1", mock.Anything, uint(0), mock.Anything).
+		Return(errors.New("OMG error"))
+	service.On("AssignStatus", mock.Anything, "requests", "2", mock.Anything, uint(0), mock.Anything).
+		Return(nil)
+
+	worker := NewScheduleWorker(&repo, &service)
+	worker.now = func() time.Time { return now }
+
+	gotCount, err := worker.RunDue(context.Background())
+	assert.Nil(t, err)
+	// the broken assignment stays pending, the next one is made
+	assert.Equal(t, 2, gotCount)
+	repo.AssertExpectations(t)
+	service.AssertExpectations(t)
+}
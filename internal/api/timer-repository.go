@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type (
+	TimerRepository interface {
+		Due(ctx context.Context, now time.Time, limit int) ([]model.ProcessTimer, error)
+		Finish(ctx context.Context, id uint, state string, lastError string) error
+		Cancel(ctx context.Context, id uint) error
+	}
+	TimerRepo struct {
+		db *gorm.DB
+	}
+)
+
+func NewTimerRepository(db *gorm.DB) TimerRepository {
+	return &TimerRepo{
+		db: db,
+	}
+}
+
+// Due - returns pending timers due at the given time, the earliest first
+func (r *TimerRepo) Due(ctx context.Context, now time.Time, limit int) ([]model.ProcessTimer, error) {
+	var timers []model.ProcessTimer
+	err := r.db.WithContext(ctx).
+		Where("state = ? AND due_at <= ?", model.TIMER_STATE_PENDING, now).
+		Order("due_at, id").
+		Limit(limit).
+		Find(&timers).Error
+	return timers, err
+}
+
+// Finish - sets the final state of the timer
+func (r *TimerRepo) Finish(ctx context.Context, id uint, state string, lastError string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.ProcessTimer{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"state":      state,
+			"last_error": lastError,
+		}).Error
+}
+
+// Cancel - cancels the timer unless it is already finished
+func (r *TimerRepo) Cancel(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&model.ProcessTimer{}).
+		Where("id = ? AND state = ?", id, model.TIMER_STATE_PENDING).
+		Update("state", model.TIMER_STATE_CANCELLED).Error
+}
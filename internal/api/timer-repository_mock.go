@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type TimerRepoMock struct {
+	mock.Mock
+}
+
+func (r *TimerRepoMock) Due(ctx context.Context, now time.Time, limit int) ([]model.ProcessTimer, error) {
+	args := r.Called(ctx, now, limit)
+	res := args.Get(0)
+	if res != nil {
+		return res.([]model.ProcessTimer), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+func (r *TimerRepoMock) Finish(ctx context.Context, id uint, state string, lastError string) error {
+	args := r.Called(ctx, id, state, lastError)
+	return args.Error(0)
+}
+func (r *TimerRepoMock) Cancel(ctx context.Context, id uint) error {
+	args := r.Called(ctx, id)
+	return args.Error(0)
+}
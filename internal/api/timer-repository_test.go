@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestTimerRepo_Due(t *testing.T) {
+	forEachDB(t, func(t *testing.T, db *gorm.DB) {
+		ctx := context.Background()
+		now := time.Now()
+		processes := NewProcessRepository(db)
+		repo := NewTimerRepository(db)
+
+		timers := map[string]*model.ProcessTimer{
+			"due":      {OnTimeout: "expired", DueAt: now.Add(-time.Minute)},
+			"not due":  {OnTimeout: "expired", DueAt: now.Add(time.Hour)},
+			"replaced": {OnTimeout: "expired", DueAt: now.Add(-2 * time.Minute)},
+		}
+		for uuid, timer := range timers {
+			process := testProcess(t, processes, uuid)
+			assert.NoError(t, processes.ResetTimers(ctx, process.ID, timer))
+		}
+		// the timer of the new status cancels the pending one
+		earliest := &model.ProcessTimer{OnTimeout: "expired", DueAt: now.Add(-3 * time.Minute)}
+		assert.NoError(t, processes.ResetTimers(ctx, timers["replaced"].ProcessID, earliest))
+
+		due, err := repo.Due(ctx, now, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{earliest.ID, timers["due"].ID}, timerIDs(due))
+
+		due, err = repo.Due(ctx, now, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{earliest.ID}, timerIDs(due))
+
+		// the finished timer is not cancelled
+		assert.NoError(t, repo.Finish(ctx, earliest.ID, model.TIMER_STATE_FIRED, ""))
+		assert.NoError(t, repo.Cancel(ctx, earliest.ID))
+		assert.NoError(t, repo.Cancel(ctx, timers["due"].ID))
+
+		due, err = repo.Due(ctx, now, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, due)
+
+		want := map[uint]string{
+			earliest.ID:           model.TIMER_STATE_FIRED,
+			timers["due"].ID:      model.TIMER_STATE_CANCELLED,
+			timers["not due"].ID:  model.TIMER_STATE_PENDING,
+			timers["replaced"].ID: model.TIMER_STATE_CANCELLED,
+		}
+		for id, wantState := range want {
+			var timer model.ProcessTimer
+			assert.NoError(t, db.First(&timer, id).Error)
+			assert.Equal(t, wantState, timer.State, id)
+		}
+	})
+}
+
+func timerIDs(timers []model.ProcessTimer) []uint {
+	var ids []uint
+	for _, timer := range timers {
+		ids = append(ids, timer.ID)
+	}
+	return ids
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+	"github.com/alex-bezverkhniy/bp-engine/internal/validators"
+
+	log "github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	timerPollInterval = 5 * time.Second
+	timerBatchSize    = 50
+)
+
+type (
+	// TimerScheduler - moves the processes staying in the status longer than its timeout into the on_timeout status.
+	// The timers are kept in the DB, so the ones due while the engine was stopped are fired on start
+	TimerScheduler struct {
+		repo    TimerRepository
+		service ProcessService
+		now     func() time.Time
+	}
+)
+
+func NewTimerScheduler(repo TimerRepository, service ProcessService) *TimerScheduler {
+	return &TimerScheduler{
+		repo:    repo,
+		service: service,
+		now:     time.Now,
+	}
+}
+
+// Run - polls the due timers until the context is done
+func (s *TimerScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(timerPollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.FireDue(ctx); err != nil {
+			log.Error("cannot fire process timers ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// FireDue - fires the due timers, returns number of processed timers.
+// The timer failed with unexpected error is logged and does not stop the rest of the batch.
+// Once the context is done the rest of timers is left for the next run
+func (s *TimerScheduler) FireDue(ctx context.Context) (int, error) {
+	timers, err := s.repo.Due(ctx, s.now(), timerBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range timers {
+		if ctx.Err() != nil {
+			return i, nil
+		}
+		// the started timer is not cancelled with the context, so it is finished on shutdown
+		if err := s.fire(context.Background(), timers[i]); err != nil {
+			log.Errorf("cannot fire timer %d of process %s %s: %v", timers[i].ID, timers[i].Code, timers[i].UUID, err)
+		}
+	}
+	return len(timers), nil
+}
+
+// fire - moves the process on behalf of the system actor through the same checks as the API does.
+// The timer stays pending on unexpected error and is fired again on the next poll
+func (s *TimerScheduler) fire(ctx context.Context, timer model.ProcessTimer) error {
+	ctx = ContextWithRequestInfo(ctx, RequestInfo{RequestID: fmt.Sprintf("timer-%d", timer.ID)})
+	status := model.ProcessStatusDTO{
+		Name:   timer.OnTimeout,
+		Reason: fmt.Sprintf("timeout of status %s", timer.Status),
+	}
+
+	err := s.service.AssignStatus(ctx, timer.Code, timer.UUID, status, timer.Version, model.SystemActor)
+	switch {
+	case err == nil:
+		return s.repo.Finish(ctx, timer.ID, model.TIMER_STATE_FIRED, "")
+	case errors.Is(err, ErrVersionConflict), errors.Is(err, ErrProcessNotFound):
+		// the process has left the status, the timer is usually cancelled by that change already
+		return s.repo.Cancel(ctx, timer.ID)
+	case isTransitionRejected(err):
+		log.Errorf("process %s %s cannot be moved on timeout of status %s: %v", timer.Code, timer.UUID, timer.Status, err)
+		return s.repo.Finish(ctx, timer.ID, model.TIMER_STATE_FAILED, err.Error())
+	}
+	return err
+}
+
+// isTransitionRejected - the transition is rejected by the config or the hooks, so retrying it makes no sense
+func isTransitionRejected(err error) bool {
+	for _, target := range []error{
+		validators.ErrUnknownStatus,
+		validators.ErrNotAllowedStatus,
+		validators.ErrFinalStatus,
+		validators.ErrReasonRequired,
+		validators.ErrForbiddenTransition,
+		validators.ErrGuardFailed,
+		validators.ErrPayloadValidation,
+		ErrStatusChangeVetoed,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
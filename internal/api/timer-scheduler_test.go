@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+	"github.com/alex-bezverkhniy/bp-engine/internal/validators"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTimerScheduler_FireDue(t *testing.T) {
+	now := time.Date(2023, 12, 8, 11, 33, 55, 0, time.UTC)
+	wantStatus := model.ProcessStatusDTO{Name: "expired", Reason: "timeout of status open"}
+
+	tests := []struct {
+		name       string
+		assignErr  error
+		wantState  string
+		wantError  string
+		wantCancel bool
+	}{
+		{
+			name:      "fired",
+			wantState: model.TIMER_STATE_FIRED,
+		},
+		{
+			name:       "process has left the status",
+			assignErr:  ErrVersionConflict,
+			wantCancel: true,
+		},
+		{
+			name:       "process is not found",
+			assignErr:  ErrProcessNotFound,
+			wantCancel: true,
+		},
+		{
+			name:      "failed - vetoed by hook",
+			assignErr: fmt.Errorf("%w: %w", ErrStatusChangeVetoed, errors.New("manager is on vacation")),
+			wantState: model.TIMER_STATE_FAILED,
+			wantError: "status change is vetoed: manager is on vacation",
+		},
+		{
+			name:      "failed - guard is not satisfied",
+			assignErr: &validators.GuardError{Status: "expired", Guard: "payload.amount < 100"},
+			wantState: model.TIMER_STATE_FAILED,
+			wantError: `transition guard is not satisfied: status "expired" requires payload.amount < 100`,
+		},
+		{
+			name:      "DB error - stays pending",
+			assignErr: errors.New("OMG error"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timer := model.ProcessTimer{Code: "requests", UUID: "42", Version: 3, Status: "open", OnTimeout: "expired", DueAt: now}
+			timer.ID = 7
+
+			repo := TimerRepoMock{}
+			repo.On("Due", mock.Anything, now, timerBatchSize).
+				Return([]model.ProcessTimer{timer}, nil)
+			if len(tt.wantState) > 0 {
+				repo.On("Finish", mock.Anything, uint(7), tt.wantState, tt.wantError).
+					Return(nil)
+			}
+			if tt.wantCancel {
+				repo.On("Cancel", mock.Anything, uint(7)).
+					Return(nil)
+			}
+			service := ProcessSrvcMock{}
+			service.On("AssignStatus", mock.Anything, "requests", "42", wantStatus, uint(3), model.SystemActor).
+				Return(tt.assignErr)
+
+			scheduler := NewTimerScheduler(&repo, &service)
+			scheduler.now = func() time.Time { return now }
+
+			gotCount, err := scheduler.FireDue(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 1, gotCount)
+			repo.AssertExpectations(t)
+			service.AssertExpectations(t)
+
+			gotCtx := service.Calls[0].Arguments.Get(0).(context.Context)
+			assert.Equal(t, "timer-7", RequestInfoFromContext(gotCtx).RequestID)
+		})
+	}
+}
+
+func TestTimerScheduler_FireDue_ContinuesOnError(t *testing.T) {
+	now := time.Date(2023, 12, 8, 11, 33, 55, 0, time.UTC)
+	broken := model.ProcessTimer{UUID: "1", OnTimeout: "expired"}
+	broken.ID = 1
+	next := model.ProcessTimer{UUID: "2", OnTimeout: "expired"}
+	next.ID = 2
+
+	repo := TimerRepoMock{}
+	repo.On("Due", mock.Anything, now, timerBatchSize).
+		Return([]model.ProcessTimer{broken, next}, nil)
+	repo.On("Finish", mock.Anything, uint(2), model.TIMER_STATE_FIRED, "").
+		Return(nil)
+	service := ProcessSrvcMock{}
+	service.On("AssignStatus", mock.Anything, mock.Anything, "1", mock.Anything, mock.Anything, model.SystemActor).
+		Return(errors.New("OMG error"))
+	service.On("AssignStatus", mock.Anything, mock.Anything, "2", mock.Anything, mock.Anything, model.SystemActor).
+		Return(nil)
+
+	scheduler := NewTimerScheduler(&repo, &service)
+	scheduler.now = func() time.Time { return now }
+
+	gotCount, err := scheduler.FireDue(context.Background())
+	assert.Nil(t, err)
+	// the broken timer stays pending, the next one is fired
+	assert.Equal(t, 2, gotCount)
+	repo.AssertExpectations(t)
+	service.AssertExpectations(t)
+}
+
+func TestTimerScheduler_FireDue_Shutdown(t *testing.T) {
+	now := time.Date(2023, 12, 8, 11, 33, 55, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	repo := TimerRepoMock{}
+	repo.On("Due", mock.Anything, now, timerBatchSize).
+		Return([]model.ProcessTimer{{OnTimeout: "expired"}, {OnTimeout: "expired"}}, nil)
+	repo.On("Finish", mock.Anything, mock.Anything, model.TIMER_STATE_FIRED, "").
+		Return(nil)
+	service := ProcessSrvcMock{}
+	// shutdown while the first timer is being fired
+	service.On("AssignStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, model.SystemActor).
+		Run(func(args mock.Arguments) { cancel() }).
+		Return(nil).Once()
+
+	scheduler := NewTimerScheduler(&repo, &service)
+	scheduler.now = func() time.Time { return now }
+
+	gotCount, err := scheduler.FireDue(ctx)
+	assert.Nil(t, err)
+	// the started timer is finished, the next one is left pending
+	assert.Equal(t, 1, gotCount)
+	assert.Nil(t, service.Calls[0].Arguments.Get(0).(context.Context).Err())
+	repo.AssertExpectations(t)
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	log "github.com/gofiber/fiber/v2/log"
+)
+
+type (
+	WebhookController struct {
+		repo WebhookRepository
+	}
+)
+
+var (
+	WebhookEventNotFoundErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "webhook event not found",
+	}
+	WebhookEventNotDeadErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "only dead webhook events can be retried",
+	}
+	NotSupportedWebhookEventIdErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "not supported webhook event id",
+	}
+	NotSupportedWebhookEventStateErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "not supported webhook event state",
+	}
+	CannotGetWebhookEventsErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "cannot get webhook events",
+	}
+	CannotRetryWebhookEventErrResp = model.ProcessErrorResponse{
+		Status:  "error",
+		Message: "cannot retry webhook event",
+	}
+)
+
+func NewWebhookController(repo WebhookRepository) *WebhookController {
+	return &WebhookController{
+		repo: repo,
+	}
+}
+
+func (wc *WebhookController) SetupRouter(router fiber.Router) {
+	router.Get("/events", wc.GetList)
+	router.Get("/events/:id", wc.Get)
+	router.Post("/events/:id/retry", wc.Retry)
+}
+
+// @Summary Get list of webhook events
+// @Description Get events of the webhook outbox, the latest first
+// @Tags admin
+// @Param	state		query	string	false	"State of the event: pending, delivered, dead"
+// @Param	webhook		query	string	false	"Name of the webhook"
+// @Param	page		query	int		false	"Page number"
+// @Param	page_size	query	int		false	"Page size"
+// @Produce json
+// @Success 200 {object} model.WebhookEventListDTO
+// @Failed	400 {object} model.ProcessErrorResponse
+// @Failed	403 {object} model.ProcessErrorResponse
+// @Router /api/v1/admin/webhooks/events [get]
+func (wc *WebhookController) GetList(c *fiber.Ctx) error {
+	state := c.Query("state")
+	switch state {
+	case "", model.WEBHOOK_STATE_PENDING, model.WEBHOOK_STATE_DELIVERED, model.WEBHOOK_STATE_DEAD:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(NotSupportedWebhookEventStateErrResp)
+	}
+
+	page, err := strconv.Atoi(c.Query(QUERYPARAM_PAGE, strconv.Itoa(DEFAULT_PAGE)))
+	if err != nil || page <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(NotSupportedValueForPageHdrErrResp)
+	}
+	pageSize, err := strconv.Atoi(c.Query(QUERYPARAM_PAGE_SIZE, strconv.Itoa(DEFAULT_PAGE_SIZE)))
+	if err != nil || pageSize <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(NotSupportedValueForPageSizeHdrErrResp)
+	}
+
+	events, err := wc.repo.Find(c.Context(), state, c.Query("webhook"), page, pageSize)
+	if err != nil {
+		log.Error("cannot get webhook events ", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(CannotGetWebhookEventsErrResp)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(model.WebhookEventList(events).ToDTO())
+}
+
+// @Summary Get webhook event
+// @Description Get webhook event with its delivery attempts
+// @Tags admin
+// @Param	id	path	int	true	"ID of the event"
+// @Produce json
+// @Success 200 {object} model.WebhookEventDTO
+// @Failed	403 {object} model.ProcessErrorResponse
+// @Failed	404 {object} model.ProcessErrorResponse
+// @Router /api/v1/admin/webhooks/events/{id} [get]
+func (wc *WebhookController) Get(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(NotSupportedWebhookEventIdErrResp)
+	}
+
+	event, err := wc.repo.GetByID(c.Context(), uint(id))
+	if err != nil {
+		log.Error("cannot get webhook event ", err)
+		if errors.Is(err, ErrWebhookEventNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(WebhookEventNotFoundErrResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(CannotGetWebhookEventsErrResp)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(event.ToDTO())
+}
+
+// @Summary Retry dead webhook event
+// @Description Moves the dead event back to the pending ones, delivery attempts start over
+// @Tags admin
+// @Param	id	path	int	true	"ID of the event"
+// @Success 204
+// @Failed	403 {object} model.ProcessErrorResponse
+// @Failed	404 {object} model.ProcessErrorResponse
+// @Failed	409 {object} model.ProcessErrorResponse
+// @Router /api/v1/admin/webhooks/events/{id}/retry [post]
+func (wc *WebhookController) Retry(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(NotSupportedWebhookEventIdErrResp)
+	}
+
+	err = wc.repo.Retry(c.Context(), uint(id), time.Now())
+	if err != nil {
+		log.Error("cannot retry webhook event ", err)
+		if errors.Is(err, ErrWebhookEventNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(WebhookEventNotFoundErrResp)
+		}
+		if errors.Is(err, ErrWebhookEventNotDead) {
+			return c.Status(fiber.StatusConflict).JSON(WebhookEventNotDeadErrResp)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(CannotRetryWebhookEventErrResp)
+	}
+
+	c.Status(fiber.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	fiber "github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetWebhookEventList(t *testing.T) {
+	createdAt := time.Date(2023, 12, 8, 11, 33, 55, 0, time.UTC)
+	event := model.WebhookEvent{Webhook: "billing", Type: model.EVENT_PROCESS_SUBMITTED, State: model.WEBHOOK_STATE_DEAD, Attempts: 10, NextAttemptAt: createdAt}
+	event.ID = 42
+	event.CreatedAt = createdAt
+
+	tests := []struct {
+		name     string
+		query    string
+		mockFunc func() *WebhookController
+		wantCode int
+		wantResp model.WebhookEventListDTO
+		wantErr  *model.ProcessErrorResponse
+	}{
+		{
+			name:  "success",
+			query: "?state=dead&webhook=billing&page=2&page_size=5",
+			mockFunc: func() *WebhookController {
+				repo := WebhookRepoMock{}
+				repo.On("Find", mock.Anything, model.WEBHOOK_STATE_DEAD, "billing", 2, 5).
+					Return([]model.WebhookEvent{event}, nil)
+				return NewWebhookController(&repo)
+			},
+			wantCode: http.StatusOK,
+			wantResp: model.WebhookEventListDTO{event.ToDTO()},
+		},
+		{
+			name: "success - empty",
+			mockFunc: func() *WebhookController {
+				repo := WebhookRepoMock{}
+				repo.On("Find", mock.Anything, "", "", DEFAULT_PAGE, DEFAULT_PAGE_SIZE).
+					Return([]model.WebhookEvent{}, nil)
+				return NewWebhookController(&repo)
+			},
+			wantCode: http.StatusOK,
+			wantResp: model.WebhookEventListDTO{},
+		},
+		{
+			name:  "fail - 400 - unknown state",
+			query: "?state=lost",
+			mockFunc: func() *WebhookController {
+				return NewWebhookController(&WebhookRepoMock{})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedWebhookEventStateErrResp,
+		},
+		{
+			name:  "fail - 400 - page size",
+			query: "?page_size=many",
+			mockFunc: func() *WebhookController {
+				return NewWebhookController(&WebhookRepoMock{})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedValueForPageSizeHdrErrResp,
+		},
+		{
+			name: "fail - 500",
+			mockFunc: func() *WebhookController {
+				repo := WebhookRepoMock{}
+				repo.On("Find", mock.Anything, "", "", DEFAULT_PAGE, DEFAULT_PAGE_SIZE).
+					Return([]model.WebhookEvent{}, errors.New("OMG error"))
+				return NewWebhookController(&repo)
+			},
+			wantCode: http.StatusInternalServerError,
+			wantErr:  &CannotGetWebhookEventsErrResp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testApp = fiber.New()
+			tt.mockFunc().SetupRouter(testApp.Group("/test"))
+
+			req := httptest.NewRequest("GET", "http://localhost/test/events"+tt.query, nil)
+			resp, err := testApp.Test(req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.Nil(t, err)
+
+			if tt.wantErr != nil {
+				var gotResp model.ProcessErrorResponse
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, *tt.wantErr, gotResp)
+			} else {
+				var gotResp model.WebhookEventListDTO
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, tt.wantResp, gotResp)
+			}
+		})
+	}
+}
+
+func TestGetWebhookEvent(t *testing.T) {
+	createdAt := time.Date(2023, 12, 8, 11, 33, 55, 0, time.UTC)
+	event := model.WebhookEvent{
+		Webhook:       "billing",
+		Type:          model.EVENT_PROCESS_STATUS_CHANGED,
+		State:         model.WEBHOOK_STATE_PENDING,
+		Attempts:      1,
+		NextAttemptAt: createdAt.Add(10 * time.Second),
+		LastError:     "unexpected response status 503",
+		Deliveries:    model.WebhookDeliveryList{{Attempt: 1, StatusCode: 503, Error: "unexpected response status 503", DurationMs: 12}},
+	}
+	event.ID = 42
+	event.CreatedAt = createdAt
+	event.Deliveries[0].CreatedAt = createdAt
+
+	tests := []struct {
+		name     string
+		id       string
+		mockFunc func() *WebhookController
+		wantCode int
+		wantErr  *model.ProcessErrorResponse
+	}{
+		{
+			name: "success",
+			id:   "42",
+			mockFunc: func() *WebhookController {
+				repo := WebhookRepoMock{}
+				repo.On("GetByID", mock.Anything, uint(42)).Return(&event, nil)
+				return NewWebhookController(&repo)
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "fail - 400",
+			id:   "abc",
+			mockFunc: func() *WebhookController {
+				return NewWebhookController(&WebhookRepoMock{})
+			},
+			wantCode: http.StatusBadRequest,
+			wantErr:  &NotSupportedWebhookEventIdErrResp,
+		},
+		{
+			name: "fail - 404",
+			id:   "43",
+			mockFunc: func() *WebhookController {
+				repo := WebhookRepoMock{}
+				repo.On("GetByID", mock.Anything, uint(43)).Return((*model.WebhookEvent)(nil), ErrWebhookEventNotFound)
+				return NewWebhookController(&repo)
+			},
+			wantCode: http.StatusNotFound,
+			wantErr:  &WebhookEventNotFoundErrResp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var testApp = fiber.New()
+			tt.mockFunc().SetupRouter(testApp.Group("/test"))
+
+			req := httptest.NewRequest("GET", fmt.Sprintf("http://localhost/test/events/%s", tt.id), nil)
+			resp, err := testApp.Test(req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			assert.Nil(t, err)
+
+			if tt.wantErr != nil {
+				var gotResp model.ProcessErrorResponse
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, *tt.wantErr, gotResp)
+			} else {
+				var gotResp model.WebhookEventDTO
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, event.ToDTO(), gotResp)
+			}
+		})
+	}
+}
+
+func TestRetryWebhookEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		mockErr  error
+		wantCode int
+		wantErr  *model.ProcessErrorResponse
+	}{
+		{
+			name:     "success",
+			wantCode: http.StatusNoContent,
+		},
+		{
+			name:     "fail - 404",
+			mockErr:  ErrWebhookEventNotFound,
+			wantCode: http.StatusNotFound,
+			wantErr:  &WebhookEventNotFoundErrResp,
+		},
+		{
+			name:     "fail - 409",
+			mockErr:  ErrWebhookEventNotDead,
+			wantCode: http.StatusConflict,
+			wantErr:  &WebhookEventNotDeadErrResp,
+		},
+		{
+			name:     "fail - 500",
+			mockErr:  errors.New("OMG error"),
+			wantCode: http.StatusInternalServerError,
+			wantErr:  &CannotRetryWebhookEventErrResp,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := WebhookRepoMock{}
+			repo.On("Retry", mock.Anything, uint(42), mock.Anything).Return(tt.mockErr)
+			var testApp = fiber.New()
+			NewWebhookController(&repo).SetupRouter(testApp.Group("/test"))
+
+			req := httptest.NewRequest("POST", "http://localhost/test/events/42/retry", nil)
+			resp, err := testApp.Test(req)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+
+			if tt.wantErr != nil {
+				body, err := io.ReadAll(resp.Body)
+				assert.Nil(t, err)
+				var gotResp model.ProcessErrorResponse
+				assert.Nil(t, json.Unmarshal(body, &gotResp))
+				assert.Equal(t, *tt.wantErr, gotResp)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	log "github.com/gofiber/fiber/v2/log"
+)
+
+const (
+	HEADERNAME_WEBHOOK_ID        = "X-Webhook-ID"
+	HEADERNAME_WEBHOOK_EVENT     = "X-Webhook-Event"
+	HEADERNAME_WEBHOOK_TIMESTAMP = "X-Webhook-Timestamp"
+	HEADERNAME_WEBHOOK_SIGNATURE = "X-Webhook-Signature"
+
+	DEFAULT_WEBHOOK_MAX_ATTEMPTS = 10
+
+	webhookPollInterval   = 5 * time.Second
+	webhookBatchSize      = 50
+	webhookTimeout        = 10 * time.Second
+	webhookLease          = time.Minute
+	webhookRetryBaseDelay = 10 * time.Second
+	webhookRetryMaxDelay  = time.Hour
+)
+
+type (
+	// WebhookDispatcher - delivers the outbox events to the webhooks, failed deliveries
+	// are retried with exponential backoff until the event is dead
+	WebhookDispatcher struct {
+		repo     WebhookRepository
+		webhooks config.WebhookConfigList
+		client   *http.Client
+		now      func() time.Time
+	}
+)
+
+var ErrWebhookNotConfigured = errors.New("webhook is not configured")
+
+func NewWebhookDispatcher(repo WebhookRepository, webhooks config.WebhookConfigList) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:     repo,
+		webhooks: webhooks,
+		client:   &http.Client{Timeout: webhookTimeout},
+		now:      time.Now,
+	}
+}
+
+// Run - polls the outbox until the context is done
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DispatchDue(ctx); err != nil {
+			log.Error("cannot dispatch webhook events ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue - delivers the events due to delivery, returns number of processed events
+func (d *WebhookDispatcher) DispatchDue(ctx context.Context) (int, error) {
+	events, err := d.repo.Claim(ctx, d.now(), webhookLease, webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range events {
+		// the rest is claimed again once the lease is over
+		if ctx.Err() != nil {
+			return i, nil
+		}
+		// the started delivery is not cancelled with the context, so it is finished on shutdown
+		if err := d.deliver(context.Background(), &events[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+// deliver - makes single delivery attempt and saves its result, only the failure to save is returned
+func (d *WebhookDispatcher) deliver(ctx context.Context, event *model.WebhookEvent) error {
+	event.Attempts++
+	delivery := &model.WebhookDelivery{Attempt: event.Attempts}
+
+	webhook, found := d.webhooks.Get(event.Webhook)
+	if !found {
+		delivery.Error = ErrWebhookNotConfigured.Error()
+		event.State = model.WEBHOOK_STATE_DEAD
+		event.LastError = delivery.Error
+		return d.repo.SaveAttempt(ctx, event, delivery)
+	}
+
+	start := d.now()
+	statusCode, err := d.send(ctx, webhook, event)
+	delivery.StatusCode = statusCode
+	delivery.DurationMs = d.now().Sub(start).Milliseconds()
+
+	if err == nil {
+		deliveredAt := d.now()
+		event.State = model.WEBHOOK_STATE_DELIVERED
+		event.DeliveredAt = &deliveredAt
+		event.LastError = ""
+		return d.repo.SaveAttempt(ctx, event, delivery)
+	}
+
+	log.Errorf("cannot deliver webhook event %d to %s: %v", event.ID, webhook.Name, err)
+	delivery.Error = err.Error()
+	event.LastError = delivery.Error
+
+	maxAttempts := webhook.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DEFAULT_WEBHOOK_MAX_ATTEMPTS
+	}
+	if event.Attempts >= maxAttempts {
+		event.State = model.WEBHOOK_STATE_DEAD
+	} else {
+		event.NextAttemptAt = d.now().Add(webhookRetryDelay(event.Attempts))
+	}
+	return d.repo.SaveAttempt(ctx, event, delivery)
+}
+
+// send - posts the signed event, any response status except 2xx is an error
+func (d *WebhookDispatcher) send(ctx context.Context, webhook config.WebhookConfig, event *model.WebhookEvent) (int, error) {
+	body := []byte(event.Body)
+	timestamp := d.now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HEADERNAME_WEBHOOK_ID, strconv.FormatUint(uint64(event.ID), 10))
+	req.Header.Set(HEADERNAME_WEBHOOK_EVENT, event.Type)
+	req.Header.Set(HEADERNAME_WEBHOOK_TIMESTAMP, strconv.FormatInt(timestamp, 10))
+	if len(webhook.Secret) > 0 {
+		req.Header.Set(HEADERNAME_WEBHOOK_SIGNATURE, SignWebhook(webhook.Secret, timestamp, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhook - returns the signature header value: `sha256=` and hex encoded
+// HMAC-SHA256 of `<timestamp>.<body>` with the secret of the webhook
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay - exponential backoff of the attempt: 10s, 20s, 40s ... up to an hour
+func webhookRetryDelay(attempt int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= webhookRetryMaxDelay {
+			return webhookRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// newWebhookEvents - creates the outbox events of the status change for every matched webhook
+func newWebhookEvents(webhooks config.WebhookConfigList, eventType string, process *model.Process, previousStatus string, status *model.ProcessStatus) ([]model.WebhookEvent, error) {
+	var events []model.WebhookEvent
+	if status == nil {
+		return events, nil
+	}
+
+	// the body is the same for all webhooks
+	var body []byte
+	for _, webhook := range webhooks {
+		if !webhook.Matches(process.Code, status.Name) {
+			continue
+		}
+
+		if body == nil {
+			var err error
+			body, err = json.Marshal(model.WebhookEventBody{
+				Type:           eventType,
+				Code:           process.Code,
+				UUID:           process.UUID,
+				Version:        process.Version,
+				PreviousStatus: previousStatus,
+				Status:         *status.ToDTO(),
+				OccurredAt:     status.CreatedAt,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		events = append(events, model.WebhookEvent{
+			Webhook:       webhook.Name,
+			Type:          eventType,
+			ProcessID:     process.ID,
+			Body:          body,
+			State:         model.WEBHOOK_STATE_PENDING,
+			NextAttemptAt: status.CreatedAt,
+		})
+	}
+	return events, nil
+}
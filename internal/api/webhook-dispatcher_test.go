@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWebhookDispatcher_DispatchDue(t *testing.T) {
+	now := time.Date(2023, 12, 8, 11, 33, 55, 0, time.UTC)
+	body := []byte(`{"type":"process.status_changed","code":"requests"}`)
+
+	type gotRequest struct {
+		headers http.Header
+		body    []byte
+	}
+	tests := []struct {
+		name         string
+		respCode     int
+		webhook      string
+		attempts     int
+		maxAttempts  int
+		wantRequest  bool
+		wantState    string
+		wantNext     time.Time
+		wantError    string
+		wantDelivery model.WebhookDelivery
+	}{
+		{
+			name:         "delivered",
+			respCode:     http.StatusNoContent,
+			webhook:      "billing",
+			wantRequest:  true,
+			wantState:    model.WEBHOOK_STATE_DELIVERED,
+			wantDelivery: model.WebhookDelivery{Attempt: 1, StatusCode: http.StatusNoContent},
+		},
+		{
+			name:         "failed - retry with backoff",
+			respCode:     http.StatusServiceUnavailable,
+			webhook:      "billing",
+			attempts:     2,
+			wantRequest:  true,
+			wantState:    model.WEBHOOK_STATE_PENDING,
+			wantNext:     now.Add(40 * time.Second),
+			wantError:    "unexpected response status 503",
+			wantDelivery: model.WebhookDelivery{Attempt: 3, StatusCode: http.StatusServiceUnavailable, Error: "unexpected response status 503"},
+		},
+		{
+			name:         "failed - dead after max attempts",
+			respCode:     http.StatusInternalServerError,
+			webhook:      "billing",
+			attempts:     2,
+			maxAttempts:  3,
+			wantRequest:  true,
+			wantState:    model.WEBHOOK_STATE_DEAD,
+			wantError:    "unexpected response status 500",
+			wantDelivery: model.WebhookDelivery{Attempt: 3, StatusCode: http.StatusInternalServerError, Error: "unexpected response status 500"},
+		},
+		{
+			name:         "failed - webhook is removed from the config",
+			webhook:      "removed",
+			wantState:    model.WEBHOOK_STATE_DEAD,
+			wantError:    ErrWebhookNotConfigured.Error(),
+			wantDelivery: model.WebhookDelivery{Attempt: 1, Error: ErrWebhookNotConfigured.Error()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []gotRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reqBody, _ := io.ReadAll(r.Body)
+				requests = append(requests, gotRequest{headers: r.Header, body: reqBody})
+				w.WriteHeader(tt.respCode)
+			}))
+			defer server.Close()
+
+			webhooks := config.WebhookConfigList{{Name: "billing", Url: server.URL, Secret: "secret", MaxAttempts: tt.maxAttempts}}
+			event := model.WebhookEvent{
+				Webhook:  tt.webhook,
+				Type:     model.EVENT_PROCESS_STATUS_CHANGED,
+				Body:     body,
+				State:    model.WEBHOOK_STATE_PENDING,
+				Attempts: tt.attempts,
+			}
+			event.ID = 42
+
+			repo := WebhookRepoMock{}
+			repo.On("Claim", mock.Anything, now, webhookLease, webhookBatchSize).
+				Return([]model.WebhookEvent{event}, nil)
+			repo.On("SaveAttempt", mock.Anything, mock.Anything, mock.Anything).
+				Return(nil)
+
+			dispatcher := NewWebhookDispatcher(&repo, webhooks)
+			dispatcher.now = func() time.Time { return now }
+
+			gotCount, err := dispatcher.DispatchDue(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 1, gotCount)
+
+			if tt.wantRequest {
+				assert.Len(t, requests, 1)
+				assert.Equal(t, body, requests[0].body)
+				assert.Equal(t, "42", requests[0].headers.Get(HEADERNAME_WEBHOOK_ID))
+				assert.Equal(t, model.EVENT_PROCESS_STATUS_CHANGED, requests[0].headers.Get(HEADERNAME_WEBHOOK_EVENT))
+				assert.Equal(t, "1702035235", requests[0].headers.Get(HEADERNAME_WEBHOOK_TIMESTAMP))
+				assert.Equal(t, SignWebhook("secret", now.Unix(), body), requests[0].headers.Get(HEADERNAME_WEBHOOK_SIGNATURE))
+			} else {
+				assert.Empty(t, requests)
+			}
+
+			gotEvent := repo.Calls[1].Arguments.Get(1).(*model.WebhookEvent)
+			gotDelivery := repo.Calls[1].Arguments.Get(2).(*model.WebhookDelivery)
+			assert.Equal(t, tt.wantState, gotEvent.State)
+			assert.Equal(t, tt.wantNext, gotEvent.NextAttemptAt)
+			assert.Equal(t, tt.wantError, gotEvent.LastError)
+			assert.Equal(t, tt.wantDelivery, *gotDelivery)
+			if tt.wantState == model.WEBHOOK_STATE_DELIVERED {
+				assert.Equal(t, now, *gotEvent.DeliveredAt)
+			} else {
+				assert.Nil(t, gotEvent.DeliveredAt)
+			}
+		})
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	got := SignWebhook("secret", 1702035235, []byte(`{"type":"process.submitted"}`))
+
+	assert.Equal(t, "sha256=4d5a34e4835ace7101735860822ee050dad2683601a0d8f1beeb18c3b3b834de", got)
+}
+
+func Test_webhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 10 * time.Second},
+		{attempt: 2, want: 20 * time.Second},
+		{attempt: 5, want: 160 * time.Second},
+		{attempt: 9, want: 2560 * time.Second},
+		{attempt: 10, want: time.Hour},
+		{attempt: 100, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, webhookRetryDelay(tt.attempt), "attempt %d", tt.attempt)
+	}
+}
+
+func Test_newWebhookEvents(t *testing.T) {
+	createdAt := time.Date(2023, 12, 8, 11, 33, 55, 0, time.UTC)
+	process := &model.Process{UUID: "23c968a6-5fc5-4e42-8f59-a7f9c0d4999c", Code: "requests", Version: 2}
+	process.ID = 7
+	status := &model.ProcessStatus{Name: "done", CreatedBy: "alex", Reason: "all good"}
+	status.CreatedAt = createdAt
+
+	webhooks := config.WebhookConfigList{
+		{Name: "all"},
+		{Name: "orders", Process: "orders"},
+		{Name: "done", Process: "requests", Statuses: []string{"done"}},
+		{Name: "open", Statuses: []string{"open"}},
+	}
+
+	got, err := newWebhookEvents(webhooks, model.EVENT_PROCESS_STATUS_CHANGED, process, "open", status)
+	assert.Nil(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, "all", got[0].Webhook)
+	assert.Equal(t, "done", got[1].Webhook)
+	for _, e := range got {
+		assert.Equal(t, model.EVENT_PROCESS_STATUS_CHANGED, e.Type)
+		assert.Equal(t, uint(7), e.ProcessID)
+		assert.Equal(t, model.WEBHOOK_STATE_PENDING, e.State)
+		assert.Equal(t, createdAt, e.NextAttemptAt)
+
+		var gotBody model.WebhookEventBody
+		assert.Nil(t, json.Unmarshal(e.Body, &gotBody))
+		assert.Equal(t, model.WebhookEventBody{
+			Type:           model.EVENT_PROCESS_STATUS_CHANGED,
+			Code:           "requests",
+			UUID:           "23c968a6-5fc5-4e42-8f59-a7f9c0d4999c",
+			Version:        2,
+			PreviousStatus: "open",
+			Status:         model.ProcessStatusDTO{Name: "done", CreatedBy: "alex", Reason: "all good", CreatedAt: &createdAt},
+			OccurredAt:     createdAt,
+		}, gotBody)
+	}
+
+	got, err = newWebhookEvents(webhooks[1:2], model.EVENT_PROCESS_STATUS_CHANGED, process, "open", status)
+	assert.Nil(t, err)
+	assert.Empty(t, got)
+}
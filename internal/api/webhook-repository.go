@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type (
+	WebhookRepository interface {
+		Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookEvent, error)
+		SaveAttempt(ctx context.Context, event *model.WebhookEvent, delivery *model.WebhookDelivery) error
+		Find(ctx context.Context, state string, webhook string, page int, pageSize int) ([]model.WebhookEvent, error)
+		GetByID(ctx context.Context, id uint) (*model.WebhookEvent, error)
+		Retry(ctx context.Context, id uint, now time.Time) error
+	}
+	WebhookRepo struct {
+		db *gorm.DB
+	}
+)
+
+var (
+	ErrWebhookEventNotFound = errors.New("webhook event not found")
+	ErrWebhookEventNotDead  = errors.New("webhook event is not dead")
+)
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &WebhookRepo{
+		db: db,
+	}
+}
+
+// Claim - returns pending events due to delivery and postpones them by the lease,
+// so other dispatchers skip them while they are being delivered
+func (r *WebhookRepo) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookEvent, error) {
+	var due []model.WebhookEvent
+	err := r.db.WithContext(ctx).
+		Where("state = ? AND next_attempt_at <= ?", model.WEBHOOK_STATE_PENDING, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&due).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []model.WebhookEvent
+	for _, event := range due {
+		res := r.db.WithContext(ctx).
+			Model(&model.WebhookEvent{}).
+			Where("id = ? AND state = ? AND next_attempt_at <= ?", event.ID, model.WEBHOOK_STATE_PENDING, now).
+			Update("next_attempt_at", now.Add(lease))
+		if res.Error != nil {
+			return claimed, res.Error
+		}
+		// claimed by someone else
+		if res.RowsAffected == 0 {
+			continue
+		}
+		claimed = append(claimed, event)
+	}
+	return claimed, nil
+}
+
+// SaveAttempt - logs the delivery attempt and updates the state of the event
+func (r *WebhookRepo) SaveAttempt(ctx context.Context, event *model.WebhookEvent, delivery *model.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		delivery.EventID = event.ID
+		if err := tx.Create(delivery).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.WebhookEvent{}).
+			Where("id = ?", event.ID).
+			Updates(map[string]interface{}{
+				"state":           event.State,
+				"attempts":        event.Attempts,
+				"next_attempt_at": event.NextAttemptAt,
+				"last_error":      event.LastError,
+				"delivered_at":    event.DeliveredAt,
+			}).Error
+	})
+}
+
+// Find - returns page of events, the latest first, empty state or webhook match any
+func (r *WebhookRepo) Find(ctx context.Context, state string, webhook string, page int, pageSize int) ([]model.WebhookEvent, error) {
+	stmt := r.db.WithContext(ctx).Model(&model.WebhookEvent{})
+	if len(state) > 0 {
+		stmt = stmt.Where("state = ?", state)
+	}
+	if len(webhook) > 0 {
+		stmt = stmt.Where("webhook = ?", webhook)
+	}
+
+	var events []model.WebhookEvent
+	err := stmt.Order("id DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&events).Error
+	return events, err
+}
+
+func (r *WebhookRepo) GetByID(ctx context.Context, id uint) (*model.WebhookEvent, error) {
+	var event model.WebhookEvent
+	err := r.db.WithContext(ctx).
+		Preload("Deliveries", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		First(&event, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookEventNotFound
+	}
+	return &event, err
+}
+
+// Retry - moves the dead event back to the pending ones with fresh attempts counter
+func (r *WebhookRepo) Retry(ctx context.Context, id uint, now time.Time) error {
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	res := r.db.WithContext(ctx).
+		Model(&model.WebhookEvent{}).
+		Where("id = ? AND state = ?", id, model.WEBHOOK_STATE_DEAD).
+		Updates(map[string]interface{}{
+			"state":           model.WEBHOOK_STATE_PENDING,
+			"attempts":        0,
+			"next_attempt_at": now,
+			"last_error":      "",
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrWebhookEventNotDead
+	}
+	return nil
+}
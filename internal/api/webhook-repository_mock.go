@@ -0,0 +1,35 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookRepoMock struct {
+	mock.Mock
+}
+
+func (r *WebhookRepoMock) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.WebhookEvent, error) {
+	args := r.Called(ctx, now, lease, limit)
+	return args.Get(0).([]model.WebhookEvent), args.Error(1)
+}
+func (r *WebhookRepoMock) SaveAttempt(ctx context.Context, event *model.WebhookEvent, delivery *model.WebhookDelivery) error {
+	args := r.Called(ctx, event, delivery)
+	return args.Error(0)
+}
+func (r *WebhookRepoMock) Find(ctx context.Context, state string, webhook string, page int, pageSize int) ([]model.WebhookEvent, error) {
+	args := r.Called(ctx, state, webhook, page, pageSize)
+	return args.Get(0).([]model.WebhookEvent), args.Error(1)
+}
+func (r *WebhookRepoMock) GetByID(ctx context.Context, id uint) (*model.WebhookEvent, error) {
+	args := r.Called(ctx, id)
+	return args.Get(0).(*model.WebhookEvent), args.Error(1)
+}
+func (r *WebhookRepoMock) Retry(ctx context.Context, id uint, now time.Time) error {
+	args := r.Called(ctx, id, now)
+	return args.Error(0)
+}
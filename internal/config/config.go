@@ -22,6 +22,48 @@ type (
 		DbUrl         string            `json:"db_url"`
 		ProcessConfig ProcessConfigList `json:"processes"`
 		SwaggerConfig swagger.Config    `json:"swagger_config:omitempty"`
+		Auth          AuthConfig        `json:"auth,omitempty"`
+		Webhooks      WebhookConfigList `json:"webhooks,omitempty"`
+	}
+
+	// AuthConfig - authentication of API requests, type is one of: none (default), api_key, jwt.
+	// AdminRoles are required to call the admin API, any authenticated actor is allowed if empty
+	AuthConfig struct {
+		Type       string       `json:"type,omitempty"`
+		ApiKey     ApiKeyConfig `json:"api_key,omitempty"`
+		Jwt        JwtConfig    `json:"jwt,omitempty"`
+		AdminRoles []string     `json:"admin_roles,omitempty"`
+	}
+
+	// ApiKeyConfig - static API keys, KeysFile is JSON list of `{"key": "...", "id": "...", "roles": [...]}`
+	ApiKeyConfig struct {
+		Header   string `json:"header,omitempty"`
+		KeysFile string `json:"keys_file"`
+	}
+
+	// JwtConfig - bearer tokens signed with HS256 secret or RS256 key pair,
+	// KeyFile contains the secret or PEM encoded public key.
+	// Tokens without exp claim are rejected unless AllowNoExpiry is set
+	JwtConfig struct {
+		Algorithm     string `json:"algorithm"`
+		KeyFile       string `json:"key_file"`
+		Issuer        string `json:"issuer,omitempty"`
+		Audience      string `json:"audience,omitempty"`
+		RolesClaim    string `json:"roles_claim,omitempty"`
+		AllowNoExpiry bool   `json:"allow_no_expiry,omitempty"`
+	}
+
+	WebhookConfigList []WebhookConfig
+
+	// WebhookConfig - subscription to the process status changes, empty Process or Statuses match any.
+	// Deliveries are signed with HMAC-SHA256 of the Secret and retried up to MaxAttempts times
+	WebhookConfig struct {
+		Name        string   `json:"name"`
+		Url         string   `json:"url"`
+		Secret      string   `json:"secret,omitempty"`
+		Process     string   `json:"process,omitempty"`
+		Statuses    []string `json:"statuses,omitempty"`
+		MaxAttempts int      `json:"max_attempts,omitempty"`
 	}
 )
 
@@ -68,3 +110,29 @@ func (cb *ConfigBuilder) LoadFromFile(fileReader FileReader) (*Config, error) {
 func (osfr *osFileReader) ReadFile(filePath string) ([]byte, error) {
 	return os.ReadFile(filePath)
 }
+
+// Matches - checks if the webhook is subscribed to the status of the process
+func (w WebhookConfig) Matches(process string, status string) bool {
+	if len(w.Process) > 0 && w.Process != process {
+		return false
+	}
+	if len(w.Statuses) == 0 {
+		return true
+	}
+	for _, s := range w.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Get - returns the webhook by name
+func (wl WebhookConfigList) Get(name string) (WebhookConfig, bool) {
+	for _, w := range wl {
+		if w.Name == name {
+			return w, true
+		}
+	}
+	return WebhookConfig{}, false
+}
@@ -38,6 +38,7 @@ func Test_LoadConfig(t *testing.T) {
 						"statuses": [
 							{
 								"name": "open",
+								"initial": true,
 								"next": [
 									"in_progress",
 									"rejected"
@@ -47,11 +48,15 @@ func Test_LoadConfig(t *testing.T) {
 								"name": "in_progress",
 								"next": [
 									"open",
-									"rejected"
+									{
+										"name": "rejected",
+										"when": "input.comment != null"
+									}
 								]
 							},
 							{
-								"name": "rejected"
+								"name": "rejected",
+								"final": true
 							}
 						]
 					}]		}
@@ -65,15 +70,17 @@ func Test_LoadConfig(t *testing.T) {
 						Name: "requests",
 						Statuses: []StatusConfig{
 							{
-								Name: "open",
-								Next: []string{"in_progress", "rejected"},
+								Name:    "open",
+								Next:    NextList{{Name: "in_progress"}, {Name: "rejected"}},
+								Initial: true,
 							},
 							{
 								Name: "in_progress",
-								Next: []string{"open", "rejected"},
+								Next: NextList{{Name: "open"}, {Name: "rejected", When: "input.comment != null"}},
 							},
 							{
-								Name: "rejected",
+								Name:  "rejected",
+								Final: true,
 							},
 						},
 					},
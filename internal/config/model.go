@@ -1,23 +1,65 @@
 package config
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
 
 type (
 	ProcessConfig struct {
 		Name     string         `json:"name"`
+		Schema   string         `json:"schema,omitempty"`
 		Statuses []StatusConfig `json:"statuses"`
 	}
 
 	ProcessConfigList []ProcessConfig
 
 	StatusConfig struct {
-		Name   string   `json:"name"`
-		Next   []string `json:"next,omitempty"`
-		Schema string   `json:"schema,omitempty"`
+		Name    string   `json:"name"`
+		Next    NextList `json:"next,omitempty"`
+		Schema  string   `json:"schema,omitempty"`
+		Initial bool     `json:"initial,omitempty"`
+		Final   bool     `json:"final,omitempty"`
+		// Roles - roles allowed to move processes into the status, anyone if empty
+		Roles []string `json:"roles,omitempty"`
+		// RequireReason - reason has to be given to move processes into the status by any transition
+		RequireReason bool `json:"require_reason,omitempty"`
+		// Timeout - duration like "30m" or "48h" the process may stay in the status,
+		// then it is moved into the OnTimeout status by the engine
+		Timeout   string `json:"timeout,omitempty"`
+		OnTimeout string `json:"on_timeout,omitempty"`
+	}
+
+	NextList []NextStatus
+
+	// NextStatus - allowed transition, optional `when` guard expression has to be true to move the process,
+	// plain status name is accepted as well: `"next": ["done", {"name": "approved", "when": "payload.amount < 10000"}]`.
+	// Roles of the transition override roles of the next status. RequireReason requires the reason for this
+	// transition only, RequireReason of the next status requires it for every transition into the status.
+	NextStatus struct {
+		Name          string   `json:"name"`
+		When          string   `json:"when,omitempty"`
+		Roles         []string `json:"roles,omitempty"`
+		RequireReason bool     `json:"require_reason,omitempty"`
 	}
 )
 
-var ErrStatusConfigNotFound = errors.New("status config not found")
+var (
+	ErrProcessConfigNotFound        = errors.New("process config not found")
+	ErrStatusConfigNotFound         = errors.New("status config not found")
+	ErrInitialStatusConfigNotFound  = errors.New("initial status config not found")
+	ErrMultipleInitialStatusConfigs = errors.New("more than one initial status config")
+)
+
+func (pc ProcessConfigList) GetProcessConfig(code string) (*ProcessConfig, error) {
+	for _, p := range pc {
+		if p.Name == code {
+			return &p, nil
+		}
+	}
+	return nil, ErrProcessConfigNotFound
+}
 
 func (pc ProcessConfigList) GetStatusConfig(code, status string) (*StatusConfig, error) {
 	for _, p := range pc {
@@ -31,3 +73,69 @@ func (pc ProcessConfigList) GetStatusConfig(code, status string) (*StatusConfig,
 	}
 	return nil, ErrStatusConfigNotFound
 }
+
+// GetInitialStatusConfig - returns the single status marked as `initial` for the process
+func (pc ProcessConfigList) GetInitialStatusConfig(code string) (*StatusConfig, error) {
+	var initial *StatusConfig
+	for _, p := range pc {
+		if p.Name == code {
+			for i := range p.Statuses {
+				if !p.Statuses[i].Initial {
+					continue
+				}
+				if initial != nil {
+					return nil, ErrMultipleInitialStatusConfigs
+				}
+				sc := p.Statuses[i]
+				initial = &sc
+			}
+		}
+	}
+	if initial == nil {
+		return nil, ErrInitialStatusConfigNotFound
+	}
+	return initial, nil
+}
+
+// TimeoutDuration - returns the timeout of the status, 0 if it has none
+func (sc StatusConfig) TimeoutDuration() (time.Duration, error) {
+	if len(sc.Timeout) == 0 {
+		return 0, nil
+	}
+	return time.ParseDuration(sc.Timeout)
+}
+
+func (ns *NextStatus) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*ns = NextStatus{Name: name}
+		return nil
+	}
+
+	type nextStatus NextStatus
+	var next nextStatus
+	if err := json.Unmarshal(data, &next); err != nil {
+		return err
+	}
+	*ns = NextStatus(next)
+	return nil
+}
+
+// Names - returns names of the next statuses
+func (nl NextList) Names() []string {
+	names := make([]string, 0, len(nl))
+	for _, n := range nl {
+		names = append(names, n.Name)
+	}
+	return names
+}
+
+// Get - returns the transition into the status
+func (nl NextList) Get(status string) (*NextStatus, bool) {
+	for i := range nl {
+		if nl[i].Name == status {
+			return &nl[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/expr"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+type (
+	// ConfigError - problem found in the process definitions, Path points to the broken element
+	ConfigError struct {
+		Path string
+		Err  error
+	}
+)
+
+var (
+	ErrEmptyName           = errors.New("name is empty")
+	ErrDuplicateProcess    = errors.New("duplicate process")
+	ErrDuplicateStatus     = errors.New("duplicate status")
+	ErrUnknownNextStatus   = errors.New("next status is not defined")
+	ErrUnreachableStatus   = errors.New("status is unreachable from the initial status")
+	ErrDeadEndStatus       = errors.New("non-final status has no way out")
+	ErrFinalStatusWithNext = errors.New("final status has next statuses")
+	ErrInvalidJsonSchema   = errors.New("invalid JSON Schema")
+	ErrNoStatusesDeclared  = errors.New("no statuses declared")
+	ErrInvalidGuard        = errors.New("invalid guard expression")
+	ErrDuplicateWebhook    = errors.New("duplicate webhook")
+	ErrInvalidWebhookUrl   = errors.New("invalid webhook URL")
+	ErrUnknownProcess      = errors.New("process is not defined")
+	ErrUnknownStatus       = errors.New("status is not defined")
+	ErrInvalidTimeout      = errors.New("invalid timeout")
+	ErrTimeoutTransition   = errors.New("on_timeout status is not a next status")
+)
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Validate - lints the process definitions and returns all found problems at once
+func (pc ProcessConfigList) Validate() error {
+	var problems []error
+
+	processNames := map[string]bool{}
+	for i, p := range pc {
+		processPath := fmt.Sprintf("processes[%s]", p.Name)
+		if len(p.Name) == 0 {
+			processPath = fmt.Sprintf("processes[%d]", i)
+			problems = append(problems, &ConfigError{Path: processPath, Err: ErrEmptyName})
+		} else if processNames[p.Name] {
+			problems = append(problems, &ConfigError{Path: processPath, Err: ErrDuplicateProcess})
+		}
+		processNames[p.Name] = true
+
+		problems = append(problems, p.validate(processPath)...)
+	}
+
+	if len(problems) > 0 {
+		return errors.Join(problems...)
+	}
+	return nil
+}
+
+func (p ProcessConfig) validate(processPath string) []error {
+	var problems []error
+
+	if len(p.Schema) > 0 {
+		if err := compileJsonSchema(p.Schema); err != nil {
+			problems = append(problems, &ConfigError{Path: processPath + ".schema", Err: errors.Join(ErrInvalidJsonSchema, err)})
+		}
+	}
+
+	if len(p.Statuses) == 0 {
+		return append(problems, &ConfigError{Path: processPath + ".statuses", Err: ErrNoStatusesDeclared})
+	}
+
+	// unique and named statuses only, problems with the rest are reported above
+	statuses := map[string]StatusConfig{}
+	var declared []StatusConfig
+	var initial []string
+	for i, s := range p.Statuses {
+		statusPath := fmt.Sprintf("%s.statuses[%s]", processPath, s.Name)
+		if len(s.Name) == 0 {
+			statusPath = fmt.Sprintf("%s.statuses[%d]", processPath, i)
+			problems = append(problems, &ConfigError{Path: statusPath, Err: ErrEmptyName})
+			continue
+		}
+		if _, ok := statuses[s.Name]; ok {
+			problems = append(problems, &ConfigError{Path: statusPath, Err: ErrDuplicateStatus})
+			continue
+		}
+		statuses[s.Name] = s
+		declared = append(declared, s)
+		if s.Initial {
+			initial = append(initial, s.Name)
+		}
+
+		if len(s.Schema) > 0 {
+			if err := compileJsonSchema(s.Schema); err != nil {
+				problems = append(problems, &ConfigError{Path: statusPath + ".schema", Err: errors.Join(ErrInvalidJsonSchema, err)})
+			}
+		}
+	}
+
+	hasWayOut := map[string]bool{}
+	for _, s := range declared {
+		statusPath := fmt.Sprintf("%s.statuses[%s]", processPath, s.Name)
+		for i, next := range s.Next {
+			nextPath := fmt.Sprintf("%s.next[%d]", statusPath, i)
+			if len(next.When) > 0 {
+				if _, err := expr.Parse(next.When); err != nil {
+					problems = append(problems, &ConfigError{Path: nextPath + ".when", Err: errors.Join(ErrInvalidGuard, err)})
+				}
+			}
+			if _, ok := statuses[next.Name]; !ok {
+				problems = append(problems, &ConfigError{
+					Path: nextPath,
+					Err:  fmt.Errorf("%w: %q", ErrUnknownNextStatus, next.Name),
+				})
+				continue
+			}
+			if next.Name != s.Name {
+				hasWayOut[s.Name] = true
+			}
+		}
+		problems = append(problems, s.validateTimeout(statusPath)...)
+	}
+
+	for _, s := range declared {
+		statusPath := fmt.Sprintf("%s.statuses[%s]", processPath, s.Name)
+		if !s.Final && !hasWayOut[s.Name] {
+			problems = append(problems, &ConfigError{Path: statusPath, Err: ErrDeadEndStatus})
+		}
+		if s.Final && len(s.Next) > 0 {
+			problems = append(problems, &ConfigError{Path: statusPath + ".next", Err: ErrFinalStatusWithNext})
+		}
+	}
+
+	switch len(initial) {
+	case 0:
+		problems = append(problems, &ConfigError{Path: processPath, Err: ErrInitialStatusConfigNotFound})
+	case 1:
+		reachable := p.reachableFrom(initial[0])
+		for _, s := range declared {
+			if !reachable[s.Name] {
+				problems = append(problems, &ConfigError{
+					Path: fmt.Sprintf("%s.statuses[%s]", processPath, s.Name),
+					Err:  ErrUnreachableStatus,
+				})
+			}
+		}
+	default:
+		problems = append(problems, &ConfigError{
+			Path: processPath,
+			Err:  fmt.Errorf("%w: %s", ErrMultipleInitialStatusConfigs, strings.Join(initial, ", ")),
+		})
+	}
+
+	return problems
+}
+
+// validateTimeout - timeout and on_timeout are set together, the process is moved on timeout by the allowed transition
+func (s StatusConfig) validateTimeout(statusPath string) []error {
+	if len(s.Timeout) == 0 && len(s.OnTimeout) == 0 {
+		return nil
+	}
+
+	var problems []error
+	timeout, err := s.TimeoutDuration()
+	if err != nil || timeout <= 0 || s.Final {
+		problems = append(problems, &ConfigError{Path: statusPath + ".timeout", Err: fmt.Errorf("%w: %q", ErrInvalidTimeout, s.Timeout)})
+	}
+	if _, ok := s.Next.Get(s.OnTimeout); !ok {
+		problems = append(problems, &ConfigError{Path: statusPath + ".on_timeout", Err: fmt.Errorf("%w: %q", ErrTimeoutTransition, s.OnTimeout)})
+	}
+	return problems
+}
+
+// Validate - checks the webhook subscriptions refer to the defined processes and statuses
+func (wl WebhookConfigList) Validate(processes ProcessConfigList) error {
+	var problems []error
+
+	names := map[string]bool{}
+	for i, w := range wl {
+		webhookPath := fmt.Sprintf("webhooks[%s]", w.Name)
+		if len(w.Name) == 0 {
+			webhookPath = fmt.Sprintf("webhooks[%d]", i)
+			problems = append(problems, &ConfigError{Path: webhookPath, Err: ErrEmptyName})
+		} else if names[w.Name] {
+			problems = append(problems, &ConfigError{Path: webhookPath, Err: ErrDuplicateWebhook})
+		}
+		names[w.Name] = true
+
+		if u, err := url.Parse(w.Url); err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			problems = append(problems, &ConfigError{Path: webhookPath + ".url", Err: fmt.Errorf("%w: %q", ErrInvalidWebhookUrl, w.Url)})
+		}
+
+		// statuses of all processes are allowed if the process is not set
+		statuses := map[string]bool{}
+		processFound := len(w.Process) == 0
+		for _, p := range processes {
+			if len(w.Process) == 0 || p.Name == w.Process {
+				processFound = processFound || p.Name == w.Process
+				for _, s := range p.Statuses {
+					statuses[s.Name] = true
+				}
+			}
+		}
+		if !processFound {
+			problems = append(problems, &ConfigError{Path: webhookPath + ".process", Err: fmt.Errorf("%w: %q", ErrUnknownProcess, w.Process)})
+			continue
+		}
+		for j, s := range w.Statuses {
+			if !statuses[s] {
+				problems = append(problems, &ConfigError{Path: fmt.Sprintf("%s.statuses[%d]", webhookPath, j), Err: fmt.Errorf("%w: %q", ErrUnknownStatus, s)})
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.Join(problems...)
+	}
+	return nil
+}
+
+// reachableFrom - walks the `next` graph starting from the given status
+func (p ProcessConfig) reachableFrom(status string) map[string]bool {
+	next := map[string][]string{}
+	for _, s := range p.Statuses {
+		next[s.Name] = append(next[s.Name], s.Next.Names()...)
+	}
+
+	reachable := map[string]bool{status: true}
+	queue := []string{status}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, n := range next[current] {
+			if !reachable[n] {
+				reachable[n] = true
+				queue = append(queue, n)
+			}
+		}
+	}
+	return reachable
+}
+
+func compileJsonSchema(schema string) error {
+	const name = "schema.json"
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(name, strings.NewReader(schema)); err != nil {
+		return err
+	}
+	_, err := compiler.Compile(name)
+	return err
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		conf      ProcessConfigList
+		wantErrs  []error
+		wantPaths []string
+	}{
+		{
+			name: "valid",
+			conf: ProcessConfigList{{
+				Name:   "requests",
+				Schema: `{"type": "object"}`,
+				Statuses: []StatusConfig{
+					{Name: "open", Initial: true, Next: NextList{{Name: "in_progress"}, {Name: "rejected"}}},
+					{Name: "in_progress", Next: NextList{{Name: "open"}, {Name: "in_progress"}, {Name: "done"}}, Schema: `{"type": "object"}`},
+					{Name: "rejected", Final: true},
+					{Name: "done", Final: true},
+				},
+			}},
+		},
+		{
+			name: "duplicate process and status",
+			conf: ProcessConfigList{
+				{
+					Name: "requests",
+					Statuses: []StatusConfig{
+						{Name: "open", Initial: true, Next: NextList{{Name: "done"}}},
+						{Name: "open", Next: NextList{{Name: "done"}}},
+						{Name: "done", Final: true},
+					},
+				},
+				{
+					Name: "requests",
+					Statuses: []StatusConfig{
+						{Name: "open", Initial: true, Next: NextList{{Name: "done"}}},
+						{Name: "done", Final: true},
+					},
+				},
+			},
+			wantErrs:  []error{ErrDuplicateStatus, ErrDuplicateProcess},
+			wantPaths: []string{"processes[requests].statuses[open]", "processes[requests]"},
+		},
+		{
+			name: "unknown next status",
+			conf: ProcessConfigList{{
+				Name: "requests",
+				Statuses: []StatusConfig{
+					{Name: "open", Initial: true, Next: NextList{{Name: "in_progres"}, {Name: "done"}}},
+					{Name: "done", Final: true},
+				},
+			}},
+			wantErrs:  []error{ErrUnknownNextStatus},
+			wantPaths: []string{"processes[requests].statuses[open].next[0]"},
+		},
+		{
+			name: "invalid guard",
+			conf: ProcessConfigList{{
+				Name: "requests",
+				Statuses: []StatusConfig{
+					{Name: "open", Initial: true, Next: NextList{{Name: "done", When: "payload.amount <"}}},
+					{Name: "done", Final: true},
+				},
+			}},
+			wantErrs:  []error{ErrInvalidGuard},
+			wantPaths: []string{"processes[requests].statuses[open].next[0].when"},
+		},
+		{
+			name: "unreachable and dead end statuses",
+			conf: ProcessConfigList{{
+				Name: "requests",
+				Statuses: []StatusConfig{
+					{Name: "open", Initial: true, Next: NextList{{Name: "in_progress"}, {Name: "done"}}},
+					{Name: "in_progress", Next: NextList{{Name: "in_progress"}}},
+					{Name: "archived", Final: true},
+					{Name: "done", Final: true},
+				},
+			}},
+			wantErrs:  []error{ErrDeadEndStatus, ErrUnreachableStatus},
+			wantPaths: []string{"processes[requests].statuses[in_progress]", "processes[requests].statuses[archived]"},
+		},
+		{
+			name: "final status with next statuses",
+			conf: ProcessConfigList{{
+				Name: "requests",
+				Statuses: []StatusConfig{
+					{Name: "open", Initial: true, Next: NextList{{Name: "done"}}},
+					{Name: "done", Final: true, Next: NextList{{Name: "open"}}},
+				},
+			}},
+			wantErrs:  []error{ErrFinalStatusWithNext},
+			wantPaths: []string{"processes[requests].statuses[done].next"},
+		},
+		{
+			name: "no initial status",
+			conf: ProcessConfigList{{
+				Name: "requests",
+				Statuses: []StatusConfig{
+					{Name: "open", Next: NextList{{Name: "done"}}},
+					{Name: "done", Final: true},
+				},
+			}},
+			wantErrs:  []error{ErrInitialStatusConfigNotFound},
+			wantPaths: []string{"processes[requests]"},
+		},
+		{
+			name: "more than one initial status",
+			conf: ProcessConfigList{{
+				Name: "requests",
+				Statuses: []StatusConfig{
+					{Name: "open", Initial: true, Next: NextList{{Name: "done"}}},
+					{Name: "new", Initial: true, Next: NextList{{Name: "done"}}},
+					{Name: "done", Final: true},
+				},
+			}},
+			wantErrs:  []error{ErrMultipleInitialStatusConfigs},
+			wantPaths: []string{"processes[requests]"},
+		},
+		{
+			name: "invalid schemas",
+			conf: ProcessConfigList{{
+				Name:   "requests",
+				Schema: `{"type": 42}`,
+				Statuses: []StatusConfig{
+					{Name: "open", Initial: true, Next: NextList{{Name: "done"}}, Schema: `{"type": `},
+					{Name: "done", Final: true},
+				},
+			}},
+			wantErrs:  []error{ErrInvalidJsonSchema, ErrInvalidJsonSchema},
+			wantPaths: []string{"processes[requests].schema", "processes[requests].statuses[open].schema"},
+		},
+		{
+			name: "valid timeout",
+			conf: ProcessConfigList{{
+				Name: "requests",
+				Statuses: []StatusConfig{
+					{Name: "open", Initial: true, Next: NextList{{Name: "done"}, {Name: "expired"}}, Timeout: "48h", OnTimeout: "expired"},
+					{Name: "expired", Final: true},
+					{Name: "done", Final: true},
+				},
+			}},
+		},
+		{
+			name: "invalid timeouts",
+			conf: ProcessConfigList{{
+				Name: "requests",
+				Statuses: []StatusConfig{
+					{Name: "open", Initial: true, Next: NextList{{Name: "in_progress"}}, Timeout: "2d", OnTimeout: "in_progress"},
+					{Name: "in_progress", Next: NextList{{Name: "done"}}, Timeout: "1h", OnTimeout: "expired"},
+					{Name: "expired", Final: true, Timeout: "-1h"},
+					{Name: "done", Final: true},
+				},
+			}},
+			wantErrs: []error{ErrInvalidTimeout, ErrTimeoutTransition, ErrInvalidTimeout, ErrTimeoutTransition, ErrUnreachableStatus},
+			wantPaths: []string{
+				"processes[requests].statuses[open].timeout",
+				"processes[requests].statuses[in_progress].on_timeout",
+				"processes[requests].statuses[expired].timeout",
+				"processes[requests].statuses[expired].on_timeout",
+				"processes[requests].statuses[expired]",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotErr := tt.conf.Validate()
+
+			if len(tt.wantErrs) == 0 {
+				assert.Nil(t, gotErr)
+				return
+			}
+
+			assert.NotNil(t, gotErr)
+			gotProblems := gotErr.(interface{ Unwrap() []error }).Unwrap()
+			assert.Len(t, gotProblems, len(tt.wantErrs))
+			for i, wantErr := range tt.wantErrs {
+				assert.ErrorIs(t, gotProblems[i], wantErr)
+
+				var configErr *ConfigError
+				assert.True(t, errors.As(gotProblems[i], &configErr))
+				assert.Equal(t, tt.wantPaths[i], configErr.Path)
+			}
+		})
+	}
+}
+
+func Test_ValidateWebhooks(t *testing.T) {
+	processes := ProcessConfigList{{
+		Name: "requests",
+		Statuses: []StatusConfig{
+			{Name: "open", Initial: true, Next: NextList{{Name: "done"}}},
+			{Name: "done", Final: true},
+		},
+	}}
+	tests := []struct {
+		name      string
+		webhooks  WebhookConfigList
+		wantErrs  []error
+		wantPaths []string
+	}{
+		{
+			name: "valid",
+			webhooks: WebhookConfigList{
+				{Name: "all", Url: "https://example.com/hook"},
+				{Name: "done", Url: "http://localhost:8080/hook", Process: "requests", Statuses: []string{"done"}},
+			},
+		},
+		{
+			name: "empty and duplicate names",
+			webhooks: WebhookConfigList{
+				{Url: "https://example.com/hook"},
+				{Name: "all", Url: "https://example.com/hook"},
+				{Name: "all", Url: "https://example.com/hook"},
+			},
+			wantErrs:  []error{ErrEmptyName, ErrDuplicateWebhook},
+			wantPaths: []string{"webhooks[0]", "webhooks[all]"},
+		},
+		{
+			name: "invalid URL",
+			webhooks: WebhookConfigList{
+				{Name: "relative", Url: "/hook"},
+				{Name: "ftp", Url: "ftp://example.com/hook"},
+			},
+			wantErrs:  []error{ErrInvalidWebhookUrl, ErrInvalidWebhookUrl},
+			wantPaths: []string{"webhooks[relative].url", "webhooks[ftp].url"},
+		},
+		{
+			name: "unknown process and status",
+			webhooks: WebhookConfigList{
+				{Name: "orders", Url: "https://example.com/hook", Process: "orders"},
+				{Name: "closed", Url: "https://example.com/hook", Statuses: []string{"done", "closed"}},
+			},
+			wantErrs:  []error{ErrUnknownProcess, ErrUnknownStatus},
+			wantPaths: []string{"webhooks[orders].process", "webhooks[closed].statuses[1]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotErr := tt.webhooks.Validate(processes)
+
+			if len(tt.wantErrs) == 0 {
+				assert.Nil(t, gotErr)
+				return
+			}
+
+			assert.NotNil(t, gotErr)
+			gotProblems := gotErr.(interface{ Unwrap() []error }).Unwrap()
+			assert.Len(t, gotProblems, len(tt.wantErrs))
+			for i, wantErr := range tt.wantErrs {
+				assert.ErrorIs(t, gotProblems[i], wantErr)
+
+				var configErr *ConfigError
+				assert.True(t, errors.As(gotProblems[i], &configErr))
+				assert.Equal(t, tt.wantPaths[i], configErr.Path)
+			}
+		})
+	}
+}
+
+func Test_WebhookMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook WebhookConfig
+		process string
+		status  string
+		want    bool
+	}{
+		{name: "any process and status", webhook: WebhookConfig{}, process: "requests", status: "open", want: true},
+		{name: "process matches", webhook: WebhookConfig{Process: "requests"}, process: "requests", status: "open", want: true},
+		{name: "process does not match", webhook: WebhookConfig{Process: "orders"}, process: "requests", status: "open"},
+		{name: "status matches", webhook: WebhookConfig{Statuses: []string{"open", "done"}}, process: "requests", status: "done", want: true},
+		{name: "status does not match", webhook: WebhookConfig{Process: "requests", Statuses: []string{"done"}}, process: "requests", status: "open"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.webhook.Matches(tt.process, tt.status))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package dialects
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const (
+	SQLITE   = "sqlite3"
+	POSTGRES = "postgres"
+	MYSQL    = "mysql"
+
+	DEFAULT_DB_ENGINE = SQLITE
+)
+
+type (
+	// Factory - creates gorm dialector of the DB engine from the DB URL (DSN)
+	Factory func(dsn string) gorm.Dialector
+)
+
+var (
+	ErrUnknownDbEngine = errors.New("unknown db engine")
+
+	mu        sync.RWMutex
+	factories = map[string]Factory{
+		SQLITE:   sqlite.Open,
+		POSTGRES: postgres.Open,
+		MYSQL:    mysql.Open,
+	}
+)
+
+// Register - adds the DB engine selected by db_engine of the config, the built-in one with the same name is replaced
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = factory
+}
+
+// Names - sorted names of the registered DB engines
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	res := make([]string, 0, len(factories))
+	for name := range factories {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Dialector - creates the dialector of the DB engine, sqlite3 if the engine is empty
+func Dialector(engine, dsn string) (gorm.Dialector, error) {
+	if len(engine) == 0 {
+		engine = DEFAULT_DB_ENGINE
+	}
+
+	mu.RLock()
+	factory, ok := factories[engine]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s, expected one of %v", ErrUnknownDbEngine, engine, Names())
+	}
+	return factory(dsn), nil
+}
+
+// Open - connects to the DB of the engine
+func Open(engine, dsn string) (*gorm.DB, error) {
+	dialector, err := Dialector(engine, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return gorm.Open(dialector, &gorm.Config{})
+}
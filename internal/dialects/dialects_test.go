@@ -0,0 +1,63 @@
+package dialects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestDialector(t *testing.T) {
+	Register("custom", func(dsn string) gorm.Dialector {
+		return sqlite.Open(dsn)
+	})
+
+	tests := []struct {
+		name     string
+		engine   string
+		wantName string
+		wantErr  error
+	}{
+		{
+			name:     "default",
+			wantName: "sqlite",
+		},
+		{
+			name:     "sqlite3",
+			engine:   SQLITE,
+			wantName: "sqlite",
+		},
+		{
+			name:     "postgres",
+			engine:   POSTGRES,
+			wantName: "postgres",
+		},
+		{
+			name:     "mysql",
+			engine:   MYSQL,
+			wantName: "mysql",
+		},
+		{
+			name:     "registered",
+			engine:   "custom",
+			wantName: "sqlite",
+		},
+		{
+			name:    "unknown",
+			engine:  "oracle",
+			wantErr: ErrUnknownDbEngine,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Dialector(tt.engine, "dsn")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantName, got.Name())
+		})
+	}
+}
@@ -23,6 +23,193 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/v1/admin/webhooks/events": {
+            "get": {
+                "description": "Get events of the webhook outbox, the latest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get list of webhook events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "State of the event: pending, delivered, dead",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Name of the webhook",
+                        "name": "webhook",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookEventDTO"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/events/{id}": {
+            "get": {
+                "description": "Get webhook event with its delivery attempts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get webhook event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the event",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.WebhookEventDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/admin/webhooks/events/{id}/retry": {
+            "post": {
+                "description": "Moves the dead event back to the pending ones, delivery attempts start over",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Retry dead webhook event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the event",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/changes": {
+            "get": {
+                "description": "Submits and status changes of all processes ordered by the sequence number (ID of the change).\nThe consumer keeps next_after of the batch and requests the next one after it, so the feed can be replayed from any offset",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "changes"
+                ],
+                "summary": "Change feed of processes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Sequence number of the last consumed change, 0 to start from the beginning",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Max number of changes in the batch, 100 by default, 1000 max",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.ChangeFeedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/process-definitions": {
+            "get": {
+                "description": "Get processes the engine runs with: statuses, allowed transitions and JSON Schemas",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "Get list of process definitions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ProcessDefinitionDTO"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/process-definitions/{code}": {
+            "get": {
+                "description": "Get process definition by code",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process-definitions"
+                ],
+                "summary": "Get process definition",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code of Process",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ProcessDefinitionDTO"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/process/": {
             "post": {
                 "description": "Submits/Creates new process",
@@ -43,15 +230,56 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.ProcessDTO"
+                            "$ref": "#/definitions/model.ProcessDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ProcessSubmitResponse"
                         }
                     }
+                }
+            }
+        },
+        "/api/v1/process/{code}/events": {
+            "get": {
+                "description": "Server-Sent Events of submits and status changes of the processes,\nthe event ID is the position of the change to resume the stream from",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "process"
+                ],
+                "summary": "Stream of process changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code of Process",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the last received event, only new changes are streamed if not set",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Same as Last-Event-ID, for clients unable to set the header",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.ProcessSubmitResponse"
+                            "$ref": "#/definitions/model.ProcessChangeDTO"
                         }
                     }
                 }
@@ -86,15 +314,84 @@ const docTemplate = `{
                         "description": "Page size",
                         "name": "X-Page-Size",
                         "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, overrides X-Page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, overrides X-Page-Size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page, see next_cursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Count total number of matched processes",
+                        "name": "total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Current status, comma separated list is supported",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC 3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, RFC 3339",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Changed at or after, RFC 3339",
+                        "name": "changed_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Changed before, RFC 3339",
+                        "name": "changed_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by created_at or changed_at, prefix - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Payload field filter, e.g. payload.amount[gte]=100, operators: eq, ne, gt, gte, lt, lte, contains",
+                        "name": "payload.{field}",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/api.ProcessDTO"
+                            "$ref": "#/definitions/api.PaginatedResponse"
+                        },
+                        "headers": {
+                            "Link": {
+                                "type": "string",
+                                "description": "RFC 8288 links to the first and the next pages"
                             }
                         }
                     }
@@ -133,7 +430,13 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/api.ProcessDTO"
+                                "$ref": "#/definitions/model.ProcessDTO"
+                            }
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the process"
                             }
                         }
                     }
@@ -175,55 +478,386 @@ const docTemplate = `{
                         "in": "path",
                         "required": true
                     },
+                    {
+                        "type": "string",
+                        "description": "ETag of the process version to change",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate only, the process is not changed. Rejected change is returned as valid: false",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
                     {
                         "description": "ProcessStatus",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.ProcessStatusDTO"
+                            "$ref": "#/definitions/model.ProcessStatusDTO"
                         }
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ProcessValidationResponse"
+                        }
+                    },
                     "204": {
                         "description": "No Content"
                     }
                 }
             }
         },
-        "/v1/Health": {
+        "/api/v1/process/{code}/{uuid}/events": {
             "get": {
-                "description": "get the status of server.",
-                "consumes": [
-                    "*/*"
-                ],
+                "description": "Server-Sent Events of submit and status changes of the process,\nthe event ID is the position of the change to resume the stream from",
                 "produces": [
-                    "application/json"
+                    "text/event-stream"
                 ],
                 "tags": [
-                    "root"
+                    "process"
+                ],
+                "summary": "Stream of changes of the process",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code of Process",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID of Process",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the last received event, only new changes are streamed if not set",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Same as Last-Event-ID, for clients unable to set the header",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Show the status of server.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/model.ProcessChangeDTO"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.Payload": {
-            "type": "object",
-            "additionalProperties": true
         },
-        "api.ProcessDTO": {
-            "type": "object",
+        "/api/v1/process/{code}/{uuid}/schedule": {
+            "get": {
+                "description": "Get status assignments scheduled for the process in order they are to be made",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process"
+                ],
+                "summary": "Get scheduled status assignments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code of Process",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID of Process",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State of the assignment: pending, done, cancelled, failed",
+                        "name": "state",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ScheduledAssignmentDTO"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Schedules the process to be moved into the status at the given time,\nthe transition is validated when it is made and failure is recorded into the assignment",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process"
+                ],
+                "summary": "Schedule status assignment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code of Process",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID of Process",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status, payload, reason and run_at of the assignment",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/model.ScheduledAssignmentDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/model.ScheduledAssignmentDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/process/{code}/{uuid}/schedule/{id}": {
+            "delete": {
+                "description": "Cancels the pending status assignment of the process",
+                "tags": [
+                    "process"
+                ],
+                "summary": "Cancel scheduled status assignment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code of Process",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID of Process",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the assignment",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/api/v1/process/{code}/{uuid}/transitions": {
+            "get": {
+                "description": "Get statuses the process can be moved into from its current status",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "process"
+                ],
+                "summary": "Get allowed transitions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code of Process",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "UUID of Process",
+                        "name": "uuid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TransitionDTO"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/Health": {
+            "get": {
+                "description": "get the status of server.",
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "root"
+                ],
+                "summary": "Show the status of server.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.ChangeFeedResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ProcessChangeDTO"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_after": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "api.PaginatedResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ProcessDTO"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string",
+                    "example": "eyJzIjoiY3JlYXRlZF9hdCIsInQiOiIyMDIzLTEyLTA4VDExOjMzOjU1WiIsImkiOjQyfQ"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "model.NextDefinitionDTO": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "approved"
+                },
+                "require_reason": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "manager"
+                    ]
+                },
+                "when": {
+                    "type": "string",
+                    "example": "payload.amount \u003c 10000"
+                }
+            }
+        },
+        "model.Payload": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "model.ProcessChangeDTO": {
+            "description": "Submit or status change of the process, ID is the position in the stream of changes.",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "requests"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "previous_status": {
+                    "type": "string",
+                    "example": "open"
+                },
+                "status": {
+                    "$ref": "#/definitions/model.ProcessStatusDTO"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "process.status_changed"
+                },
+                "uuid": {
+                    "type": "string",
+                    "example": "23c968a6-5fc5-4e42-8f59-a7f9c0d4999c"
+                }
+            }
+        },
+        "model.ProcessDTO": {
+            "type": "object",
             "properties": {
                 "changed_at": {
                     "type": "string",
@@ -237,41 +871,84 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2023-12-08T11:33:55.418484002-06:00"
                 },
+                "created_by": {
+                    "type": "string",
+                    "example": "alex"
+                },
                 "current_status": {
-                    "$ref": "#/definitions/api.ProcessStatusDTO"
+                    "$ref": "#/definitions/model.ProcessStatusDTO"
                 },
                 "payload": {
-                    "$ref": "#/definitions/api.Payload"
+                    "$ref": "#/definitions/model.Payload"
                 },
                 "statuses": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/api.ProcessStatusDTO"
+                        "$ref": "#/definitions/model.ProcessStatusDTO"
                     }
                 },
                 "uuid": {
                     "type": "string",
                     "example": "23c968a6-5fc5-4e42-8f59-a7f9c0d4999c"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
                 }
             }
         },
-        "api.ProcessStatusDTO": {
+        "model.ProcessDefinitionDTO": {
+            "description": "Process definition: statuses, allowed transitions and payload schemas.",
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "requests"
+                },
+                "schema": {
+                    "type": "object"
+                },
+                "statuses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.StatusDefinitionDTO"
+                    }
+                }
+            }
+        },
+        "model.ProcessStatusDTO": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string",
                     "example": "2023-12-08T11:33:55.418484002-06:00"
                 },
+                "created_by": {
+                    "type": "string",
+                    "example": "alex"
+                },
                 "name": {
                     "type": "string",
                     "example": "created"
                 },
                 "payload": {
-                    "$ref": "#/definitions/api.Payload"
+                    "$ref": "#/definitions/model.Payload"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "customer asked to cancel"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "6e0ed39c-9ec1-4a7a-b6b1-1c8c9c0bfb87"
+                },
+                "source_ip": {
+                    "type": "string",
+                    "example": "192.168.0.1"
                 }
             }
         },
-        "api.ProcessSubmitResponse": {
+        "model.ProcessSubmitResponse": {
             "description": "Response with UUID of created process.",
             "type": "object",
             "properties": {
@@ -280,6 +957,234 @@ const docTemplate = `{
                     "example": "23c968a6-5fc5-4e42-8f59-a7f9c0d4999c"
                 }
             }
+        },
+        "model.ProcessValidationResponse": {
+            "description": "Result of the status assignment dry run, the message and details explain why it is not valid.",
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ValidationErrorDetail"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "reason is required to move the process into the status"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "in_progress"
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "model.ScheduledAssignmentDTO": {
+            "description": "Status assignment scheduled to be made at the given time, only status, payload, reason and run_at are set on request.",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-12-08T11:33:55.418484002-06:00"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "alex"
+                },
+                "executed_at": {
+                    "type": "string",
+                    "example": "2023-12-11T09:00:02.418484002-06:00"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "not allowed status"
+                },
+                "payload": {
+                    "$ref": "#/definitions/model.Payload"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "work starts on Monday"
+                },
+                "run_at": {
+                    "type": "string",
+                    "example": "2023-12-11T09:00:00-06:00"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "pending"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "in_progress"
+                }
+            }
+        },
+        "model.StatusDefinitionDTO": {
+            "type": "object",
+            "properties": {
+                "final": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "initial": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "open"
+                },
+                "next": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.NextDefinitionDTO"
+                    }
+                },
+                "require_reason": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "manager"
+                    ]
+                },
+                "schema": {
+                    "type": "object"
+                }
+            }
+        },
+        "model.TransitionDTO": {
+            "description": "Status the process can be moved into.",
+            "type": "object",
+            "properties": {
+                "final": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "type": "string",
+                    "example": "in_progress"
+                },
+                "require_reason": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "schema": {
+                    "type": "object"
+                },
+                "when": {
+                    "type": "string",
+                    "example": "payload.amount \u003c 10000"
+                }
+            }
+        },
+        "model.ValidationErrorDetail": {
+            "description": "Failed field of the payload",
+            "type": "object",
+            "properties": {
+                "keyword": {
+                    "type": "string",
+                    "example": "minimum"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "must be \u003e= 18 but found 16"
+                },
+                "pointer": {
+                    "type": "string",
+                    "example": "/data/age"
+                }
+            }
+        },
+        "model.WebhookDeliveryDTO": {
+            "description": "Delivery attempt of the webhook event.",
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-12-08T11:33:55.418484002-06:00"
+                },
+                "duration_ms": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "error": {
+                    "type": "string",
+                    "example": "unexpected response status 503"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 503
+                }
+            }
+        },
+        "model.WebhookEventDTO": {
+            "description": "Webhook event of the outbox with its delivery attempts.",
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "body": {
+                    "$ref": "#/definitions/model.Payload"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-12-08T11:33:55.418484002-06:00"
+                },
+                "delivered_at": {
+                    "type": "string",
+                    "example": "2023-12-08T11:33:55.418484002-06:00"
+                },
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WebhookDeliveryDTO"
+                    }
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "unexpected response status 503"
+                },
+                "next_attempt_at": {
+                    "type": "string",
+                    "example": "2023-12-08T11:33:55.418484002-06:00"
+                },
+                "state": {
+                    "type": "string",
+                    "example": "dead"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "process.status_changed"
+                },
+                "webhook": {
+                    "type": "string",
+                    "example": "billing"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,259 @@
+// Package expr - small side effect free expression language used by transition guards,
+// e.g. `payload.amount < 10000 && current_status == "open"`.
+//
+// Supported are number, string ('...' or "..."), true, false and null literals,
+// dotted paths to variables, arithmetic (+ - * /), comparison (== != < <= > >=),
+// logical (&& || !) operators and parentheses. Missing fields evaluate to null.
+package expr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+type (
+	// Expression - parsed expression, safe for concurrent use
+	Expression struct {
+		src  string
+		root node
+	}
+
+	node interface {
+		eval(vars map[string]interface{}) (interface{}, error)
+	}
+
+	literalNode struct {
+		value interface{}
+	}
+
+	pathNode struct {
+		path []string
+	}
+
+	unaryNode struct {
+		op      string
+		operand node
+	}
+
+	binaryNode struct {
+		op    string
+		left  node
+		right node
+	}
+)
+
+var (
+	ErrSyntax     = errors.New("syntax error")
+	ErrEvaluation = errors.New("evaluation error")
+)
+
+// Parse - parses the expression
+func Parse(src string) (*Expression, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpr(1)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("%w: unexpected %q at %d", ErrSyntax, t.text, t.pos)
+	}
+	return &Expression{src: src, root: root}, nil
+}
+
+// String - returns source of the expression
+func (e *Expression) String() string {
+	return e.src
+}
+
+// Eval - evaluates the expression against the variables, numbers are returned as float64
+func (e *Expression) Eval(vars map[string]interface{}) (interface{}, error) {
+	return e.root.eval(vars)
+}
+
+// EvalBool - evaluates the expression which must result in boolean
+func (e *Expression) EvalBool(vars map[string]interface{}) (bool, error) {
+	res, err := e.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := res.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: result is %s, boolean expected", ErrEvaluation, typeName(res))
+	}
+	return b, nil
+}
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *pathNode) eval(vars map[string]interface{}) (interface{}, error) {
+	var current interface{} = vars
+	for _, field := range n.path {
+		v := reflect.ValueOf(current)
+		if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+			return nil, nil
+		}
+		item := v.MapIndex(reflect.ValueOf(field).Convert(v.Type().Key()))
+		if !item.IsValid() {
+			return nil, nil
+		}
+		current = item.Interface()
+	}
+	return normalize(current), nil
+}
+
+func (n *unaryNode) eval(vars map[string]interface{}) (interface{}, error) {
+	val, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "!":
+		b, err := toBool(val)
+		if err != nil {
+			return nil, err
+		}
+		return !b, nil
+	default:
+		num, ok := val.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot negate %s", ErrEvaluation, typeName(val))
+		}
+		return -num, nil
+	}
+}
+
+func (n *binaryNode) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	// logical operators are short-circuit
+	if n.op == "&&" || n.op == "||" {
+		l, err := toBool(left)
+		if err != nil {
+			return nil, err
+		}
+		if (n.op == "&&" && !l) || (n.op == "||" && l) {
+			return l, nil
+		}
+		right, err := n.right.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		return toBool(right)
+	}
+
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==", "!=":
+		// objects and arrays are not comparable
+		if typeName(left) == "object" || typeName(right) == "object" {
+			return nil, fmt.Errorf("%w: operator %s is not supported for %s and %s", ErrEvaluation, n.op, typeName(left), typeName(right))
+		}
+		return (left == right) == (n.op == "=="), nil
+	case "+":
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
+		}
+	}
+
+	if l, ok := left.(float64); ok {
+		if r, ok := right.(float64); ok {
+			switch n.op {
+			case "<":
+				return l < r, nil
+			case "<=":
+				return l <= r, nil
+			case ">":
+				return l > r, nil
+			case ">=":
+				return l >= r, nil
+			case "+":
+				return l + r, nil
+			case "-":
+				return l - r, nil
+			case "*":
+				return l * r, nil
+			case "/":
+				if r == 0 {
+					return nil, fmt.Errorf("%w: division by zero", ErrEvaluation)
+				}
+				return l / r, nil
+			}
+		}
+	}
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			switch n.op {
+			case "<":
+				return l < r, nil
+			case "<=":
+				return l <= r, nil
+			case ">":
+				return l > r, nil
+			case ">=":
+				return l >= r, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("%w: operator %s is not supported for %s and %s", ErrEvaluation, n.op, typeName(left), typeName(right))
+}
+
+// toBool - null is false, other non boolean values are not allowed
+func toBool(val interface{}) (bool, error) {
+	switch v := val.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	}
+	return false, fmt.Errorf("%w: %s cannot be used as boolean", ErrEvaluation, typeName(val))
+}
+
+// normalize - converts values of variables to the types of literals
+func normalize(val interface{}) interface{} {
+	switch v := val.(type) {
+	case nil, bool, string, float64:
+		return v
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case float32:
+		return float64(v)
+	}
+	return val
+}
+
+func typeName(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	}
+	return "object"
+}
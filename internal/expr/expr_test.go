@@ -0,0 +1,98 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr error
+	}{
+		{name: "comparison", src: `payload.amount < 10000`},
+		{name: "logical", src: `!input.rejected && (current_status == "open" || current_status == 'new')`},
+		{name: "arithmetic", src: `payload.amount * 2 - 1 >= -payload.limit / 3`},
+		{name: "literals", src: `true != null && false == false`},
+		{name: "empty", src: ``, wantErr: ErrSyntax},
+		{name: "unknown character", src: `payload.amount = 1`, wantErr: ErrSyntax},
+		{name: "unterminated string", src: `current_status == "open`, wantErr: ErrSyntax},
+		{name: "unbalanced parentheses", src: `(payload.amount < 1`, wantErr: ErrSyntax},
+		{name: "missing operand", src: `payload.amount <`, wantErr: ErrSyntax},
+		{name: "trailing tokens", src: `payload.amount 1`, wantErr: ErrSyntax},
+		{name: "missing field name", src: `payload. < 1`, wantErr: ErrSyntax},
+		{name: "invalid number", src: `1.2.3 < 4`, wantErr: ErrSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.src)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.src, got.String())
+			}
+		})
+	}
+}
+
+func TestEvalBool(t *testing.T) {
+	vars := map[string]interface{}{
+		"payload": map[string]interface{}{
+			"amount":   9999.5,
+			"count":    3,
+			"customer": map[string]interface{}{"name": "alex", "vip": true},
+			"tags":     []interface{}{"a"},
+		},
+		"input":          map[string]interface{}{},
+		"current_status": "open",
+	}
+	tests := []struct {
+		name    string
+		src     string
+		want    bool
+		wantErr error
+	}{
+		{name: "number less", src: `payload.amount < 10000`, want: true},
+		{name: "number greater or equal", src: `payload.amount >= 10000`, want: false},
+		{name: "integer variable", src: `payload.count == 3`, want: true},
+		{name: "arithmetic", src: `payload.count * 2 + 1 == 7 && 10 / 4 == 2.5 && -payload.count < 0`, want: true},
+		{name: "string equal", src: `current_status == "open"`, want: true},
+		{name: "string not equal", src: `current_status != 'open'`, want: false},
+		{name: "string compare", src: `payload.customer.name < "bob"`, want: true},
+		{name: "string concat", src: `payload.customer.name + "!" == "alex!"`, want: true},
+		{name: "nested boolean", src: `payload.customer.vip`, want: true},
+		{name: "missing field is null", src: `input.approved == null`, want: true},
+		{name: "missing field is false", src: `input.approved || !input.rejected`, want: true},
+		{name: "path through value", src: `current_status.name == null`, want: true},
+		{name: "precedence", src: `false && false || true`, want: true},
+		{name: "parentheses", src: `false && (false || true)`, want: false},
+		{name: "short circuit", src: `false && payload.amount / 0 > 1`, want: false},
+		{name: "not boolean result", src: `payload.amount`, wantErr: ErrEvaluation},
+		{name: "mismatched types", src: `payload.amount < "10000"`, wantErr: ErrEvaluation},
+		{name: "missing field compare", src: `input.amount < 10000`, wantErr: ErrEvaluation},
+		{name: "division by zero", src: `payload.amount / 0 > 1`, wantErr: ErrEvaluation},
+		{name: "object compare", src: `payload.tags == null`, wantErr: ErrEvaluation},
+		{name: "not boolean operand", src: `!current_status`, wantErr: ErrEvaluation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Parse(tt.src)
+			assert.Nil(t, err)
+
+			got, err := e.EvalBool(vars)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
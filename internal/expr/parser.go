@@ -0,0 +1,186 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	tokenKind int
+
+	token struct {
+		kind tokenKind
+		text string
+		pos  int
+	}
+
+	parser struct {
+		tokens []token
+		pos    int
+	}
+)
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+// binding powers of the binary operators, higher binds tighter
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6,
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			for i++; i < len(runes) && runes[i] != r; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated string at %d", ErrSyntax, start)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: start})
+		default:
+			if i+1 < len(runes) {
+				if op := string(runes[i : i+2]); op == "&&" || op == "||" || op == "==" || op == "!=" || op == "<=" || op == ">=" {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("()<>+-*/!.", r) {
+				return nil, fmt.Errorf("%w: unexpected %q at %d", ErrSyntax, r, i)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: string(r), pos: i})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// parseExpr - precedence climbing over the binary operators
+func (p *parser) parseExpr(minPrecedence int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		prec, ok := precedence[t.text]
+		if t.kind != tokenOperator || !ok || prec < minPrecedence {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseExpr(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: t.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t := p.peek()
+	if t.kind == tokenOperator && (t.text == "!" || t.text == "-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: t.text, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		num, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid number %q at %d", ErrSyntax, t.text, t.pos)
+		}
+		return &literalNode{value: num}, nil
+	case tokenString:
+		return &literalNode{value: t.text}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+		path := []string{t.text}
+		for p.peek().text == "." && p.peek().kind == tokenOperator {
+			p.next()
+			field := p.next()
+			if field.kind != tokenIdent {
+				return nil, fmt.Errorf("%w: field name expected at %d", ErrSyntax, field.pos)
+			}
+			path = append(path, field.text)
+		}
+		return &pathNode{path: path}, nil
+	case tokenOperator:
+		if t.text == "(" {
+			inner, err := p.parseExpr(1)
+			if err != nil {
+				return nil, err
+			}
+			if closing := p.next(); closing.text != ")" || closing.kind != tokenOperator {
+				return nil, fmt.Errorf("%w: ')' expected at %d", ErrSyntax, closing.pos)
+			}
+			return inner, nil
+		}
+	case tokenEOF:
+		return nil, fmt.Errorf("%w: unexpected end of expression", ErrSyntax)
+	}
+	return nil, fmt.Errorf("%w: unexpected %q at %d", ErrSyntax, t.text, t.pos)
+}
@@ -0,0 +1,348 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed sqlite/*.sql postgres/*.sql mysql/*.sql
+var scripts embed.FS
+
+var (
+	ErrUnknownDialect      = errors.New("no migrations for the db dialect")
+	ErrInvalidMigration    = errors.New("invalid migration")
+	ErrUnknownVersion      = errors.New("unknown migration version")
+	ErrNoAppliedMigrations = errors.New("no applied migrations")
+	ErrSchemaIsBehind      = errors.New("db schema is behind, run migrate up")
+
+	// 0001_create_processes.up.sql
+	fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	// statements of the script end with semicolon at the end of the line
+	statementEndRegexp = regexp.MustCompile(`;[ \t]*(\r?\n|$)`)
+
+	mu sync.RWMutex
+	// dialect name -> scripts, the built-in ones are embedded into the binary
+	dialects = map[string]fs.FS{}
+)
+
+func init() {
+	for _, dialect := range []string{"sqlite", "postgres", "mysql"} {
+		sub, err := fs.Sub(scripts, dialect)
+		if err != nil {
+			panic(err)
+		}
+		dialects[dialect] = sub
+	}
+}
+
+type (
+	// Migration - versioned change of the DB schema, Down reverts Up
+	Migration struct {
+		Version uint
+		Name    string
+		Up      string
+		Down    string
+	}
+
+	// MigrationStatus - migration with the time it is applied at, AppliedAt is nil for pending one
+	MigrationStatus struct {
+		Version   uint
+		Name      string
+		AppliedAt *time.Time
+	}
+
+	// SchemaMigration - row of the schema_migrations table, one per applied migration
+	SchemaMigration struct {
+		Version   uint `gorm:"primaryKey;autoIncrement:false"`
+		Name      string
+		AppliedAt time.Time
+	}
+
+	// Migrator - applies and reverts the migrations of the dialect of the db. Every migration is applied within
+	// single transaction, but MySQL commits DDL statements implicitly, so the failed one has to be fixed by hand there
+	Migrator struct {
+		db         *gorm.DB
+		migrations []Migration
+	}
+)
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Register - sets the scripts of the dialect, gorm.Dialector.Name() of the db is the name of the dialect
+func Register(dialect string, fsys fs.FS) {
+	mu.Lock()
+	defer mu.Unlock()
+	dialects[dialect] = fsys
+}
+
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	dialect := db.Dialector.Name()
+	mu.RLock()
+	fsys, ok := dialects[dialect]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDialect, dialect)
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Load - reads the migrations from <version>_<name>.up.sql and <version>_<name>.down.sql files, ordered by version
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[uint]*Migration{}
+	for _, file := range files {
+		match := fileNameRegexp.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			return nil, fmt.Errorf("%w: unexpected file name %s", ErrInvalidMigration, file)
+		}
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("%w: unexpected version of %s", ErrInvalidMigration, file)
+		}
+		script, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[uint(version)]
+		if !ok {
+			m = &Migration{Version: uint(version), Name: match[2]}
+			byVersion[uint(version)] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("%w: version %d is used by %s and %s", ErrInvalidMigration, version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(script)
+		} else {
+			m.Down = string(script)
+		}
+	}
+
+	res := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if len(m.Up) == 0 || len(m.Down) == 0 {
+			return nil, fmt.Errorf("%w: version %d has to have both up and down scripts", ErrInvalidMigration, m.Version)
+		}
+		res = append(res, *m)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Version < res[j].Version
+	})
+	return res, nil
+}
+
+// Latest - version of the last known migration, 0 if there are none
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status - all known migrations with the time they are applied at
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		res = append(res, status)
+	}
+	return res, nil
+}
+
+// Version - version of the last applied migration, 0 if none of them is applied
+func (m *Migrator) Version(ctx context.Context) (uint, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var version uint
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// Up - applies all pending migrations
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.To(ctx, m.Latest())
+}
+
+// Down - reverts the last applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		return ErrNoAppliedMigrations
+	}
+
+	var target uint
+	for _, migration := range m.migrations {
+		if migration.Version < version {
+			target = migration.Version
+		}
+	}
+	return m.To(ctx, target)
+}
+
+// To - applies or reverts the migrations so the schema is of the given version, 0 reverts all of them
+func (m *Migrator) To(ctx context.Context, version uint) error {
+	if version != 0 && !m.known(version) {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	// pending migrations up to the version in ascending order
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+			if err := m.apply(ctx, migration); err != nil {
+				return err
+			}
+		}
+	}
+	// applied migrations above the version in descending order
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; ok && migration.Version > version {
+			if err := m.revert(ctx, migration); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Check - returns ErrSchemaIsBehind if any of the known migrations is not applied
+func (m *Migrator) Check(ctx context.Context) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			return fmt.Errorf("%w: %d_%s is not applied", ErrSchemaIsBehind, status.Version, status.Name)
+		}
+	}
+	return nil
+}
+
+// apply - runs the up script and records the migration within single transaction
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := exec(tx, migration.Up); err != nil {
+			return err
+		}
+		return tx.Create(&SchemaMigration{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			AppliedAt: time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("cannot apply migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// revert - runs the down script and removes the migration record within single transaction
+func (m *Migrator) revert(ctx context.Context, migration Migration) error {
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := exec(tx, migration.Down); err != nil {
+			return err
+		}
+		return tx.Delete(&SchemaMigration{}, migration.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("cannot revert migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return nil
+}
+
+// applied - applied migrations by version, the schema_migrations table is created if it does not exist
+func (m *Migrator) applied(ctx context.Context) (map[uint]SchemaMigration, error) {
+	db := m.db.WithContext(ctx)
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	var rows []SchemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	res := make(map[uint]SchemaMigration, len(rows))
+	for _, row := range rows {
+		res[row.Version] = row
+	}
+	return res, nil
+}
+
+// exec - runs the statements of the script one by one, not every driver runs multiple statements at once
+func exec(tx *gorm.DB, script string) error {
+	for _, statement := range statements(script) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func statements(script string) []string {
+	var res []string
+	for _, statement := range statementEndRegexp.Split(script, -1) {
+		if statement = strings.TrimSpace(statement); len(statement) > 0 {
+			res = append(res, statement)
+		}
+	}
+	return res
+}
+
+func (m *Migrator) known(version uint) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+	return false
+}
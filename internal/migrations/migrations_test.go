@@ -0,0 +1,310 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func Test_Load(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		want    []Migration
+		wantErr error
+	}{
+		{
+			name: "ordered by version",
+			fsys: fstest.MapFS{
+				"0002_add_index.up.sql":          {Data: []byte("CREATE INDEX")},
+				"0002_add_index.down.sql":        {Data: []byte("DROP INDEX")},
+				"0001_create_processes.up.sql":   {Data: []byte("CREATE TABLE")},
+				"0001_create_processes.down.sql": {Data: []byte("DROP TABLE")},
+			},
+			want: []Migration{
+				{Version: 1, Name: "create_processes", Up: "CREATE TABLE", Down: "DROP TABLE"},
+				{Version: 2, Name: "add_index", Up: "CREATE INDEX", Down: "DROP INDEX"},
+			},
+		},
+		{
+			name: "no migrations",
+			fsys: fstest.MapFS{},
+			want: []Migration{},
+		},
+		{
+			name: "unexpected file name",
+			fsys: fstest.MapFS{
+				"create_processes.sql": {Data: []byte("CREATE TABLE")},
+			},
+			wantErr: ErrInvalidMigration,
+		},
+		{
+			name: "zero version",
+			fsys: fstest.MapFS{
+				"0000_create_processes.up.sql":   {Data: []byte("CREATE TABLE")},
+				"0000_create_processes.down.sql": {Data: []byte("DROP TABLE")},
+			},
+			wantErr: ErrInvalidMigration,
+		},
+		{
+			name: "missing down script",
+			fsys: fstest.MapFS{
+				"0001_create_processes.up.sql": {Data: []byte("CREATE TABLE")},
+			},
+			wantErr: ErrInvalidMigration,
+		},
+		{
+			name: "same version of different migrations",
+			fsys: fstest.MapFS{
+				"0001_create_processes.up.sql": {Data: []byte("CREATE TABLE")},
+				"0001_add_index.down.sql":      {Data: []byte("DROP INDEX")},
+			},
+			wantErr: ErrInvalidMigration,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.fsys)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_LoadEmbedded(t *testing.T) {
+	var latest []uint
+	for _, dialect := range []string{"sqlite", "postgres", "mysql"} {
+		got, err := Load(dialects[dialect])
+		assert.NoError(t, err, dialect)
+		for i, m := range got {
+			assert.Equal(t, uint(i+1), m.Version, "%s versions have no gaps", dialect)
+		}
+		latest = append(latest, uint(len(got)))
+	}
+	assert.Equal(t, []uint{latest[0], latest[0], latest[0]}, latest, "every dialect has the same migrations")
+}
+
+func Test_statements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "single statement",
+			script: "DROP TABLE `processes`;\n",
+			want:   []string{"DROP TABLE `processes`"},
+		},
+		{
+			name:   "no semicolon at the end",
+			script: "DROP TABLE `processes`",
+			want:   []string{"DROP TABLE `processes`"},
+		},
+		{
+			name:   "multiline statements with comments",
+			script: "-- tables\nCREATE TABLE `a` (\n  `id` integer\n);\r\n\nUPDATE `a` SET `id` = 1\nWHERE `id` = 0; \n",
+			want: []string{
+				"-- tables\nCREATE TABLE `a` (\n  `id` integer\n)",
+				"UPDATE `a` SET `id` = 1\nWHERE `id` = 0",
+			},
+		},
+		{
+			name:   "semicolon within the line",
+			script: "UPDATE `a` SET `name` = 'a;b';",
+			want:   []string{"UPDATE `a` SET `name` = 'a;b'"},
+		},
+		{
+			name:   "empty",
+			script: "\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, statements(tt.script))
+		})
+	}
+}
+
+// baselineProcess and baselineProcessStatus - models of the first release, its schema is created by AutoMigrate
+type (
+	baselineProcess struct {
+		gorm.Model
+		UUID          string
+		Code          string
+		Payload       datatypes.JSON
+		CurrentStatus baselineProcessStatus   `gorm:"foreignKey:ProcessID"`
+		Statuses      []baselineProcessStatus `gorm:"foreignKey:ProcessID"`
+	}
+
+	baselineProcessStatus struct {
+		gorm.Model
+		ProcessID uint
+		Name      string
+		Payload   datatypes.JSON
+	}
+)
+
+func (baselineProcess) TableName() string {
+	return "processes"
+}
+
+func (baselineProcessStatus) TableName() string {
+	return "process_statuses"
+}
+
+func testDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	assert.NoError(t, err)
+	return db
+}
+
+func TestMigrator_Up(t *testing.T) {
+	ctx := context.Background()
+	startedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		// schema and data of the previous release
+		setup             func(t *testing.T, db *gorm.DB)
+		wantCurrentStatus map[string]string
+	}{
+		{
+			name:  "empty db",
+			setup: func(t *testing.T, db *gorm.DB) {},
+		},
+		{
+			name: "baseline schema",
+			setup: func(t *testing.T, db *gorm.DB) {
+				assert.NoError(t, db.AutoMigrate(&baselineProcess{}, &baselineProcessStatus{}))
+				processes := []baselineProcess{
+					{UUID: "approved", Code: "requests", Statuses: []baselineProcessStatus{
+						{Name: "open", Model: gorm.Model{CreatedAt: startedAt}},
+						{Name: "approved", Model: gorm.Model{CreatedAt: startedAt.Add(time.Minute)}},
+					}},
+					{UUID: "open", Code: "requests", Statuses: []baselineProcessStatus{
+						{Name: "open", Model: gorm.Model{CreatedAt: startedAt}},
+					}},
+					{UUID: "no statuses", Code: "requests"},
+				}
+				assert.NoError(t, db.Create(&processes).Error)
+			},
+			wantCurrentStatus: map[string]string{
+				"approved":    "approved",
+				"open":        "open",
+				"no statuses": "",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			tt.setup(t, db)
+
+			migrator, err := NewMigrator(db)
+			assert.NoError(t, err)
+			assert.NoError(t, migrator.Up(ctx))
+			assert.NoError(t, migrator.Check(ctx))
+
+			for uuid, wantStatus := range tt.wantCurrentStatus {
+				var process model.Process
+				assert.NoError(t, db.Where("uuid = ?", uuid).First(&process).Error)
+				assert.Equal(t, wantStatus, process.CurrentStatus, uuid)
+				assert.Equal(t, uint(1), process.Version, uuid)
+			}
+
+			// the schema is the one the code expects
+			process := model.Process{UUID: "new", Code: "requests", CurrentStatus: "open", CreatedBy: "alex", Statuses: model.ProcessStatusList{
+				{Name: "open", CreatedBy: "alex", Reason: "submitted", SourceIP: "127.0.0.1", RequestID: "1"},
+			}}
+			assert.NoError(t, db.Create(&process).Error)
+
+			// every down script reverts its up script
+			assert.NoError(t, migrator.To(ctx, 0))
+			assert.False(t, db.Migrator().HasTable("processes"))
+			assert.NoError(t, migrator.Up(ctx))
+		})
+	}
+}
+
+func TestMigrator_CurrentStatusBackfill(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+	migrator, err := NewMigrator(db)
+	assert.NoError(t, err)
+	// the schema before the current_status column is added
+	assert.NoError(t, migrator.To(ctx, 5))
+
+	createdAt := time.Now().Add(-time.Hour)
+	deletedAt := gorm.DeletedAt{Time: createdAt, Valid: true}
+	processes := []baselineProcess{
+		{UUID: "latest created", Statuses: []baselineProcessStatus{
+			{Name: "approved", Model: gorm.Model{CreatedAt: createdAt.Add(time.Minute)}},
+			{Name: "open", Model: gorm.Model{CreatedAt: createdAt}},
+		}},
+		{UUID: "same time", Statuses: []baselineProcessStatus{
+			{Name: "open", Model: gorm.Model{CreatedAt: createdAt}},
+			{Name: "approved", Model: gorm.Model{CreatedAt: createdAt}},
+		}},
+		{UUID: "deleted status", Statuses: []baselineProcessStatus{
+			{Name: "open", Model: gorm.Model{CreatedAt: createdAt}},
+			{Name: "approved", Model: gorm.Model{CreatedAt: createdAt.Add(time.Minute), DeletedAt: deletedAt}},
+		}},
+		{UUID: "no statuses"},
+	}
+	assert.NoError(t, db.Create(&processes).Error)
+
+	assert.NoError(t, migrator.To(ctx, 6))
+
+	want := map[string]string{
+		"latest created": "approved",
+		"same time":      "approved",
+		"deleted status": "open",
+		"no statuses":    "",
+	}
+	for uuid, wantStatus := range want {
+		var process model.Process
+		assert.NoError(t, db.Select("id", "uuid", "current_status").Where("uuid = ?", uuid).First(&process).Error)
+		assert.Equal(t, wantStatus, process.CurrentStatus, uuid)
+	}
+}
+
+func TestMigrator_ChangeSeqBackfill(t *testing.T) {
+	ctx := context.Background()
+	db := testDB(t)
+	migrator, err := NewMigrator(db)
+	assert.NoError(t, err)
+	// the schema before the seq column is added
+	assert.NoError(t, migrator.To(ctx, 7))
+
+	processes := []baselineProcess{
+		{UUID: "first", Statuses: []baselineProcessStatus{{Name: "open"}, {Name: "approved"}}},
+		{UUID: "second", Statuses: []baselineProcessStatus{{Name: "open"}}},
+	}
+	assert.NoError(t, db.Create(&processes).Error)
+
+	assert.NoError(t, migrator.To(ctx, 8))
+
+	// the changes made before keep their IDs as the position
+	var statuses []model.ProcessStatus
+	assert.NoError(t, db.Order("id").Find(&statuses).Error)
+	assert.Len(t, statuses, 3)
+	for _, status := range statuses {
+		assert.Equal(t, status.ID, status.Seq)
+	}
+	var seq uint
+	assert.NoError(t, db.Raw("SELECT seq FROM process_change_seqs WHERE id = 1").Scan(&seq).Error)
+	assert.Equal(t, uint(3), seq)
+}
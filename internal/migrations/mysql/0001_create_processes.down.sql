@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `process_statuses`;
+DROP TABLE IF EXISTS `processes`;
@@ -0,0 +1,26 @@
+-- schema of the first release, IF NOT EXISTS adopts the tables created by its AutoMigrate
+CREATE TABLE IF NOT EXISTS `processes` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `uuid` longtext,
+    `code` longtext,
+    `payload` JSON,
+    PRIMARY KEY (`id`),
+    INDEX `idx_processes_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `process_statuses` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `process_id` bigint unsigned,
+    `name` longtext,
+    `payload` JSON,
+    PRIMARY KEY (`id`),
+    INDEX `idx_process_statuses_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_processes_current_status` FOREIGN KEY (`process_id`) REFERENCES `processes`(`id`),
+    CONSTRAINT `fk_processes_statuses` FOREIGN KEY (`process_id`) REFERENCES `processes`(`id`)
+);
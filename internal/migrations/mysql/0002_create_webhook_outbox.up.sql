@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS `webhook_events` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `webhook` varchar(191),
+    `type` longtext,
+    `process_id` bigint unsigned,
+    `body` JSON,
+    `state` varchar(191) NOT NULL DEFAULT 'pending',
+    `attempts` bigint NOT NULL DEFAULT 0,
+    `next_attempt_at` datetime(3) NULL,
+    `last_error` longtext,
+    `delivered_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_webhook_events_process_id` (`process_id`),
+    INDEX `idx_webhook_events_state` (`state`),
+    INDEX `idx_webhook_events_next_attempt_at` (`next_attempt_at`),
+    INDEX `idx_webhook_events_deleted_at` (`deleted_at`),
+    INDEX `idx_webhook_events_webhook` (`webhook`)
+);
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `event_id` bigint unsigned,
+    `attempt` bigint,
+    `status_code` bigint,
+    `error` longtext,
+    `duration_ms` bigint,
+    PRIMARY KEY (`id`),
+    INDEX `idx_webhook_deliveries_deleted_at` (`deleted_at`),
+    INDEX `idx_webhook_deliveries_event_id` (`event_id`),
+    CONSTRAINT `fk_webhook_events_deliveries` FOREIGN KEY (`event_id`) REFERENCES `webhook_events`(`id`)
+);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS `process_timers` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `process_id` bigint unsigned,
+    `code` longtext,
+    `uuid` longtext,
+    `version` bigint unsigned,
+    `status` longtext,
+    `on_timeout` longtext,
+    `due_at` datetime(3) NULL,
+    `state` varchar(191) NOT NULL DEFAULT 'pending',
+    `last_error` longtext,
+    PRIMARY KEY (`id`),
+    INDEX `idx_process_timers_process_id` (`process_id`),
+    INDEX `idx_process_timers_due_at` (`due_at`),
+    INDEX `idx_process_timers_state` (`state`),
+    INDEX `idx_process_timers_deleted_at` (`deleted_at`)
+);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS `scheduled_assignments` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `process_id` bigint unsigned,
+    `code` longtext,
+    `uuid` longtext,
+    `status` longtext,
+    `payload` JSON,
+    `reason` longtext,
+    `run_at` datetime(3) NULL,
+    `state` varchar(191) NOT NULL DEFAULT 'pending',
+    `locked_until` datetime(3) NULL,
+    `last_error` longtext,
+    `executed_at` datetime(3) NULL,
+    `created_by` longtext,
+    `created_by_roles` JSON,
+    PRIMARY KEY (`id`),
+    INDEX `idx_scheduled_assignments_state` (`state`),
+    INDEX `idx_scheduled_assignments_deleted_at` (`deleted_at`),
+    INDEX `idx_scheduled_assignments_process_id` (`process_id`),
+    INDEX `idx_scheduled_assignments_run_at` (`run_at`)
+);
@@ -0,0 +1 @@
+ALTER TABLE `processes` ADD COLUMN `version` bigint unsigned NOT NULL DEFAULT 1;
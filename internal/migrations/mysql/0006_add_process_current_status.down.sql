@@ -0,0 +1,2 @@
+DROP INDEX `idx_processes_current_status` ON `processes`;
+ALTER TABLE `processes` DROP COLUMN `current_status`;
@@ -0,0 +1,5 @@
+ALTER TABLE `process_statuses` DROP COLUMN `request_id`;
+ALTER TABLE `process_statuses` DROP COLUMN `source_ip`;
+ALTER TABLE `process_statuses` DROP COLUMN `reason`;
+ALTER TABLE `process_statuses` DROP COLUMN `created_by`;
+ALTER TABLE `processes` DROP COLUMN `created_by`;
@@ -0,0 +1,5 @@
+ALTER TABLE `processes` ADD COLUMN `created_by` longtext;
+ALTER TABLE `process_statuses` ADD COLUMN `created_by` longtext;
+ALTER TABLE `process_statuses` ADD COLUMN `reason` longtext;
+ALTER TABLE `process_statuses` ADD COLUMN `source_ip` longtext;
+ALTER TABLE `process_statuses` ADD COLUMN `request_id` longtext;
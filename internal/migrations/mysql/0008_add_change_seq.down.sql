@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `process_change_seqs`;
+DROP INDEX `idx_process_statuses_seq` ON `process_statuses`;
+ALTER TABLE `process_statuses` DROP COLUMN `seq`;
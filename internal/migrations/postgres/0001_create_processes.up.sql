@@ -0,0 +1,26 @@
+-- schema of the first release, IF NOT EXISTS adopts the tables created by its AutoMigrate
+CREATE TABLE IF NOT EXISTS "processes" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "uuid" text,
+    "code" text,
+    "payload" JSONB,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_processes_deleted_at" ON "processes" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "process_statuses" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "process_id" bigint,
+    "name" text,
+    "payload" JSONB,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_processes_current_status" FOREIGN KEY ("process_id") REFERENCES "processes"("id"),
+    CONSTRAINT "fk_processes_statuses" FOREIGN KEY ("process_id") REFERENCES "processes"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_process_statuses_deleted_at" ON "process_statuses" ("deleted_at");
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS "webhook_events" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "webhook" text,
+    "type" text,
+    "process_id" bigint,
+    "body" JSONB,
+    "state" text NOT NULL DEFAULT 'pending',
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz,
+    "last_error" text,
+    "delivered_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_events_next_attempt_at" ON "webhook_events" ("next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_webhook_events_state" ON "webhook_events" ("state");
+CREATE INDEX IF NOT EXISTS "idx_webhook_events_process_id" ON "webhook_events" ("process_id");
+CREATE INDEX IF NOT EXISTS "idx_webhook_events_webhook" ON "webhook_events" ("webhook");
+CREATE INDEX IF NOT EXISTS "idx_webhook_events_deleted_at" ON "webhook_events" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "webhook_deliveries" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "event_id" bigint,
+    "attempt" bigint,
+    "status_code" bigint,
+    "error" text,
+    "duration_ms" bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_webhook_events_deliveries" FOREIGN KEY ("event_id") REFERENCES "webhook_events"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_event_id" ON "webhook_deliveries" ("event_id");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_deleted_at" ON "webhook_deliveries" ("deleted_at");
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS "process_timers" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "process_id" bigint,
+    "code" text,
+    "uuid" text,
+    "version" bigint,
+    "status" text,
+    "on_timeout" text,
+    "due_at" timestamptz,
+    "state" text NOT NULL DEFAULT 'pending',
+    "last_error" text,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_process_timers_state" ON "process_timers" ("state");
+CREATE INDEX IF NOT EXISTS "idx_process_timers_due_at" ON "process_timers" ("due_at");
+CREATE INDEX IF NOT EXISTS "idx_process_timers_process_id" ON "process_timers" ("process_id");
+CREATE INDEX IF NOT EXISTS "idx_process_timers_deleted_at" ON "process_timers" ("deleted_at");
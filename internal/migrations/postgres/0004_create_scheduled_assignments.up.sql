@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS "scheduled_assignments" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "process_id" bigint,
+    "code" text,
+    "uuid" text,
+    "status" text,
+    "payload" JSONB,
+    "reason" text,
+    "run_at" timestamptz,
+    "state" text NOT NULL DEFAULT 'pending',
+    "locked_until" timestamptz,
+    "last_error" text,
+    "executed_at" timestamptz,
+    "created_by" text,
+    "created_by_roles" JSONB,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_scheduled_assignments_state" ON "scheduled_assignments" ("state");
+CREATE INDEX IF NOT EXISTS "idx_scheduled_assignments_run_at" ON "scheduled_assignments" ("run_at");
+CREATE INDEX IF NOT EXISTS "idx_scheduled_assignments_process_id" ON "scheduled_assignments" ("process_id");
+CREATE INDEX IF NOT EXISTS "idx_scheduled_assignments_deleted_at" ON "scheduled_assignments" ("deleted_at");
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "idx_processes_current_status";
+ALTER TABLE "processes" DROP COLUMN "current_status";
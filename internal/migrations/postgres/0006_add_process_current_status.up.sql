@@ -0,0 +1,11 @@
+ALTER TABLE "processes" ADD COLUMN "current_status" text;
+CREATE INDEX IF NOT EXISTS "idx_processes_current_status" ON "processes" ("current_status");
+
+-- the latest status of the processes created before the column was added
+UPDATE "processes" SET "current_status" = (
+    SELECT ps."name" FROM "process_statuses" ps
+    WHERE ps."process_id" = "processes"."id" AND ps."deleted_at" IS NULL
+    ORDER BY ps."created_at" DESC, ps."id" DESC
+    LIMIT 1
+)
+WHERE "current_status" IS NULL OR "current_status" = '';
@@ -0,0 +1,5 @@
+ALTER TABLE "processes" ADD COLUMN "created_by" text;
+ALTER TABLE "process_statuses" ADD COLUMN "created_by" text;
+ALTER TABLE "process_statuses" ADD COLUMN "reason" text;
+ALTER TABLE "process_statuses" ADD COLUMN "source_ip" text;
+ALTER TABLE "process_statuses" ADD COLUMN "request_id" text;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "process_change_seqs";
+DROP INDEX IF EXISTS "idx_process_statuses_seq";
+ALTER TABLE "process_statuses" DROP COLUMN "seq";
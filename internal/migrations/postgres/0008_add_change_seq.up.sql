@@ -0,0 +1,12 @@
+-- position of the status in the stream of changes, taken from the counter row by the writing transaction,
+-- the changes made before keep their IDs as the position
+ALTER TABLE "process_statuses" ADD COLUMN "seq" bigint;
+UPDATE "process_statuses" SET "seq" = "id";
+CREATE INDEX IF NOT EXISTS "idx_process_statuses_seq" ON "process_statuses" ("seq");
+
+CREATE TABLE IF NOT EXISTS "process_change_seqs" (
+    "id" bigint,
+    "seq" bigint NOT NULL,
+    PRIMARY KEY ("id")
+);
+INSERT INTO "process_change_seqs" ("id", "seq") SELECT 1, COALESCE(MAX("id"), 0) FROM "process_statuses";
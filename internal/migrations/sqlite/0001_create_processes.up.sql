@@ -0,0 +1,24 @@
+-- schema of the first release, IF NOT EXISTS adopts the tables created by its AutoMigrate
+CREATE TABLE IF NOT EXISTS `processes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `uuid` text,
+    `code` text,
+    `payload` JSON
+);
+CREATE INDEX IF NOT EXISTS `idx_processes_deleted_at` ON `processes`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `process_statuses` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `process_id` integer,
+    `name` text,
+    `payload` JSON,
+    CONSTRAINT `fk_processes_current_status` FOREIGN KEY (`process_id`) REFERENCES `processes`(`id`),
+    CONSTRAINT `fk_processes_statuses` FOREIGN KEY (`process_id`) REFERENCES `processes`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_process_statuses_deleted_at` ON `process_statuses`(`deleted_at`);
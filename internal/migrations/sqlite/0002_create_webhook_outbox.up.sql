@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS `webhook_events` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `webhook` text,
+    `type` text,
+    `process_id` integer,
+    `body` JSON,
+    `state` text NOT NULL DEFAULT 'pending',
+    `attempts` integer NOT NULL DEFAULT 0,
+    `next_attempt_at` datetime,
+    `last_error` text,
+    `delivered_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_webhook_events_next_attempt_at` ON `webhook_events`(`next_attempt_at`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_events_state` ON `webhook_events`(`state`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_events_process_id` ON `webhook_events`(`process_id`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_events_webhook` ON `webhook_events`(`webhook`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_events_deleted_at` ON `webhook_events`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `webhook_deliveries` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `event_id` integer,
+    `attempt` integer,
+    `status_code` integer,
+    `error` text,
+    `duration_ms` integer,
+    CONSTRAINT `fk_webhook_events_deliveries` FOREIGN KEY (`event_id`) REFERENCES `webhook_events`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_event_id` ON `webhook_deliveries`(`event_id`);
+CREATE INDEX IF NOT EXISTS `idx_webhook_deliveries_deleted_at` ON `webhook_deliveries`(`deleted_at`);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `process_timers` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `process_id` integer,
+    `code` text,
+    `uuid` text,
+    `version` integer,
+    `status` text,
+    `on_timeout` text,
+    `due_at` datetime,
+    `state` text NOT NULL DEFAULT 'pending',
+    `last_error` text
+);
+CREATE INDEX IF NOT EXISTS `idx_process_timers_process_id` ON `process_timers`(`process_id`);
+CREATE INDEX IF NOT EXISTS `idx_process_timers_deleted_at` ON `process_timers`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_process_timers_state` ON `process_timers`(`state`);
+CREATE INDEX IF NOT EXISTS `idx_process_timers_due_at` ON `process_timers`(`due_at`);
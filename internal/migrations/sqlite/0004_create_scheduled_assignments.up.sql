@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS `scheduled_assignments` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `process_id` integer,
+    `code` text,
+    `uuid` text,
+    `status` text,
+    `payload` JSON,
+    `reason` text,
+    `run_at` datetime,
+    `state` text NOT NULL DEFAULT 'pending',
+    `locked_until` datetime,
+    `last_error` text,
+    `executed_at` datetime,
+    `created_by` text,
+    `created_by_roles` JSON
+);
+CREATE INDEX IF NOT EXISTS `idx_scheduled_assignments_state` ON `scheduled_assignments`(`state`);
+CREATE INDEX IF NOT EXISTS `idx_scheduled_assignments_run_at` ON `scheduled_assignments`(`run_at`);
+CREATE INDEX IF NOT EXISTS `idx_scheduled_assignments_process_id` ON `scheduled_assignments`(`process_id`);
+CREATE INDEX IF NOT EXISTS `idx_scheduled_assignments_deleted_at` ON `scheduled_assignments`(`deleted_at`);
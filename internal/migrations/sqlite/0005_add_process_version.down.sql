@@ -0,0 +1 @@
+ALTER TABLE `processes` DROP COLUMN `version`;
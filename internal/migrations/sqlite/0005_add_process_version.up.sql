@@ -0,0 +1 @@
+ALTER TABLE `processes` ADD COLUMN `version` integer NOT NULL DEFAULT 1;
@@ -0,0 +1,28 @@
+package model
+
+const SYSTEM_ACTOR_ID = "system"
+
+type (
+	// Actor - caller of the API, resolved by the authenticator.
+	// System actor is the engine itself, it is never resolved from the request
+	Actor struct {
+		ID     string   `json:"id,omitempty" example:"alex"`
+		Roles  []string `json:"roles,omitempty" example:"manager"`
+		System bool     `json:"-"`
+	}
+)
+
+// SystemActor - actor of the changes made by the engine, e.g. on timeout of the status
+var SystemActor = Actor{ID: SYSTEM_ACTOR_ID, System: true}
+
+// HasAnyRole - checks if the actor has at least one of the roles
+func (a Actor) HasAnyRole(roles []string) bool {
+	for _, role := range roles {
+		for _, r := range a.Roles {
+			if r == role {
+				return true
+			}
+		}
+	}
+	return false
+}
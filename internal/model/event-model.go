@@ -0,0 +1,20 @@
+package model
+
+const (
+	EVENT_PROCESS_SUBMITTED            = "process.submitted"
+	EVENT_PROCESS_BEFORE_STATUS_CHANGE = "process.before_status_change"
+	EVENT_PROCESS_STATUS_CHANGED       = "process.status_changed"
+)
+
+type (
+	// ProcessEvent - lifecycle event of the process passed to the hooks.
+	// Before is nil on submit, After is nil before the status change
+	ProcessEvent struct {
+		Type   string
+		Actor  Actor
+		Before *ProcessDTO
+		After  *ProcessDTO
+		Status ProcessStatusDTO
+		DryRun bool
+	}
+)
@@ -0,0 +1,31 @@
+package model
+
+import "encoding/json"
+
+type (
+	ProcessDefinitionListDTO []ProcessDefinitionDTO
+
+	// @Description Process definition: statuses, allowed transitions and payload schemas.
+	ProcessDefinitionDTO struct {
+		Name     string                `json:"name" example:"requests"`
+		Schema   json.RawMessage       `json:"schema,omitempty" swaggertype:"object"`
+		Statuses []StatusDefinitionDTO `json:"statuses"`
+	}
+
+	StatusDefinitionDTO struct {
+		Name          string              `json:"name" example:"open"`
+		Initial       bool                `json:"initial" example:"true"`
+		Final         bool                `json:"final" example:"false"`
+		Next          []NextDefinitionDTO `json:"next"`
+		Schema        json.RawMessage     `json:"schema,omitempty" swaggertype:"object"`
+		Roles         []string            `json:"roles,omitempty" example:"manager"`
+		RequireReason bool                `json:"require_reason,omitempty" example:"true"`
+	}
+
+	NextDefinitionDTO struct {
+		Name          string   `json:"name" example:"approved"`
+		When          string   `json:"when,omitempty" example:"payload.amount < 10000"`
+		Roles         []string `json:"roles,omitempty" example:"manager"`
+		RequireReason bool     `json:"require_reason,omitempty" example:"true"`
+	}
+)
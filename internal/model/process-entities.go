@@ -2,6 +2,7 @@ package model
 
 import (
 	"encoding/json"
+	"time"
 
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
@@ -15,31 +16,65 @@ type (
 		UUID          string
 		Code          string
 		Payload       datatypes.JSON
-		CurrentStatus ProcessStatus
+		Version       uint   `gorm:"not null;default:1"`
+		CurrentStatus string `gorm:"index"`
+		CreatedBy     string
 		Statuses      ProcessStatusList
 	}
 
 	ProcessStatusList []ProcessStatus
 
+	// ProcessStatus - record of the status history, Seq is the position of the change in the stream of changes
 	ProcessStatus struct {
 		gorm.Model
 		ProcessID uint
+		Seq       uint `gorm:"index"`
 		Name      string
 		Payload   datatypes.JSON
+		CreatedBy string
+		Reason    string
+		SourceIP  string
+		RequestID string
+	}
+
+	ProcessChangeList []ProcessChange
+
+	// ProcessChange - record of the status history joined with its process,
+	// PreviousStatus is empty for the initial status set on submit
+	ProcessChange struct {
+		ID             uint
+		Code           string
+		UUID           string
+		PreviousStatus string
+		Name           string
+		Payload        datatypes.JSON
+		CreatedBy      string
+		Reason         string
+		SourceIP       string
+		RequestID      string
+		CreatedAt      time.Time
 	}
 )
 
 func (p Process) ToDTO() ProcessDTO {
 	var status *ProcessStatusDTO
-	if len(p.CurrentStatus.Name) > 0 {
-		status = p.CurrentStatus.ToDTO()
+	if len(p.CurrentStatus) > 0 {
+		status = &ProcessStatusDTO{
+			Name: p.CurrentStatus,
+		}
+		// Use full status details when the history is loaded
+		if latest := p.Statuses.Latest(); latest != nil && latest.Name == p.CurrentStatus {
+			status = latest.ToDTO()
+		}
 	}
 	return ProcessDTO{
 		UUID:          p.UUID,
 		Code:          p.Code,
 		Payload:       ToDTO(p.Payload),
+		Version:       p.Version,
 		CurrentStatus: status,
 		Statuses:      p.Statuses.ToDTO(),
+		CreatedBy:     p.CreatedBy,
 		CreatedAt:     &p.CreatedAt,
 		ChangedAt:     &p.UpdatedAt,
 	}
@@ -49,19 +84,34 @@ func (p *ProcessStatus) ToDTO() *ProcessStatusDTO {
 	return &ProcessStatusDTO{
 		Name:      p.Name,
 		Payload:   ToDTO(p.Payload),
+		CreatedBy: p.CreatedBy,
+		Reason:    p.Reason,
+		SourceIP:  p.SourceIP,
+		RequestID: p.RequestID,
 		CreatedAt: &p.CreatedAt,
 	}
 }
 
 func (pp ProcessStatusList) ToDTO() ProcessStatusListDTO {
 	res := ProcessStatusListDTO{}
-	for _, p := range pp {
-		res = append(res, *p.ToDTO())
+	for i := range pp {
+		res = append(res, *pp[i].ToDTO())
 	}
 
 	return res
 }
 
+// Latest - returns the most recently added status
+func (pp ProcessStatusList) Latest() *ProcessStatus {
+	var latest *ProcessStatus
+	for i := range pp {
+		if latest == nil || pp[i].ID > latest.ID {
+			latest = &pp[i]
+		}
+	}
+	return latest
+}
+
 func (pl ProcessList) ToDTO() ProcessListDTO {
 	res := ProcessListDTO{}
 	for _, p := range pl {
@@ -78,3 +128,34 @@ func ToDTO(d datatypes.JSON) Payload {
 	}
 	return payload
 }
+
+func (c ProcessChange) ToDTO() ProcessChangeDTO {
+	eventType := EVENT_PROCESS_STATUS_CHANGED
+	if len(c.PreviousStatus) == 0 {
+		eventType = EVENT_PROCESS_SUBMITTED
+	}
+	return ProcessChangeDTO{
+		ID:             c.ID,
+		Type:           eventType,
+		Code:           c.Code,
+		UUID:           c.UUID,
+		PreviousStatus: c.PreviousStatus,
+		Status: ProcessStatusDTO{
+			Name:      c.Name,
+			Payload:   ToDTO(c.Payload),
+			CreatedBy: c.CreatedBy,
+			Reason:    c.Reason,
+			SourceIP:  c.SourceIP,
+			RequestID: c.RequestID,
+			CreatedAt: &c.CreatedAt,
+		},
+	}
+}
+
+func (cl ProcessChangeList) ToDTO() ProcessChangeListDTO {
+	res := ProcessChangeListDTO{}
+	for _, c := range cl {
+		res = append(res, c.ToDTO())
+	}
+	return res
+}
@@ -14,8 +14,10 @@ type (
 		UUID          string               `json:"uuid,omitempty" example:"23c968a6-5fc5-4e42-8f59-a7f9c0d4999c"`
 		Code          string               `json:"code" example:"requests"`
 		Payload       Payload              `json:"payload,omitempty"`
+		Version       uint                 `json:"version,omitempty" example:"3"`
 		CurrentStatus *ProcessStatusDTO    `json:"current_status,omitempty"`
 		Statuses      ProcessStatusListDTO `json:"statuses,omitempty"`
+		CreatedBy     string               `json:"created_by,omitempty" example:"alex"`
 		CreatedAt     *time.Time           `json:"created_at,omitempty" example:"2023-12-08T11:33:55.418484002-06:00"`
 		ChangedAt     *time.Time           `json:"changed_at,omitempty" example:"2023-12-10T12:30:55.442484002-06:00"`
 	}
@@ -27,6 +29,10 @@ type (
 	ProcessStatusDTO struct {
 		Name      string     `json:"name,omitempty" example:"created"`
 		Payload   Payload    `json:"payload,omitempty"`
+		Reason    string     `json:"reason,omitempty" example:"customer asked to cancel"`
+		CreatedBy string     `json:"created_by,omitempty" example:"alex"`
+		SourceIP  string     `json:"source_ip,omitempty" example:"192.168.0.1"`
+		RequestID string     `json:"request_id,omitempty" example:"6e0ed39c-9ec1-4a7a-b6b1-1c8c9c0bfb87"`
 		CreatedAt *time.Time `json:"created_at,omitempty" example:"2023-12-08T11:33:55.418484002-06:00"`
 	}
 
@@ -36,10 +42,49 @@ type (
 		Uuid string `json:"uuid" example:"23c968a6-5fc5-4e42-8f59-a7f9c0d4999c"`
 	}
 
+	ProcessChangeListDTO []ProcessChangeDTO
+
+	// @Description Submit or status change of the process, ID is the position in the stream of changes.
+	ProcessChangeDTO struct {
+		ID             uint             `json:"id" example:"42"`
+		Type           string           `json:"type" example:"process.status_changed"`
+		Code           string           `json:"code" example:"requests"`
+		UUID           string           `json:"uuid" example:"23c968a6-5fc5-4e42-8f59-a7f9c0d4999c"`
+		PreviousStatus string           `json:"previous_status,omitempty" example:"open"`
+		Status         ProcessStatusDTO `json:"status"`
+	}
+
+	TransitionListDTO []TransitionDTO
+
+	// @Description Status the process can be moved into.
+	TransitionDTO struct {
+		Name          string          `json:"name" example:"in_progress"`
+		Final         bool            `json:"final" example:"false"`
+		When          string          `json:"when,omitempty" example:"payload.amount < 10000"`
+		RequireReason bool            `json:"require_reason,omitempty" example:"true"`
+		Schema        json.RawMessage `json:"schema,omitempty" swaggertype:"object"`
+	}
+
+	// @Description Result of the status assignment dry run, the message and details explain why it is not valid.
+	ProcessValidationResponse struct {
+		Valid   bool                    `json:"valid" example:"false"`
+		Status  string                  `json:"status" example:"in_progress"`
+		Message string                  `json:"message,omitempty" example:"reason is required to move the process into the status"`
+		Details []ValidationErrorDetail `json:"details,omitempty"`
+	}
+
 	// @Description Error message
 	ProcessErrorResponse struct {
-		Status  string `json:"status" example:"error"`
-		Message string `json:"message" example:"no process found"`
+		Status  string                  `json:"status" example:"error"`
+		Message string                  `json:"message" example:"no process found"`
+		Details []ValidationErrorDetail `json:"details,omitempty"`
+	}
+
+	// @Description Failed field of the payload
+	ValidationErrorDetail struct {
+		Pointer string `json:"pointer" example:"/data/age"`
+		Keyword string `json:"keyword" example:"minimum"`
+		Message string `json:"message" example:"must be >= 18 but found 16"`
 	}
 )
 
@@ -49,9 +94,13 @@ func (p *ProcessDTO) ToEntity() *Process {
 		statuses = p.Statuses.ToEntity()
 	}
 
-	var curentStatus ProcessStatus
+	var curentStatus string
 	if p.CurrentStatus != nil {
-		curentStatus = p.CurrentStatus.ToEntity()
+		curentStatus = p.CurrentStatus.Name
+		// History always starts with the current status
+		if len(statuses) == 0 {
+			statuses = ProcessStatusList{p.CurrentStatus.ToEntity()}
+		}
 	}
 
 	return &Process{
@@ -59,6 +108,7 @@ func (p *ProcessDTO) ToEntity() *Process {
 		Code:          p.Code,
 		Payload:       p.Payload.ToBytes(),
 		CurrentStatus: curentStatus,
+		CreatedBy:     p.CreatedBy,
 		Statuses:      statuses,
 	}
 }
@@ -67,8 +117,12 @@ func (p *ProcessStatusDTO) ToEntity() ProcessStatus {
 	metadata := datatypes.JSON{}
 	metadata.Scan(p.Payload)
 	return ProcessStatus{
-		Name:    p.Name,
-		Payload: metadata,
+		Name:      p.Name,
+		Payload:   metadata,
+		CreatedBy: p.CreatedBy,
+		Reason:    p.Reason,
+		SourceIP:  p.SourceIP,
+		RequestID: p.RequestID,
 	}
 }
 
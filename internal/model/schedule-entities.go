@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	SCHEDULE_STATE_PENDING   = "pending"
+	SCHEDULE_STATE_DONE      = "done"
+	SCHEDULE_STATE_CANCELLED = "cancelled"
+	SCHEDULE_STATE_FAILED    = "failed"
+)
+
+type (
+	ScheduledAssignmentList []ScheduledAssignment
+
+	// ScheduledAssignment - status assignment to be made at RunAt on behalf of the actor scheduled it,
+	// the roles of the actor are kept to check the transition at execution time
+	ScheduledAssignment struct {
+		gorm.Model
+		ProcessID      uint `gorm:"index"`
+		Code           string
+		UUID           string
+		Status         string
+		Payload        datatypes.JSON
+		Reason         string
+		RunAt          time.Time `gorm:"index"`
+		State          string    `gorm:"index;not null;default:pending"`
+		LockedUntil    time.Time
+		LastError      string
+		ExecutedAt     *time.Time
+		CreatedBy      string
+		CreatedByRoles datatypes.JSONSlice[string]
+	}
+)
+
+func (a ScheduledAssignment) ToDTO() ScheduledAssignmentDTO {
+	return ScheduledAssignmentDTO{
+		ID:         a.ID,
+		Status:     a.Status,
+		Payload:    ToDTO(a.Payload),
+		Reason:     a.Reason,
+		RunAt:      &a.RunAt,
+		State:      a.State,
+		LastError:  a.LastError,
+		ExecutedAt: a.ExecutedAt,
+		CreatedBy:  a.CreatedBy,
+		CreatedAt:  &a.CreatedAt,
+	}
+}
+
+func (al ScheduledAssignmentList) ToDTO() ScheduledAssignmentListDTO {
+	res := ScheduledAssignmentListDTO{}
+	for _, a := range al {
+		res = append(res, a.ToDTO())
+	}
+	return res
+}
+
+// Actor - returns the actor the assignment is made on behalf of
+func (a ScheduledAssignment) Actor() Actor {
+	return Actor{ID: a.CreatedBy, Roles: a.CreatedByRoles}
+}
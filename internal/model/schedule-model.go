@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+type (
+	ScheduledAssignmentListDTO []ScheduledAssignmentDTO
+
+	// @Description Status assignment scheduled to be made at the given time, only status, payload, reason and run_at are set on request.
+	ScheduledAssignmentDTO struct {
+		ID         uint       `json:"id,omitempty" example:"42"`
+		Status     string     `json:"status" example:"in_progress"`
+		Payload    Payload    `json:"payload,omitempty"`
+		Reason     string     `json:"reason,omitempty" example:"work starts on Monday"`
+		RunAt      *time.Time `json:"run_at" example:"2023-12-11T09:00:00-06:00"`
+		State      string     `json:"state,omitempty" example:"pending"`
+		LastError  string     `json:"last_error,omitempty" example:"not allowed status"`
+		ExecutedAt *time.Time `json:"executed_at,omitempty" example:"2023-12-11T09:00:02.418484002-06:00"`
+		CreatedBy  string     `json:"created_by,omitempty" example:"alex"`
+		CreatedAt  *time.Time `json:"created_at,omitempty" example:"2023-12-08T11:33:55.418484002-06:00"`
+	}
+)
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	TIMER_STATE_PENDING   = "pending"
+	TIMER_STATE_FIRED     = "fired"
+	TIMER_STATE_CANCELLED = "cancelled"
+	TIMER_STATE_FAILED    = "failed"
+)
+
+type (
+	// ProcessTimer - timeout of the status the process entered, the process of the given version
+	// is moved into OnTimeout status when the timer is due. The timer is cancelled once the process leaves the status
+	ProcessTimer struct {
+		gorm.Model
+		ProcessID uint `gorm:"index"`
+		Code      string
+		UUID      string
+		Version   uint
+		Status    string
+		OnTimeout string
+		DueAt     time.Time `gorm:"index"`
+		State     string    `gorm:"index;not null;default:pending"`
+		LastError string
+	}
+)
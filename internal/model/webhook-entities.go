@@ -0,0 +1,86 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	WEBHOOK_STATE_PENDING   = "pending"
+	WEBHOOK_STATE_DELIVERED = "delivered"
+	WEBHOOK_STATE_DEAD      = "dead"
+)
+
+type (
+	WebhookEventList []WebhookEvent
+
+	// WebhookEvent - outbox record, the event to deliver to one webhook
+	WebhookEvent struct {
+		gorm.Model
+		Webhook       string `gorm:"index"`
+		Type          string
+		ProcessID     uint `gorm:"index"`
+		Body          datatypes.JSON
+		State         string    `gorm:"index;not null;default:pending"`
+		Attempts      int       `gorm:"not null;default:0"`
+		NextAttemptAt time.Time `gorm:"index"`
+		LastError     string
+		DeliveredAt   *time.Time
+		Deliveries    WebhookDeliveryList `gorm:"foreignKey:EventID"`
+	}
+
+	WebhookDeliveryList []WebhookDelivery
+
+	// WebhookDelivery - log record of single delivery attempt
+	WebhookDelivery struct {
+		gorm.Model
+		EventID    uint `gorm:"index"`
+		Attempt    int
+		StatusCode int
+		Error      string
+		DurationMs int64
+	}
+)
+
+func (e WebhookEvent) ToDTO() WebhookEventDTO {
+	return WebhookEventDTO{
+		ID:            e.ID,
+		Webhook:       e.Webhook,
+		Type:          e.Type,
+		Body:          ToDTO(e.Body),
+		State:         e.State,
+		Attempts:      e.Attempts,
+		NextAttemptAt: e.NextAttemptAt,
+		LastError:     e.LastError,
+		DeliveredAt:   e.DeliveredAt,
+		CreatedAt:     e.CreatedAt,
+		Deliveries:    e.Deliveries.ToDTO(),
+	}
+}
+
+func (el WebhookEventList) ToDTO() WebhookEventListDTO {
+	res := WebhookEventListDTO{}
+	for _, e := range el {
+		res = append(res, e.ToDTO())
+	}
+	return res
+}
+
+func (dl WebhookDeliveryList) ToDTO() []WebhookDeliveryDTO {
+	if len(dl) == 0 {
+		return nil
+	}
+	res := []WebhookDeliveryDTO{}
+	for _, d := range dl {
+		res = append(res, WebhookDeliveryDTO{
+			Attempt:    d.Attempt,
+			StatusCode: d.StatusCode,
+			Error:      d.Error,
+			DurationMs: d.DurationMs,
+			CreatedAt:  d.CreatedAt,
+		})
+	}
+	return res
+}
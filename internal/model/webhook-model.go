@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+)
+
+type (
+	// @Description Body of the webhook request.
+	WebhookEventBody struct {
+		Type           string           `json:"type" example:"process.status_changed"`
+		Code           string           `json:"code" example:"requests"`
+		UUID           string           `json:"uuid" example:"23c968a6-5fc5-4e42-8f59-a7f9c0d4999c"`
+		Version        uint             `json:"version" example:"3"`
+		PreviousStatus string           `json:"previous_status,omitempty" example:"open"`
+		Status         ProcessStatusDTO `json:"status"`
+		OccurredAt     time.Time        `json:"occurred_at" example:"2023-12-08T11:33:55.418484002-06:00"`
+	}
+
+	WebhookEventListDTO []WebhookEventDTO
+
+	// @Description Webhook event of the outbox with its delivery attempts.
+	WebhookEventDTO struct {
+		ID            uint                 `json:"id" example:"42"`
+		Webhook       string               `json:"webhook" example:"billing"`
+		Type          string               `json:"type" example:"process.status_changed"`
+		Body          Payload              `json:"body"`
+		State         string               `json:"state" example:"dead"`
+		Attempts      int                  `json:"attempts" example:"10"`
+		NextAttemptAt time.Time            `json:"next_attempt_at" example:"2023-12-08T11:33:55.418484002-06:00"`
+		LastError     string               `json:"last_error,omitempty" example:"unexpected response status 503"`
+		DeliveredAt   *time.Time           `json:"delivered_at,omitempty" example:"2023-12-08T11:33:55.418484002-06:00"`
+		CreatedAt     time.Time            `json:"created_at" example:"2023-12-08T11:33:55.418484002-06:00"`
+		Deliveries    []WebhookDeliveryDTO `json:"deliveries,omitempty"`
+	}
+
+	// @Description Delivery attempt of the webhook event.
+	WebhookDeliveryDTO struct {
+		Attempt    int       `json:"attempt" example:"1"`
+		StatusCode int       `json:"status_code,omitempty" example:"503"`
+		Error      string    `json:"error,omitempty" example:"unexpected response status 503"`
+		DurationMs int64     `json:"duration_ms" example:"120"`
+		CreatedAt  time.Time `json:"created_at" example:"2023-12-08T11:33:55.418484002-06:00"`
+	}
+)
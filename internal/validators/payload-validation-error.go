@@ -0,0 +1,71 @@
+package validators
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+type (
+	// PayloadValidationError - payload does not match JSON Schema, Causes point to the failed fields
+	PayloadValidationError struct {
+		Causes []model.ValidationErrorDetail
+	}
+)
+
+// newPayloadValidationError - converts error of JSON Schema validation.
+// Prefix is JSON pointer of the validated value within the payload.
+func newPayloadValidationError(srcErr error, prefix string) *PayloadValidationError {
+	var ve *jsonschema.ValidationError
+	if !errors.As(srcErr, &ve) {
+		return &PayloadValidationError{
+			Causes: []model.ValidationErrorDetail{{
+				Pointer: prefix,
+				Message: srcErr.Error(),
+			}},
+		}
+	}
+
+	pve := &PayloadValidationError{}
+	pve.addCauses(ve, prefix)
+	return pve
+}
+
+// addCauses - collects the leaves of the validation errors tree, they describe the actual problems
+func (e *PayloadValidationError) addCauses(ve *jsonschema.ValidationError, prefix string) {
+	if len(ve.Causes) == 0 {
+		keyword := ve.KeywordLocation
+		if i := strings.LastIndex(keyword, "/"); i >= 0 {
+			keyword = keyword[i+1:]
+		}
+		e.Causes = append(e.Causes, model.ValidationErrorDetail{
+			Pointer: prefix + ve.InstanceLocation,
+			Keyword: keyword,
+			Message: ve.Message,
+		})
+		return
+	}
+	for _, c := range ve.Causes {
+		e.addCauses(c, prefix)
+	}
+}
+
+func (e *PayloadValidationError) Error() string {
+	causes := make([]string, 0, len(e.Causes))
+	for _, c := range e.Causes {
+		pointer := c.Pointer
+		if len(pointer) == 0 {
+			pointer = "/"
+		}
+		causes = append(causes, fmt.Sprintf("%s: %s", pointer, c.Message))
+	}
+	return fmt.Sprintf("%s: %s", ErrPayloadValidation, strings.Join(causes, "; "))
+}
+
+func (e *PayloadValidationError) Unwrap() error {
+	return ErrPayloadValidation
+}
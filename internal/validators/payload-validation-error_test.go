@@ -0,0 +1,84 @@
+package validators
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newPayloadValidationError(t *testing.T) {
+	schema := jsonschema.MustCompileString("schema.json", `{
+		"type": "object",
+		"properties": {
+			"user_name": {"type": "string"},
+			"age": {"type": "integer", "minimum": 18},
+			"address": {
+				"type": "object",
+				"properties": {"zip": {"type": "string", "pattern": "^[0-9]{5}$"}}
+			}
+		},
+		"required": ["user_name"]
+	}`)
+	tests := []struct {
+		name       string
+		payload    interface{}
+		prefix     string
+		wantCauses []model.ValidationErrorDetail
+		wantMsg    string
+	}{
+		{
+			name:    "required",
+			payload: map[string]interface{}{},
+			prefix:  "",
+			wantCauses: []model.ValidationErrorDetail{
+				{Pointer: "", Keyword: "required", Message: "missing properties: 'user_name'"},
+			},
+			wantMsg: "payload validation error: /: missing properties: 'user_name'",
+		},
+		{
+			name: "several fields",
+			payload: map[string]interface{}{
+				"user_name": "alex",
+				"age":       16,
+				"address":   map[string]interface{}{"zip": "abc"},
+			},
+			prefix: "/data",
+			wantCauses: []model.ValidationErrorDetail{
+				{Pointer: "/data/address/zip", Keyword: "pattern", Message: "does not match pattern '^[0-9]{5}$'"},
+				{Pointer: "/data/age", Keyword: "minimum", Message: "must be >= 18 but found 16"},
+			},
+		},
+		{
+			name:    "not validation error",
+			payload: nil,
+			prefix:  "/data",
+			wantCauses: []model.ValidationErrorDetail{
+				{Pointer: "/data", Message: "OMG error"},
+			},
+			wantMsg: "payload validation error: /data: OMG error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srcErr := errors.New("OMG error")
+			if tt.payload != nil {
+				srcErr = schema.Validate(tt.payload)
+			}
+
+			gotErr := newPayloadValidationError(srcErr, tt.prefix)
+
+			assert.ErrorIs(t, gotErr, ErrPayloadValidation)
+			assert.ElementsMatch(t, tt.wantCauses, gotErr.Causes)
+			if len(tt.wantMsg) > 0 {
+				assert.Equal(t, tt.wantMsg, gotErr.Error())
+			}
+			assert.False(t, strings.Contains(gotErr.Error(), "\n"))
+		})
+	}
+}
@@ -1,12 +1,14 @@
 package validators
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/expr"
 	"github.com/alex-bezverkhniy/bp-engine/internal/model"
 
 	"github.com/santhosh-tekuri/jsonschema/v5"
@@ -14,19 +16,38 @@ import (
 
 type (
 	Validator interface {
-		Validate(process model.ProcessDTO, newStatus model.ProcessStatusDTO) error
+		Validate(process model.ProcessDTO, newStatus model.ProcessStatusDTO, actor model.Actor) error
+		ValidateSubmit(process model.ProcessDTO) error
+		AllowedTransitions(process model.ProcessDTO, actor model.Actor) (model.TransitionListDTO, error)
+		InitialStatus(code string) (string, error)
+		StatusTimeout(code string, status string) (time.Duration, string)
 		CompileJsonSchema() error
 	}
 
 	BasicValidator struct {
 		conf        config.ProcessConfigList
 		jsonSchemas map[string]*jsonschema.Schema
+		// compiled `when` expressions of the transitions
+		guards map[string]*expr.Expression
+	}
+
+	// GuardError - `when` expression of the transition is not satisfied, Err is set if it cannot be evaluated
+	GuardError struct {
+		Status string
+		Guard  string
+		Err    error
 	}
 )
 
+var ErrUnknownProcess = errors.New("unknown process")
 var ErrUnknownStatus = errors.New("unknown status")
 var ErrNotAllowedStatus = errors.New("not allowed status")
-var ErrPayloadValidation = errors.New("payload validation error: ")
+var ErrPayloadValidation = errors.New("payload validation error")
+var ErrFinalStatus = errors.New("process is in final status")
+var ErrInitialStatusNotDefined = errors.New("initial status is not defined")
+var ErrGuardFailed = errors.New("transition guard is not satisfied")
+var ErrForbiddenTransition = errors.New("transition is not allowed for the actor")
+var ErrReasonRequired = errors.New("reason is required")
 
 func NewBasicValidator(conf []config.ProcessConfig) Validator {
 	return &BasicValidator{
@@ -34,27 +55,35 @@ func NewBasicValidator(conf []config.ProcessConfig) Validator {
 	}
 }
 
-func (bv *BasicValidator) Validate(process model.ProcessDTO, newStatus model.ProcessStatusDTO) error {
+func (bv *BasicValidator) Validate(process model.ProcessDTO, newStatus model.ProcessStatusDTO, actor model.Actor) error {
 	// Check if status defined
-	_, err := bv.conf.GetStatusConfig(process.Code, newStatus.Name)
+	newStatusCfg, err := bv.conf.GetStatusConfig(process.Code, newStatus.Name)
 	if err != nil {
 		return ErrUnknownStatus
 	}
 
 	// Check current status config
-	currentStatusCfg, err := bv.conf.GetStatusConfig(process.Code, process.CurrentStatus.Name)
+	currentStatusName := ""
+	if process.CurrentStatus != nil {
+		currentStatusName = process.CurrentStatus.Name
+	}
+	currentStatusCfg, err := bv.conf.GetStatusConfig(process.Code, currentStatusName)
 	if err != nil {
 		return ErrUnknownStatus
 	}
-	found := false
-	for _, s := range currentStatusCfg.Next {
-		if s == newStatus.Name {
-			found = true
-		}
+	if currentStatusCfg.Final {
+		return ErrFinalStatus
 	}
+	next, found := currentStatusCfg.Next.Get(newStatus.Name)
 	if !found {
 		return ErrNotAllowedStatus
 	}
+	if !bv.isPermitted(*next, *newStatusCfg, actor) {
+		return ErrForbiddenTransition
+	}
+	if requiresReason(*next, *newStatusCfg) && len(strings.TrimSpace(newStatus.Reason)) == 0 {
+		return ErrReasonRequired
+	}
 
 	schemaKey := bv.schemaKey(process.Code, newStatus.Name)
 	schema := bv.jsonSchemas[schemaKey]
@@ -66,20 +95,176 @@ func (bv *BasicValidator) Validate(process model.ProcessDTO, newStatus model.Pro
 		}
 		err = schema.Validate(m)
 		if err != nil {
-			return errors.Join(ErrPayloadValidation, bv.formatErrMsg(err))
+			return newPayloadValidationError(err, "/data")
+		}
+	}
+
+	if len(next.When) > 0 {
+		return bv.checkGuard(process, newStatus, currentStatusName, next.When)
+	}
+
+	return nil
+}
+
+// checkGuard - evaluates `when` expression of the transition against the process payload,
+// the payload of the new status and the current status name. The expression is compiled by CompileJsonSchema,
+// it is parsed on every call if the validator is not compiled
+func (bv *BasicValidator) checkGuard(process model.ProcessDTO, newStatus model.ProcessStatusDTO, currentStatusName, guard string) error {
+	e, ok := bv.guards[bv.guardKey(process.Code, currentStatusName, newStatus.Name)]
+	if !ok {
+		var err error
+		if e, err = expr.Parse(guard); err != nil {
+			return &GuardError{Status: newStatus.Name, Guard: guard, Err: err}
+		}
+	}
+
+	vars := map[string]interface{}{
+		"payload":        map[string]interface{}(process.Payload),
+		"input":          map[string]interface{}(newStatus.Payload),
+		"current_status": nil,
+	}
+	if process.CurrentStatus != nil {
+		vars["current_status"] = process.CurrentStatus.Name
+	}
+
+	ok, err := e.EvalBool(vars)
+	if err != nil || !ok {
+		return &GuardError{Status: newStatus.Name, Guard: guard, Err: err}
+	}
+	return nil
+}
+
+// ValidateSubmit - validates new process against the process config and its JSON Schema
+func (bv *BasicValidator) ValidateSubmit(process model.ProcessDTO) error {
+	if _, err := bv.conf.GetProcessConfig(process.Code); err != nil {
+		return ErrUnknownProcess
+	}
+
+	schema := bv.jsonSchemas[bv.processSchemaKey(process.Code)]
+	if schema != nil {
+		var m interface{}
+		if process.Payload != nil {
+			m = map[string]interface{}(process.Payload)
+		}
+		err := schema.Validate(m)
+		if err != nil {
+			return newPayloadValidationError(err, "")
 		}
 	}
 
 	return nil
 }
 
-// CompileJsonSchema - Compiles JSON Schemas and adds it into map
+// AllowedTransitions - returns statuses the actor can move the process into from its current status
+func (bv *BasicValidator) AllowedTransitions(process model.ProcessDTO, actor model.Actor) (model.TransitionListDTO, error) {
+	currentStatusName := ""
+	if process.CurrentStatus != nil {
+		currentStatusName = process.CurrentStatus.Name
+	}
+	currentStatusCfg, err := bv.conf.GetStatusConfig(process.Code, currentStatusName)
+	if err != nil {
+		return nil, ErrUnknownStatus
+	}
+
+	transitions := model.TransitionListDTO{}
+	if currentStatusCfg.Final {
+		return transitions, nil
+	}
+	for _, next := range currentStatusCfg.Next {
+		sc, err := bv.conf.GetStatusConfig(process.Code, next.Name)
+		if err != nil {
+			return nil, ErrUnknownStatus
+		}
+		if !bv.isPermitted(next, *sc, actor) {
+			continue
+		}
+		var schema json.RawMessage
+		if len(sc.Schema) > 0 {
+			schema = json.RawMessage(sc.Schema)
+		}
+		transitions = append(transitions, model.TransitionDTO{
+			Name:          sc.Name,
+			Final:         sc.Final,
+			When:          next.When,
+			RequireReason: requiresReason(next, *sc),
+			Schema:        schema,
+		})
+	}
+
+	return transitions, nil
+}
+
+// isPermitted - checks roles of the transition, roles of the next status are used if the transition has none.
+// The system actor is permitted any transition
+func (bv *BasicValidator) isPermitted(next config.NextStatus, nextStatusCfg config.StatusConfig, actor model.Actor) bool {
+	if actor.System {
+		return true
+	}
+	roles := next.Roles
+	if len(roles) == 0 {
+		roles = nextStatusCfg.Roles
+	}
+	return len(roles) == 0 || actor.HasAnyRole(roles)
+}
+
+// requiresReason - checks if the transition or every transition into the next status requires the reason
+func requiresReason(next config.NextStatus, nextStatusCfg config.StatusConfig) bool {
+	return next.RequireReason || nextStatusCfg.RequireReason
+}
+
+// InitialStatus - returns name of the status new processes are placed into
+func (bv *BasicValidator) InitialStatus(code string) (string, error) {
+	sc, err := bv.conf.GetInitialStatusConfig(code)
+	if err != nil {
+		return "", errors.Join(ErrInitialStatusNotDefined, err)
+	}
+	return sc.Name, nil
+}
+
+// StatusTimeout - returns the timeout of the status and the status to move the process into on timeout,
+// 0 if the status has no timeout
+func (bv *BasicValidator) StatusTimeout(code string, status string) (time.Duration, string) {
+	sc, err := bv.conf.GetStatusConfig(code, status)
+	if err != nil || len(sc.OnTimeout) == 0 {
+		return 0, ""
+	}
+	timeout, err := sc.TimeoutDuration()
+	if err != nil || timeout <= 0 {
+		return 0, ""
+	}
+	return timeout, sc.OnTimeout
+}
+
+// CompileJsonSchema - Compiles JSON Schemas and `when` expressions of the transitions and adds them into maps
 func (bv *BasicValidator) CompileJsonSchema() error {
 	compiler := jsonschema.NewCompiler()
 
 	jsonSchemas := map[string]*jsonschema.Schema{}
+	guards := map[string]*expr.Expression{}
 	for _, pc := range bv.conf {
+		if len(pc.Schema) > 0 {
+			schemaKey := bv.processSchemaKey(pc.Name)
+			err := compiler.AddResource(schemaKey, strings.NewReader(pc.Schema))
+			if err != nil {
+				return err
+			}
+			schema, err := compiler.Compile(schemaKey)
+			if err != nil {
+				return err
+			}
+			jsonSchemas[schemaKey] = schema
+		}
 		for _, s := range pc.Statuses {
+			for _, next := range s.Next {
+				if len(next.When) == 0 {
+					continue
+				}
+				guard, err := expr.Parse(next.When)
+				if err != nil {
+					return fmt.Errorf("transition %s -> %s of %s: %w", s.Name, next.Name, pc.Name, err)
+				}
+				guards[bv.guardKey(pc.Name, s.Name, next.Name)] = guard
+			}
 			if len(s.Schema) > 0 {
 				schemaKey := bv.schemaKey(pc.Name, s.Name)
 				err := compiler.AddResource(schemaKey, strings.NewReader(s.Schema))
@@ -95,6 +280,7 @@ func (bv *BasicValidator) CompileJsonSchema() error {
 		}
 	}
 	bv.jsonSchemas = jsonSchemas
+	bv.guards = guards
 	return nil
 }
 
@@ -102,9 +288,26 @@ func (bv *BasicValidator) schemaKey(processName, statusName string) string {
 	return fmt.Sprintf("%s-%s", processName, statusName)
 }
 
-func (bv *BasicValidator) formatErrMsg(srcErr error) error {
-	var re = regexp.MustCompile(`^jsonschema:(.*)(file:\/\/(.*):)(.*)$`)
+func (bv *BasicValidator) guardKey(processName, statusName, nextStatusName string) string {
+	return fmt.Sprintf("%s-%s->%s", processName, statusName, nextStatusName)
+}
+
+func (bv *BasicValidator) processSchemaKey(processName string) string {
+	return fmt.Sprintf("%s.json", processName)
+}
+
+func (e *GuardError) Error() string {
+	msg := fmt.Sprintf("%s: status %q requires %s", ErrGuardFailed, e.Status, e.Guard)
+	if e.Err != nil {
+		msg += fmt.Sprintf(" (%s)", e.Err)
+	}
+	return msg
+}
+
+func (e *GuardError) Is(target error) bool {
+	return target == ErrGuardFailed
+}
 
-	msg := re.ReplaceAllString(srcErr.Error(), `$1 JSON Schema:$4`)
-	return errors.New(strings.Trim(msg, " "))
+func (e *GuardError) Unwrap() error {
+	return e.Err
 }
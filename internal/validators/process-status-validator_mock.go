@@ -1,6 +1,8 @@
 package validators
 
 import (
+	"time"
+
 	"github.com/alex-bezverkhniy/bp-engine/internal/model"
 
 	"github.com/stretchr/testify/mock"
@@ -10,11 +12,35 @@ type ValidatorMocked struct {
 	mock.Mock
 }
 
-func (vm *ValidatorMocked) Validate(process model.ProcessDTO, newStatus model.ProcessStatusDTO) error {
-	args := vm.Called(process, newStatus)
+func (vm *ValidatorMocked) Validate(process model.ProcessDTO, newStatus model.ProcessStatusDTO, actor model.Actor) error {
+	args := vm.Called(process, newStatus, actor)
 	return args.Error(0)
 }
 
+func (vm *ValidatorMocked) ValidateSubmit(process model.ProcessDTO) error {
+	args := vm.Called(process)
+	return args.Error(0)
+}
+
+func (vm *ValidatorMocked) AllowedTransitions(process model.ProcessDTO, actor model.Actor) (model.TransitionListDTO, error) {
+	args := vm.Called(process, actor)
+	res := args.Get(0)
+	if res != nil {
+		return res.(model.TransitionListDTO), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (vm *ValidatorMocked) InitialStatus(code string) (string, error) {
+	args := vm.Called(code)
+	return args.String(0), args.Error(1)
+}
+
+func (vm *ValidatorMocked) StatusTimeout(code string, status string) (time.Duration, string) {
+	args := vm.Called(code, status)
+	return args.Get(0).(time.Duration), args.String(1)
+}
+
 func (vm *ValidatorMocked) CompileJsonSchema() error {
 	args := vm.Called()
 	return args.Error(0)
@@ -1,9 +1,12 @@
 package validators
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/expr"
 	"github.com/alex-bezverkhniy/bp-engine/internal/model"
 
 	"github.com/stretchr/testify/assert"
@@ -14,18 +17,22 @@ func Test_Validate(t *testing.T) {
 		Name: "requests",
 		Statuses: []config.StatusConfig{
 			{
-				Name: "open",
-				Next: []string{"in_progress", "rejected"},
+				Name:    "open",
+				Next:    config.NextList{{Name: "in_progress"}, {Name: "rejected"}},
+				Initial: true,
 			},
 			{
 				Name: "in_progress",
-				Next: []string{"open", "rejected", "done"},
+				Next: config.NextList{{Name: "open"}, {Name: "rejected"}, {Name: "done"}},
 			},
 			{
-				Name: "rejected",
+				Name:  "rejected",
+				Final: true,
 			},
 			{
-				Name: "done",
+				Name:  "done",
+				Next:  config.NextList{{Name: "open"}},
+				Final: true,
 			},
 		},
 	}}
@@ -120,13 +127,38 @@ func Test_Validate(t *testing.T) {
 			},
 			wantErr: ErrNotAllowedStatus,
 		},
+		{
+			name: "invalid - final status",
+			conf: defaultProcessConfig,
+			process: model.ProcessDTO{
+				Code: "requests",
+				CurrentStatus: &model.ProcessStatusDTO{
+					Name: "done",
+				},
+			},
+			status: model.ProcessStatusDTO{
+				Name: "open",
+			},
+			wantErr: ErrFinalStatus,
+		},
+		{
+			name: "invalid - no current status",
+			conf: defaultProcessConfig,
+			process: model.ProcessDTO{
+				Code: "requests",
+			},
+			status: model.ProcessStatusDTO{
+				Name: "open",
+			},
+			wantErr: ErrUnknownStatus,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			validator := NewBasicValidator(tt.conf)
 
-			gotErr := validator.Validate(tt.process, tt.status)
+			gotErr := validator.Validate(tt.process, tt.status, model.Actor{})
 
 			if tt.wantErr != nil {
 				assert.NotNil(t, gotErr)
@@ -137,3 +169,568 @@ func Test_Validate(t *testing.T) {
 		})
 	}
 }
+
+func Test_ValidateGuard(t *testing.T) {
+	conf := config.ProcessConfigList{{
+		Name: "requests",
+		Statuses: []config.StatusConfig{
+			{
+				Name:    "open",
+				Initial: true,
+				Next: config.NextList{
+					{Name: "approved", When: "payload.amount < 10000"},
+					{Name: "rejected", When: `input.comment != null && current_status == "open"`},
+				},
+			},
+			{Name: "approved", Final: true},
+			{Name: "rejected", Final: true},
+		},
+	}}
+	tests := []struct {
+		name    string
+		process model.ProcessDTO
+		status  model.ProcessStatusDTO
+		wantErr string
+	}{
+		{
+			name: "valid - process payload",
+			process: model.ProcessDTO{
+				Code:          "requests",
+				Payload:       model.Payload{"amount": 9999},
+				CurrentStatus: &model.ProcessStatusDTO{Name: "open"},
+			},
+			status: model.ProcessStatusDTO{Name: "approved"},
+		},
+		{
+			name: "valid - status payload",
+			process: model.ProcessDTO{
+				Code:          "requests",
+				CurrentStatus: &model.ProcessStatusDTO{Name: "open"},
+			},
+			status: model.ProcessStatusDTO{
+				Name:    "rejected",
+				Payload: model.Payload{"comment": "too expensive"},
+			},
+		},
+		{
+			name: "invalid - guard is false",
+			process: model.ProcessDTO{
+				Code:          "requests",
+				Payload:       model.Payload{"amount": 10000},
+				CurrentStatus: &model.ProcessStatusDTO{Name: "open"},
+			},
+			status:  model.ProcessStatusDTO{Name: "approved"},
+			wantErr: `transition guard is not satisfied: status "approved" requires payload.amount < 10000`,
+		},
+		{
+			name: "invalid - missing field",
+			process: model.ProcessDTO{
+				Code:          "requests",
+				CurrentStatus: &model.ProcessStatusDTO{Name: "open"},
+			},
+			status:  model.ProcessStatusDTO{Name: "rejected"},
+			wantErr: `transition guard is not satisfied: status "rejected" requires input.comment != null && current_status == "open"`,
+		},
+		{
+			name: "invalid - cannot be evaluated",
+			process: model.ProcessDTO{
+				Code:          "requests",
+				Payload:       model.Payload{"amount": "a lot"},
+				CurrentStatus: &model.ProcessStatusDTO{Name: "open"},
+			},
+			status:  model.ProcessStatusDTO{Name: "approved"},
+			wantErr: `transition guard is not satisfied: status "approved" requires payload.amount < 10000 (evaluation error: operator < is not supported for string and number)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewBasicValidator(conf)
+			assert.Nil(t, validator.CompileJsonSchema())
+
+			gotErr := validator.Validate(tt.process, tt.status, model.Actor{})
+
+			if len(tt.wantErr) > 0 {
+				assert.ErrorIs(t, gotErr, ErrGuardFailed)
+				assert.Equal(t, tt.wantErr, gotErr.Error())
+			} else {
+				assert.Nil(t, gotErr)
+			}
+		})
+	}
+}
+
+func Test_ValidateRoles(t *testing.T) {
+	conf := config.ProcessConfigList{{
+		Name: "requests",
+		Statuses: []config.StatusConfig{
+			{
+				Name:    "open",
+				Initial: true,
+				Next: config.NextList{
+					{Name: "approved", Roles: []string{"manager", "director"}},
+					{Name: "rejected"},
+					{Name: "in_progress"},
+				},
+			},
+			{Name: "in_progress", Next: config.NextList{{Name: "approved", Roles: []string{"director"}}}},
+			{Name: "approved", Final: true, Roles: []string{"manager"}},
+			{Name: "rejected", Final: true, Roles: []string{"manager"}},
+		},
+	}}
+	tests := []struct {
+		name    string
+		current string
+		status  string
+		actor   model.Actor
+		wantErr error
+	}{
+		{
+			name:    "valid - role of transition",
+			current: "open",
+			status:  "approved",
+			actor:   model.Actor{ID: "alex", Roles: []string{"clerk", "director"}},
+		},
+		{
+			name:    "valid - role of status",
+			current: "open",
+			status:  "rejected",
+			actor:   model.Actor{ID: "alex", Roles: []string{"manager"}},
+		},
+		{
+			name:    "valid - no roles required",
+			current: "open",
+			status:  "in_progress",
+		},
+		{
+			name:    "invalid - transition roles override status roles",
+			current: "in_progress",
+			status:  "approved",
+			actor:   model.Actor{ID: "alex", Roles: []string{"manager"}},
+			wantErr: ErrForbiddenTransition,
+		},
+		{
+			name:    "invalid - anonymous",
+			current: "open",
+			status:  "rejected",
+			wantErr: ErrForbiddenTransition,
+		},
+		{
+			name:    "valid - system actor",
+			current: "in_progress",
+			status:  "approved",
+			actor:   model.SystemActor,
+		},
+		{
+			name:    "invalid - system actor ID",
+			current: "open",
+			status:  "rejected",
+			actor:   model.Actor{ID: model.SYSTEM_ACTOR_ID},
+			wantErr: ErrForbiddenTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewBasicValidator(conf)
+
+			gotErr := validator.Validate(model.ProcessDTO{
+				Code:          "requests",
+				CurrentStatus: &model.ProcessStatusDTO{Name: tt.current},
+			}, model.ProcessStatusDTO{Name: tt.status}, tt.actor)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, gotErr, tt.wantErr)
+			} else {
+				assert.Nil(t, gotErr)
+			}
+		})
+	}
+}
+
+func Test_ValidateReason(t *testing.T) {
+	conf := config.ProcessConfigList{{
+		Name: "requests",
+		Statuses: []config.StatusConfig{
+			{Name: "open", Initial: true, Next: config.NextList{{Name: "rejected"}, {Name: "done"}, {Name: "cancelled", RequireReason: true}}},
+			{Name: "in_progress", Next: config.NextList{{Name: "cancelled"}}},
+			{Name: "rejected", Final: true, RequireReason: true},
+			{Name: "done", Final: true},
+			{Name: "cancelled", Final: true},
+		},
+	}}
+	tests := []struct {
+		name          string
+		currentStatus string
+		status        model.ProcessStatusDTO
+		wantErr       error
+	}{
+		{
+			name:   "valid - reason is given",
+			status: model.ProcessStatusDTO{Name: "rejected", Reason: "customer asked to cancel"},
+		},
+		{
+			name:   "valid - reason is not required",
+			status: model.ProcessStatusDTO{Name: "done"},
+		},
+		{
+			name:    "invalid - no reason",
+			status:  model.ProcessStatusDTO{Name: "rejected"},
+			wantErr: ErrReasonRequired,
+		},
+		{
+			name:    "invalid - blank reason",
+			status:  model.ProcessStatusDTO{Name: "rejected", Reason: "  "},
+			wantErr: ErrReasonRequired,
+		},
+		{
+			name:    "invalid - no reason for the transition",
+			status:  model.ProcessStatusDTO{Name: "cancelled"},
+			wantErr: ErrReasonRequired,
+		},
+		{
+			name:          "valid - reason is not required for other transition into the status",
+			currentStatus: "in_progress",
+			status:        model.ProcessStatusDTO{Name: "cancelled"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewBasicValidator(conf)
+
+			currentStatus := tt.currentStatus
+			if len(currentStatus) == 0 {
+				currentStatus = "open"
+			}
+			gotErr := validator.Validate(model.ProcessDTO{
+				Code:          "requests",
+				CurrentStatus: &model.ProcessStatusDTO{Name: currentStatus},
+			}, tt.status, model.Actor{})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, gotErr, tt.wantErr)
+			} else {
+				assert.Nil(t, gotErr)
+			}
+		})
+	}
+}
+
+func Test_StatusTimeout(t *testing.T) {
+	conf := config.ProcessConfigList{{
+		Name: "requests",
+		Statuses: []config.StatusConfig{
+			{Name: "open", Initial: true, Next: config.NextList{{Name: "done"}, {Name: "expired"}}, Timeout: "48h", OnTimeout: "expired"},
+			{Name: "expired", Final: true},
+			{Name: "done", Final: true},
+		},
+	}}
+	tests := []struct {
+		name          string
+		code          string
+		status        string
+		wantTimeout   time.Duration
+		wantOnTimeout string
+	}{
+		{
+			name:          "timeout",
+			code:          "requests",
+			status:        "open",
+			wantTimeout:   48 * time.Hour,
+			wantOnTimeout: "expired",
+		},
+		{
+			name:   "no timeout",
+			code:   "requests",
+			status: "done",
+		},
+		{
+			name:   "unknown status",
+			code:   "tickets",
+			status: "open",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewBasicValidator(conf)
+
+			gotTimeout, gotOnTimeout := validator.StatusTimeout(tt.code, tt.status)
+
+			assert.Equal(t, tt.wantTimeout, gotTimeout)
+			assert.Equal(t, tt.wantOnTimeout, gotOnTimeout)
+		})
+	}
+}
+
+func Test_InitialStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		conf       config.ProcessConfigList
+		code       string
+		wantStatus string
+		wantErr    error
+	}{
+		{
+			name: "success",
+			conf: config.ProcessConfigList{{
+				Name: "requests",
+				Statuses: []config.StatusConfig{
+					{Name: "open", Initial: true, Next: config.NextList{{Name: "done"}}},
+					{Name: "done", Final: true},
+				},
+			}},
+			code:       "requests",
+			wantStatus: "open",
+		},
+		{
+			name: "failed - no initial status",
+			conf: config.ProcessConfigList{{
+				Name: "requests",
+				Statuses: []config.StatusConfig{
+					{Name: "open", Next: config.NextList{{Name: "done"}}},
+					{Name: "done", Final: true},
+				},
+			}},
+			code:    "requests",
+			wantErr: ErrInitialStatusNotDefined,
+		},
+		{
+			name: "failed - more than one initial status",
+			conf: config.ProcessConfigList{{
+				Name: "requests",
+				Statuses: []config.StatusConfig{
+					{Name: "open", Initial: true, Next: config.NextList{{Name: "done"}}},
+					{Name: "new", Initial: true, Next: config.NextList{{Name: "done"}}},
+					{Name: "done", Final: true},
+				},
+			}},
+			code:    "requests",
+			wantErr: config.ErrMultipleInitialStatusConfigs,
+		},
+		{
+			name: "failed - unknown process",
+			conf: config.ProcessConfigList{{
+				Name: "requests",
+				Statuses: []config.StatusConfig{
+					{Name: "open", Initial: true},
+				},
+			}},
+			code:    "tickets",
+			wantErr: ErrInitialStatusNotDefined,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewBasicValidator(tt.conf)
+
+			gotStatus, gotErr := validator.InitialStatus(tt.code)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, gotErr, tt.wantErr)
+			} else {
+				assert.Nil(t, gotErr)
+				assert.Equal(t, tt.wantStatus, gotStatus)
+			}
+		})
+	}
+}
+
+func Test_ValidateSubmit(t *testing.T) {
+	conf := config.ProcessConfigList{{
+		Name:   "requests",
+		Schema: `{"type": "object", "properties": {"customer_id": {"type": "integer"}}, "required": ["customer_id"]}`,
+		Statuses: []config.StatusConfig{
+			{Name: "open", Initial: true, Next: config.NextList{{Name: "done"}}},
+			{Name: "done", Final: true},
+		},
+	}, {
+		Name: "tickets",
+		Statuses: []config.StatusConfig{
+			{Name: "open", Initial: true},
+		},
+	}}
+	tests := []struct {
+		name    string
+		process model.ProcessDTO
+		wantErr error
+	}{
+		{
+			name: "valid",
+			process: model.ProcessDTO{
+				Code:    "requests",
+				Payload: model.Payload{"customer_id": 42},
+			},
+		},
+		{
+			name: "valid - no schema",
+			process: model.ProcessDTO{
+				Code: "tickets",
+			},
+		},
+		{
+			name: "invalid - unknown process",
+			process: model.ProcessDTO{
+				Code: "orders",
+			},
+			wantErr: ErrUnknownProcess,
+		},
+		{
+			name: "invalid - payload",
+			process: model.ProcessDTO{
+				Code:    "requests",
+				Payload: model.Payload{"customer_id": "abc"},
+			},
+			wantErr: ErrPayloadValidation,
+		},
+		{
+			name: "invalid - no payload",
+			process: model.ProcessDTO{
+				Code: "requests",
+			},
+			wantErr: ErrPayloadValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewBasicValidator(conf)
+			assert.Nil(t, validator.CompileJsonSchema())
+
+			gotErr := validator.ValidateSubmit(tt.process)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, gotErr, tt.wantErr)
+			} else {
+				assert.Nil(t, gotErr)
+			}
+		})
+	}
+}
+
+func Test_AllowedTransitions(t *testing.T) {
+	conf := config.ProcessConfigList{{
+		Name: "requests",
+		Statuses: []config.StatusConfig{
+			{Name: "open", Initial: true, Next: config.NextList{{Name: "in_progress"}, {Name: "rejected", When: "input.comment != null"}}},
+			{Name: "in_progress", Next: config.NextList{{Name: "done"}}, Schema: `{"type": "object"}`, Roles: []string{"manager"}},
+			{Name: "rejected", Final: true},
+			{Name: "done", Final: true, Next: config.NextList{{Name: "open"}}},
+		},
+	}}
+	tests := []struct {
+		name    string
+		process model.ProcessDTO
+		actor   model.Actor
+		want    model.TransitionListDTO
+		wantErr error
+	}{
+		{
+			name: "success",
+			process: model.ProcessDTO{
+				Code:          "requests",
+				CurrentStatus: &model.ProcessStatusDTO{Name: "open"},
+			},
+			actor: model.Actor{ID: "alex", Roles: []string{"manager"}},
+			want: model.TransitionListDTO{
+				{Name: "in_progress", Schema: json.RawMessage(`{"type": "object"}`)},
+				{Name: "rejected", Final: true, When: "input.comment != null"},
+			},
+		},
+		{
+			name: "success - filtered by roles",
+			process: model.ProcessDTO{
+				Code:          "requests",
+				CurrentStatus: &model.ProcessStatusDTO{Name: "open"},
+			},
+			want: model.TransitionListDTO{
+				{Name: "rejected", Final: true, When: "input.comment != null"},
+			},
+		},
+		{
+			name: "success - final status",
+			process: model.ProcessDTO{
+				Code:          "requests",
+				CurrentStatus: &model.ProcessStatusDTO{Name: "done"},
+			},
+			want: model.TransitionListDTO{},
+		},
+		{
+			name: "failed - unknown status",
+			process: model.ProcessDTO{
+				Code:          "requests",
+				CurrentStatus: &model.ProcessStatusDTO{Name: "closed"},
+			},
+			wantErr: ErrUnknownStatus,
+		},
+		{
+			name: "failed - unknown process",
+			process: model.ProcessDTO{
+				Code:          "tickets",
+				CurrentStatus: &model.ProcessStatusDTO{Name: "open"},
+			},
+			wantErr: ErrUnknownStatus,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewBasicValidator(conf)
+
+			got, gotErr := validator.AllowedTransitions(tt.process, tt.actor)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, gotErr, tt.wantErr)
+			} else {
+				assert.Nil(t, gotErr)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_CompileGuards(t *testing.T) {
+	tests := []struct {
+		name       string
+		next       config.NextList
+		wantGuards []string
+		wantErr    bool
+	}{
+		{
+			name:       "compiled once",
+			next:       config.NextList{{Name: "approved", When: "payload.amount < 10000"}, {Name: "rejected"}},
+			wantGuards: []string{"requests-open->approved"},
+		},
+		{
+			name:    "invalid expression",
+			next:    config.NextList{{Name: "approved", When: "payload.amount <"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := &BasicValidator{conf: config.ProcessConfigList{{
+				Name: "requests",
+				Statuses: []config.StatusConfig{
+					{Name: "open", Initial: true, Next: tt.next},
+					{Name: "approved", Final: true},
+					{Name: "rejected", Final: true},
+				},
+			}}}
+
+			err := validator.CompileJsonSchema()
+			if tt.wantErr {
+				assert.ErrorIs(t, err, expr.ErrSyntax)
+				return
+			}
+			assert.Nil(t, err)
+			var gotGuards []string
+			for key := range validator.guards {
+				gotGuards = append(gotGuards, key)
+			}
+			assert.Equal(t, tt.wantGuards, gotGuards)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package bpengine
+
+import (
+	"context"
+
+	"github.com/alex-bezverkhniy/bp-engine/internal/api"
+	"github.com/alex-bezverkhniy/bp-engine/internal/config"
+	"github.com/alex-bezverkhniy/bp-engine/internal/model"
+	"github.com/alex-bezverkhniy/bp-engine/internal/validators"
+
+	"gorm.io/gorm"
+)
+
+// Public API of the engine. The types are aliases of the internal ones, so the values of the embedding application
+// and of the engine are interchangeable and the built-in implementations satisfy the interfaces.
+
+// Extension points
+type (
+	// Validator - validates the processes and their status changes by the process definitions, see SetValidator
+	Validator = validators.Validator
+
+	// ProcessRepository - storage of the processes, see WithRepository
+	ProcessRepository = api.ProcessRepository
+
+	// Authenticator - resolves the actor of the API request, see SetAuthenticator
+	Authenticator = api.Authenticator
+
+	// AuthenticatorFunc - adapter to use ordinary function as Authenticator
+	AuthenticatorFunc = api.AuthenticatorFunc
+
+	// EventHandler - hook of the process lifecycle event, see OnSubmitted and OnStatusChanged
+	EventHandler = api.EventHandler
+
+	// RequestInfo - origin of the API request, recorded into the status history
+	RequestInfo = api.RequestInfo
+)
+
+// Process definitions and configuration
+type (
+	Config            = config.Config
+	AuthConfig        = config.AuthConfig
+	ApiKeyConfig      = config.ApiKeyConfig
+	JwtConfig         = config.JwtConfig
+	WebhookConfig     = config.WebhookConfig
+	WebhookConfigList = config.WebhookConfigList
+	ProcessConfig     = config.ProcessConfig
+	ProcessConfigList = config.ProcessConfigList
+	StatusConfig      = config.StatusConfig
+	NextStatus        = config.NextStatus
+	NextList          = config.NextList
+
+	// ConfigError - problem found in the process definitions, Path points to the broken element
+	ConfigError = config.ConfigError
+)
+
+// DTOs of the API and the hooks
+type (
+	Actor                = model.Actor
+	Payload              = model.Payload
+	ProcessDTO           = model.ProcessDTO
+	ProcessListDTO       = model.ProcessListDTO
+	ProcessStatusDTO     = model.ProcessStatusDTO
+	ProcessStatusListDTO = model.ProcessStatusListDTO
+	TransitionDTO        = model.TransitionDTO
+	TransitionListDTO    = model.TransitionListDTO
+	ProcessChangeDTO     = model.ProcessChangeDTO
+	ProcessEvent         = model.ProcessEvent
+	ProcessErrorResponse = model.ProcessErrorResponse
+
+	// PayloadValidationError - payload does not match the JSON Schema of the process or the status
+	PayloadValidationError = validators.PayloadValidationError
+	// GuardError - `when` expression of the transition is not satisfied
+	GuardError = validators.GuardError
+)
+
+// Entities stored by ProcessRepository
+type (
+	Process           = model.Process
+	ProcessList       = model.ProcessList
+	ProcessStatus     = model.ProcessStatus
+	ProcessStatusList = model.ProcessStatusList
+	ProcessChange     = model.ProcessChange
+	ProcessChangeList = model.ProcessChangeList
+	ProcessTimer      = model.ProcessTimer
+	WebhookEvent      = model.WebhookEvent
+	WebhookDelivery   = model.WebhookDelivery
+
+	ProcessQuery   = api.ProcessQuery
+	PayloadFilter  = api.PayloadFilter
+	FilterOperator = api.FilterOperator
+	SortField      = api.SortField
+	PageInfo       = api.PageInfo
+	ChangeQuery    = api.ChangeQuery
+)
+
+const (
+	EVENT_PROCESS_SUBMITTED            = model.EVENT_PROCESS_SUBMITTED
+	EVENT_PROCESS_BEFORE_STATUS_CHANGE = model.EVENT_PROCESS_BEFORE_STATUS_CHANGE
+	EVENT_PROCESS_STATUS_CHANGED       = model.EVENT_PROCESS_STATUS_CHANGED
+
+	OperatorEq       = api.OperatorEq
+	OperatorNe       = api.OperatorNe
+	OperatorGt       = api.OperatorGt
+	OperatorGte      = api.OperatorGte
+	OperatorLt       = api.OperatorLt
+	OperatorLte      = api.OperatorLte
+	OperatorContains = api.OperatorContains
+
+	SortByCreatedAt = api.SortByCreatedAt
+	SortByChangedAt = api.SortByChangedAt
+)
+
+// SystemActor - actor of the changes made by the engine, e.g. on timeout of the status
+var SystemActor = model.SystemActor
+
+// NewValidator - creates built-in validator of the process definitions, custom one may wrap it
+func NewValidator(cfg ProcessConfigList) (Validator, error) {
+	validator := validators.NewBasicValidator(cfg)
+	if err := validator.CompileJsonSchema(); err != nil {
+		return nil, err
+	}
+	return validator, nil
+}
+
+// NewProcessRepository - creates built-in repository storing the processes in the DB, custom one may wrap it
+func NewProcessRepository(db *gorm.DB) ProcessRepository {
+	return api.NewProcessRepository(db)
+}
+
+// NewAuthenticator - creates built-in authenticator defined by the config
+func NewAuthenticator(cfg AuthConfig) (Authenticator, error) {
+	return api.NewAuthenticator(cfg)
+}
+
+// RequestInfoFromContext - origin of the API request passed to ProcessRepository, empty one if the context has none
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	return api.RequestInfoFromContext(ctx)
+}
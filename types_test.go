@@ -0,0 +1,113 @@
+package bpengine_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	bpengine "github.com/alex-bezverkhniy/bp-engine"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// the extensions implemented with the public API only, the same way an external module does
+
+type auditValidator struct {
+	bpengine.Validator
+	submitted []string
+}
+
+func (v *auditValidator) ValidateSubmit(process bpengine.ProcessDTO) error {
+	if _, ok := process.Payload["secret"]; ok {
+		return fmt.Errorf("%w: secret is not allowed", bpengine.ErrPayloadValidation)
+	}
+	v.submitted = append(v.submitted, process.Code)
+	return v.Validator.ValidateSubmit(process)
+}
+
+type auditRepository struct {
+	bpengine.ProcessRepository
+	// shared with the repository of the transaction
+	created *[]string
+}
+
+func (r *auditRepository) Create(ctx context.Context, process *bpengine.Process) (string, error) {
+	uuid, err := r.ProcessRepository.Create(ctx, process)
+	if err == nil {
+		*r.created = append(*r.created, uuid)
+	}
+	return uuid, err
+}
+
+func (r *auditRepository) Transaction(ctx context.Context, fn func(repo bpengine.ProcessRepository) error) error {
+	return r.ProcessRepository.Transaction(ctx, func(repo bpengine.ProcessRepository) error {
+		return fn(&auditRepository{ProcessRepository: repo, created: r.created})
+	})
+}
+
+func TestPublicExtensions(t *testing.T) {
+	processConfig := bpengine.ProcessConfigList{{
+		Name:   "requests",
+		Schema: `{"type": "object"}`,
+		Statuses: []bpengine.StatusConfig{
+			{Name: "open", Initial: true, Next: bpengine.NextList{{Name: "done"}}},
+			{Name: "done", Final: true},
+		},
+	}}
+	db, err := gorm.Open(sqlite.Open("file:TestPublicExtensions?mode=memory&cache=shared"), &gorm.Config{})
+	assert.NoError(t, err)
+
+	builtIn, err := bpengine.NewValidator(processConfig)
+	assert.NoError(t, err)
+	validator := &auditValidator{Validator: builtIn}
+	var created []string
+	repository := &auditRepository{ProcessRepository: bpengine.NewProcessRepository(db), created: &created}
+
+	engine, err := bpengine.New(bpengine.Config{ProcessConfig: processConfig},
+		bpengine.WithDB(db), bpengine.WithValidator(validator), bpengine.WithRepository(repository), bpengine.WithoutSwagger())
+	assert.NoError(t, err)
+	assert.NoError(t, engine.RunDBMigration())
+
+	submitted := make(chan bpengine.ProcessEvent, 1)
+	engine.OnSubmitted(func(ctx context.Context, event bpengine.ProcessEvent) error {
+		submitted <- event
+		return nil
+	})
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{
+			name:     "submitted",
+			body:     `{"code": "requests", "payload": {"amount": 10}}`,
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "rejected by custom validator",
+			body:     `{"code": "requests", "payload": {"secret": "42"}}`,
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/process", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := engine.App.Test(req)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, resp.StatusCode)
+		})
+	}
+
+	assert.Equal(t, []string{"requests"}, validator.submitted)
+	assert.Len(t, created, 1)
+	event := <-submitted
+	assert.Equal(t, bpengine.EVENT_PROCESS_SUBMITTED, event.Type)
+	assert.Equal(t, "open", event.After.CurrentStatus.Name)
+}